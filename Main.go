@@ -7,7 +7,9 @@ import (
 	"fmt"
 	"github.com/bwmarrin/discordgo"
 	"github.com/davide/ModRepository/controllers"
+	"github.com/davide/ModRepository/models"
 	repo "github.com/davide/ModRepository/repositories/mysql"
+	"github.com/davide/ModRepository/repositories/storage"
 	"github.com/davide/ModRepository/routes"
 	"github.com/davide/ModRepository/routes/handlers"
 	"google.golang.org/api/option"
@@ -15,6 +17,7 @@ import (
 	"gorm.io/gorm"
 	"log"
 	"os"
+	"time"
 )
 
 type Credentials struct {
@@ -27,6 +30,17 @@ type Secret struct {
 	Secret       string
 	DiscordToken string
 	Channels     []string
+	// DiscordRoutes send the announcements matching their filters to more channels
+	DiscordRoutes         []models.DiscordRoute
+	AuthorUpdatesApproval bool
+	ImagesDir             string
+	ImagesBaseUrl         string
+	// Notifiers enable the announcement channels, only the community mods go to Discord when empty
+	Notifiers []models.NotifierRule
+	// EmbedTemplates replace the default Discord embeds of their events
+	EmbedTemplates []models.EmbedTemplate
+	// NoopNotifiers logs the announcements instead of sending them, for local development
+	NoopNotifiers bool
 }
 
 func main() {
@@ -51,6 +65,13 @@ func main() {
 		}
 	}
 
+	if secret.ImagesDir == "" {
+		secret.ImagesDir = "images"
+	}
+	if secret.ImagesBaseUrl == "" {
+		secret.ImagesBaseUrl = "https://api.acmodrepository.com/images"
+	}
+
 	ctx := context.Background()
 	opt := option.WithCredentialsFile("serviceAccountKey.json")
 	app, err := firebase.NewApp(ctx, nil, opt)
@@ -63,16 +84,33 @@ func main() {
 		log.Fatalf("error getting Messaging client: %v\n", err)
 	}
 
-	dg, err := discordgo.New("Bot " + secret.DiscordToken)
-	if err != nil {
-		fmt.Println("error creating Discord session,", err)
-		return
+	notifiers := &controllers.NotifierRegistry{Rules: secret.Notifiers}
+	if len(notifiers.Rules) == 0 {
+		community := false
+		notifiers.Rules = []models.NotifierRule{{Channel: models.DiscordChannel, Enabled: true, Official: &community}}
+	}
+	discordCtrl := controllers.DiscordBotControllerImpl{Channels: secret.Channels, Routes: secret.DiscordRoutes, Templates: secret.EmbedTemplates}
+	if err := discordCtrl.Validate(); err != nil {
+		log.Fatal(err)
 	}
+	var dg *discordgo.Session
+	if secret.NoopNotifiers {
+		notifiers.Register(models.DiscordChannel, controllers.NoopNotifier{Channel: models.DiscordChannel})
+		notifiers.Register(models.FirebaseChannel, controllers.NoopNotifier{Channel: models.FirebaseChannel})
+	} else {
+		dg, err = discordgo.New("Bot " + secret.DiscordToken)
+		if err != nil {
+			fmt.Println("error creating Discord session,", err)
+			return
+		}
 
-	err = dg.Open()
-	if err != nil {
-		fmt.Println("error opening connection,", err)
-		return
+		err = dg.Open()
+		if err != nil {
+			fmt.Println("error opening connection,", err)
+			return
+		}
+		discordCtrl.Session = dg
+		notifiers.Register(models.DiscordChannel, discordCtrl)
 	}
 
 	dsn := fmt.Sprintf("%v:%v@tcp(%v:3306)/%v?charset=utf8mb4&parseTime=True&loc=Local", cred.Username, cred.Password, cred.Host, "mod_repo")
@@ -91,27 +129,69 @@ func main() {
 	logsRepo := repo.LogRepositoryImpl{Db: dbase}
 	serversRepo := repo.ServersRepositoryImpl{Db: dbase}
 	skinsRepo := repo.SkinRepositoryImpl{Db: dbase}
+	modUpdatesRepo := repo.ModUpdateRepositoryImpl{Db: dbase}
+	imagesRepo := repo.ImageRepositoryImpl{Db: dbase}
+	webhooksRepo := repo.WebhookRepositoryImpl{Db: dbase}
+	notificationsRepo := repo.NotificationRepositoryImpl{Db: dbase}
+	deviceTokensRepo := repo.DeviceTokenRepositoryImpl{Db: dbase}
+	imageStorage := storage.LocalStorageImpl{Dir: secret.ImagesDir, BaseUrl: secret.ImagesBaseUrl}
+
+	catalogCache := &controllers.CatalogCache{}
+	webhookCtrl := controllers.WebhookControllerImpl{Repo: webhooksRepo}
+	liveCtrl := &controllers.LiveControllerImpl{Webhooks: webhookCtrl}
+
+	nationCtrl := controllers.NationControllerImpl{Repo: nationRepo, Cache: catalogCache}
+	carCtrl := controllers.CarControllerImpl{Repo: carRepo, Similarity: &controllers.CarSimilarityIndex{}, Cache: catalogCache, Notifiers: notifiers}
+	trackCtrl := controllers.TrackControllerImpl{Repo: trackRepo, Cache: catalogCache, Notifiers: notifiers}
+	brandCtrl := controllers.BrandControllerImpl{Repo: brandRepo, CarRepo: carRepo, Cache: catalogCache}
+	authorCtrl := controllers.AuthorsControllerImpl{Repo: authorRepo, CarRepo: carRepo, TrackRepo: trackRepo, Cache: catalogCache}
+	serversCtrl := controllers.ServersControllerImpl{Repo: serversRepo, Cache: catalogCache}
+	skinsCtrl := controllers.SkinControllerImpl{Repo: skinsRepo, Cache: catalogCache}
+	feedCtrl := controllers.FeedControllerImpl{LogRepo: logsRepo, CarRepo: carRepo, TrackRepo: trackRepo}
+	firebaseCtrl := controllers.FirebaseControllerImpl{Client: client, Context: ctx, Repo: deviceTokensRepo}
+	if !secret.NoopNotifiers {
+		notifiers.Register(models.FirebaseChannel, firebaseCtrl)
+	}
+	go firebaseCtrl.RunPruning(ctx, 24*time.Hour)
+	notificationCtrl := controllers.NotificationControllerImpl{Repo: notificationsRepo, Notifiers: notifiers}
+	go notificationCtrl.Run(ctx, 15*time.Second)
+
+	if dg != nil {
+		commands := controllers.DiscordCommandsImpl{CarCtrl: carCtrl, TrackCtrl: trackCtrl, ServerCtrl: serversCtrl, FeedCtrl: feedCtrl}
+		if err := commands.Register(dg); err != nil {
+			log.Printf("error registering the discord commands: %v", err)
+		}
+	}
 
 	web := routes.Web{
-		CarHandler: handlers.CarsHandlerImpl{
-			CarCtrl:        controllers.CarControllerImpl{Repo: carRepo},
-			FirebaseCtrl:   controllers.FirebaseControllerImpl{Client: client, Context: ctx},
-			DiscordBotCtrl: controllers.DiscordBotControllerImpl{Session: dg, Channels: secret.Channels},
-		},
-		TracksHandler: handlers.TrackHandlerImpl{
-			TrackCtrl:      controllers.TrackControllerImpl{Repo: trackRepo},
-			FirebaseCtrl:   controllers.FirebaseControllerImpl{Client: client, Context: ctx},
-			DiscordBotCtrl: controllers.DiscordBotControllerImpl{Session: dg, Channels: secret.Channels},
-		},
-		NationHandler:   handlers.NationsHandlerImpl{CtrlNations: controllers.NationControllerImpl{Repo: nationRepo}},
-		BrandsHandler:   handlers.BrandsHandlerImpl{BrandCtrl: controllers.BrandControllerImpl{Repo: brandRepo}},
+		CarHandler:      handlers.CarsHandlerImpl{CarCtrl: carCtrl, LiveCtrl: liveCtrl},
+		TracksHandler:   handlers.TrackHandlerImpl{TrackCtrl: trackCtrl, LiveCtrl: liveCtrl},
+		NationHandler:   handlers.NationsHandlerImpl{CtrlNations: nationCtrl},
+		BrandsHandler:   handlers.BrandsHandlerImpl{BrandCtrl: brandCtrl},
 		UsersHandler:    handlers.UserHandlerImpl{UserCtrl: controllers.UserControllerImpl{Repo: userRepo}, Secret: secret.Secret},
-		AuthorsHandler:  handlers.AuthorHandlerImpl{AuthorsCtrl: controllers.AuthorsControllerImpl{Repo: authorRepo}},
+		AuthorsHandler:  handlers.AuthorHandlerImpl{AuthorsCtrl: authorCtrl},
 		LogsHandler:     handlers.LogsHandlerImpl{Ctrl: controllers.LogControllerImpl{Repo: logsRepo}},
-		ServersHandler:  handlers.ServersHandlerImpl{Ctrl: controllers.ServersControllerImpl{Repo: serversRepo}},
-		SkinsHandler:    handlers.SkinsHandlerImpl{Ctrl: controllers.SkinControllerImpl{Repo: skinsRepo}},
-		Middleware:      handlers.MiddlewareImpl{Secret: secret.Secret},
-		FirebaseHandler: handlers.FirebaseHandlerImpl{Ctrl: controllers.FirebaseControllerImpl{Client: client, Context: context.Background()}},
+		ServersHandler:  handlers.ServersHandlerImpl{Ctrl: serversCtrl, LiveCtrl: liveCtrl},
+		SkinsHandler:    handlers.SkinsHandlerImpl{Ctrl: skinsCtrl, LiveCtrl: liveCtrl},
+		ModUpdates:      handlers.ModUpdatesHandlerImpl{Ctrl: controllers.ModUpdateControllerImpl{Repo: modUpdatesRepo, CarRepo: carRepo, TrackRepo: trackRepo, Notifiers: notifiers, LiveCtrl: liveCtrl, RequireApproval: secret.AuthorUpdatesApproval, Cache: catalogCache}},
+		ImagesHandler:   handlers.ImagesHandlerImpl{Ctrl: controllers.ImageControllerImpl{Storage: imageStorage, Repo: imagesRepo, Cache: catalogCache}},
+		LiveHandler:     handlers.LiveHandlerImpl{Ctrl: liveCtrl},
+		FeedsHandler:    handlers.FeedsHandlerImpl{Ctrl: feedCtrl},
+		WebhooksHandler: handlers.WebhooksHandlerImpl{Ctrl: webhookCtrl},
+		Notifications:   handlers.NotificationsHandlerImpl{Ctrl: notificationCtrl},
+		DiscordHandler:  handlers.DiscordHandlerImpl{Ctrl: discordCtrl, CarCtrl: carCtrl, TrackCtrl: trackCtrl},
+		GraphqlHandler: handlers.NewGraphqlHandler(handlers.GraphqlResolver{
+			CarCtrl:    carCtrl,
+			TrackCtrl:  trackCtrl,
+			SkinCtrl:   skinsCtrl,
+			ServerCtrl: serversCtrl,
+			AuthorCtrl: authorCtrl,
+			BrandCtrl:  brandCtrl,
+			NationCtrl: nationCtrl,
+		}),
+		ImagesDir:       secret.ImagesDir,
+		Middleware:      handlers.MiddlewareImpl{Secret: secret.Secret, Cache: catalogCache},
+		FirebaseHandler: handlers.FirebaseHandlerImpl{Ctrl: firebaseCtrl},
 	}
 	web.Listen()
 }
@@ -1,12 +1,17 @@
 package controllers
 
 import (
+	"github.com/davide/ModRepository/controllers/helpers"
 	"github.com/davide/ModRepository/models"
 	"github.com/davide/ModRepository/repositories"
+	"time"
 )
 
 type AuthorsControllerImpl struct {
-	Repo repositories.AuthorRepository
+	Repo      repositories.AuthorRepository
+	CarRepo   repositories.CarRepository
+	TrackRepo repositories.TrackRepository
+	Cache     *CatalogCache
 }
 
 func (a AuthorsControllerImpl) GetAllAuthors() ([]models.Author, error) {
@@ -20,3 +25,75 @@ func (a AuthorsControllerImpl) GetAllCarAuthors() ([]models.Author, error) {
 func (a AuthorsControllerImpl) GetAllTrackAuthors() ([]models.Author, error) {
 	return a.Repo.SelectAllTrackAuthors()
 }
+
+func (a AuthorsControllerImpl) GetAuthor(name string, role models.Role) (models.AuthorDetails, error) {
+	premium, admin := helpers.IsPremium(role), helpers.IsAdmin(role)
+
+	author, err := a.Repo.SelectAuthorByName(name)
+	if err != nil {
+		return models.AuthorDetails{}, err
+	}
+
+	cars, err := a.CarRepo.SelectCarsByAuthor(author.Name, 0, 0, premium, admin)
+	if err != nil {
+		return models.AuthorDetails{}, err
+	}
+
+	tracks, err := a.TrackRepo.SelectTracksByAuthor(author.Name, 0, 0, premium, admin)
+	if err != nil {
+		return models.AuthorDetails{}, err
+	}
+
+	var mods []models.Mod
+	for _, car := range cars {
+		mods = append(mods, car.Mod)
+	}
+	for _, track := range tracks {
+		mods = append(mods, track.Mod)
+	}
+
+	var latestRelease *time.Time
+	var ratingSum, ratingCount uint
+	for i, mod := range mods {
+		if latestRelease == nil || mod.CreatedAt.After(*latestRelease) {
+			latestRelease = &mods[i].CreatedAt
+		}
+		if mod.Rating > 0 {
+			ratingSum += mod.Rating
+			ratingCount++
+		}
+	}
+
+	var averageRating float64
+	if ratingCount > 0 {
+		averageRating = float64(ratingSum) / float64(ratingCount)
+	}
+
+	return models.AuthorDetails{
+		Author:        author,
+		Cars:          cars,
+		Tracks:        tracks,
+		CarsCount:     len(cars),
+		TracksCount:   len(tracks),
+		LatestRelease: latestRelease,
+		AverageRating: averageRating,
+	}, nil
+}
+
+// UpdateAuthor returns the author as stored, the fields left empty keep their value
+func (a AuthorsControllerImpl) UpdateAuthor(name string, author models.Author) (models.Author, error) {
+	if err := helpers.ValidateAuthor(author); err != nil {
+		return models.Author{}, err
+	}
+	if err := a.Cache.invalidateOn(a.Repo.UpdateAuthor(name, author)); err != nil {
+		return models.Author{}, err
+	}
+	if author.Name != "" {
+		name = author.Name
+	}
+	return a.Repo.SelectAuthorByName(name)
+}
+
+func (a AuthorsControllerImpl) MergeAuthors(source string, target string) error {
+	return a.Cache.invalidateOn(a.Repo.MergeAuthors(source, target))
+}
@@ -1,14 +1,54 @@
 package controllers
 
 import (
+	"github.com/davide/ModRepository/controllers/helpers"
 	"github.com/davide/ModRepository/models"
 	"github.com/davide/ModRepository/repositories"
 )
 
 type BrandControllerImpl struct {
-	Repo repositories.BrandRepository
+	Repo    repositories.BrandRepository
+	CarRepo repositories.CarRepository
+	Cache   *CatalogCache
 }
 
 func (b BrandControllerImpl) GetAllBrands() ([]models.CarBrand, error) {
 	return b.Repo.SelectAllBrands()
 }
+
+func (b BrandControllerImpl) GetBrand(name string, role models.Role) (models.BrandDetails, error) {
+	brand, err := b.Repo.SelectBrandByName(name)
+	if err != nil {
+		return models.BrandDetails{}, err
+	}
+	cars, err := b.CarRepo.SelectCarsByBrand(brand.Name, 0, 0, helpers.IsPremium(role), helpers.IsAdmin(role))
+	if err != nil {
+		return models.BrandDetails{}, err
+	}
+	return models.BrandDetails{CarBrand: brand, Cars: cars}, nil
+}
+
+// UpdateBrand returns the brand as stored, the fields left empty keep their value
+func (b BrandControllerImpl) UpdateBrand(name string, brand models.CarBrand) (models.CarBrand, error) {
+	if err := helpers.ValidateBrand(brand); err != nil {
+		return models.CarBrand{}, err
+	}
+	if brand.Nation.Name != "" || brand.Nation.Code != "" {
+		nation, err := helpers.NormalizeNation(brand.Nation)
+		if err != nil {
+			return models.CarBrand{}, err
+		}
+		brand.Nation = nation
+	}
+	if err := b.Cache.invalidateOn(b.Repo.UpdateBrand(name, brand)); err != nil {
+		return models.CarBrand{}, err
+	}
+	if brand.Name != "" {
+		name = brand.Name
+	}
+	return b.Repo.SelectBrandByName(name)
+}
+
+func (b BrandControllerImpl) MergeBrands(source string, target string) error {
+	return b.Cache.invalidateOn(b.Repo.MergeBrands(source, target))
+}
@@ -7,7 +7,11 @@ import (
 )
 
 type CarControllerImpl struct {
-	Repo repositories.CarRepository
+	Repo       repositories.CarRepository
+	Similarity *CarSimilarityIndex
+	Cache      *CatalogCache
+	// Notifiers route the announcements written to the outbox with the car
+	Notifiers *NotifierRegistry
 }
 
 func (c CarControllerImpl) GetAllCarCategories() ([]models.CarCategory, error) {
@@ -19,9 +23,121 @@ func (c CarControllerImpl) GetAllCars(role models.Role) ([]models.Car, error) {
 }
 
 func (c CarControllerImpl) AddCar(car *models.Car) error {
-	return c.Repo.InsertCar(car)
+	if err := helpers.ValidateCar(*car); err != nil {
+		return err
+	}
+	nation, err := helpers.NormalizeNation(car.Brand.Nation)
+	if err != nil {
+		return err
+	}
+	car.Brand.Nation = nation
+	car.Images = helpers.NormalizeGallery(car.Images)
+
+	if err := c.Repo.InsertCar(car, outboxNotifications(c.Notifiers, models.CarAddedEvent, car.Mod)...); err != nil {
+		return err
+	}
+	c.Similarity.Invalidate()
+	c.Cache.Invalidate()
+	return nil
 }
 
 func (c CarControllerImpl) UpdateCar(car models.Car) (bool, error) {
-	return c.Repo.UpdateCar(car)
+	if err := helpers.ValidateCar(car); err != nil {
+		return false, err
+	}
+	nation, err := helpers.NormalizeNation(car.Brand.Nation)
+	if err != nil {
+		return false, err
+	}
+	car.Brand.Nation = nation
+	car.Images = helpers.NormalizeGallery(car.Images)
+
+	versionChange, err := c.Repo.UpdateCar(car, outboxNotifications(c.Notifiers, models.CarUpdatedEvent, car.Mod)...)
+	if err != nil {
+		return false, err
+	}
+	c.Similarity.Invalidate()
+	c.Cache.Invalidate()
+	return versionChange, nil
+}
+
+func (c CarControllerImpl) GetCar(id uint, role models.Role) (models.Car, error) {
+	return c.Repo.SelectCarById(id, helpers.IsPremium(role), helpers.IsAdmin(role))
+}
+
+func (c CarControllerImpl) GetCarsByIds(ids []uint, role models.Role) ([]models.Car, error) {
+	return c.Repo.SelectCarsByIds(ids, helpers.IsPremium(role), helpers.IsAdmin(role))
+}
+
+// SearchCars returns at most limit cars whose brand, model and year contain every word of the query
+func (c CarControllerImpl) SearchCars(query string, role models.Role, limit int) ([]models.Car, error) {
+	return c.Repo.SearchCars(query, limit, helpers.IsPremium(role), helpers.IsAdmin(role))
+}
+
+func (c CarControllerImpl) DeleteCar(id uint) error {
+	if err := c.Repo.DeleteCar(id); err != nil {
+		return err
+	}
+	c.Similarity.Invalidate()
+	c.Cache.Invalidate()
+	return nil
+}
+
+func (c CarControllerImpl) GetRelatedCars(carId uint, role models.Role, limit int) (models.RelatedCars, error) {
+	premium, admin := helpers.IsPremium(role), helpers.IsAdmin(role)
+
+	car, err := c.Repo.SelectCarById(carId, premium, admin)
+	if err != nil {
+		return models.RelatedCars{}, err
+	}
+
+	var related models.RelatedCars
+
+	if related.SameAuthor, err = c.Repo.SelectCarsByAuthor(car.Author.Name, car.Id, limit, premium, admin); err != nil {
+		return models.RelatedCars{}, err
+	}
+	if related.SameBrand, err = c.Repo.SelectCarsByBrand(car.Brand.Name, car.Id, limit, premium, admin); err != nil {
+		return models.RelatedCars{}, err
+	}
+	if related.SameCategory, err = c.Repo.SelectCarsByCategories(car.Categories, car.Year, car.Id, limit, premium, admin); err != nil {
+		return models.RelatedCars{}, err
+	}
+
+	return related, nil
+}
+
+func (c CarControllerImpl) GetSimilarCars(carId uint, role models.Role, limit int) ([]models.SimilarCar, error) {
+	ranking, err := c.Similarity.Ranking(carId, c.Repo)
+	if err != nil {
+		return nil, err
+	}
+	if ranking == nil {
+		return nil, models.ErrNotFound
+	}
+	if len(ranking) > limit {
+		ranking = ranking[:limit]
+	}
+
+	var ids []uint
+	for _, scored := range ranking {
+		ids = append(ids, scored.Id)
+	}
+
+	cars, err := c.Repo.SelectCarsByIds(ids, helpers.IsPremium(role), helpers.IsAdmin(role))
+	if err != nil {
+		return nil, err
+	}
+
+	carsById := make(map[uint]models.Car, len(cars))
+	for _, car := range cars {
+		carsById[car.Id] = car
+	}
+
+	similar := make([]models.SimilarCar, 0, len(ranking))
+	for _, scored := range ranking {
+		if car, ok := carsById[scored.Id]; ok {
+			similar = append(similar, models.SimilarCar{Car: car, Score: scored.Score})
+		}
+	}
+	return similar, nil
 }
@@ -0,0 +1,52 @@
+package controllers
+
+import (
+	"github.com/davide/ModRepository/controllers/helpers"
+	"github.com/davide/ModRepository/repositories"
+	"sync"
+)
+
+// CarSimilarityIndex caches the spec vectors and the rankings computed from them,
+// it's rebuilt lazily after the catalog changes
+type CarSimilarityIndex struct {
+	mu       sync.RWMutex
+	vectors  map[uint][]float64
+	rankings map[uint][]helpers.ScoredCar
+}
+
+func (i *CarSimilarityIndex) Invalidate() {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.vectors = nil
+	i.rankings = nil
+}
+
+func (i *CarSimilarityIndex) Ranking(carId uint, repo repositories.CarRepository) ([]helpers.ScoredCar, error) {
+	i.mu.RLock()
+	if i.vectors != nil {
+		if ranking, ok := i.rankings[carId]; ok {
+			i.mu.RUnlock()
+			return ranking, nil
+		}
+	}
+	i.mu.RUnlock()
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.vectors == nil {
+		cars, err := repo.SelectAllCars(true, true)
+		if err != nil {
+			return nil, err
+		}
+		i.vectors = helpers.CarSpecVectors(cars)
+		i.rankings = make(map[uint][]helpers.ScoredCar)
+	}
+
+	if ranking, ok := i.rankings[carId]; ok {
+		return ranking, nil
+	}
+	ranking := helpers.RankBySimilarity(carId, i.vectors)
+	i.rankings[carId] = ranking
+	return ranking, nil
+}
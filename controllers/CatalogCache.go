@@ -0,0 +1,93 @@
+package controllers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// maxCachedResponses bounds the cache, the feed filters are free text so the keys alone are not bounded
+const maxCachedResponses = 1024
+
+// CachedResponse is a serialized list response, ETag and LastModified are ready to be sent as headers
+type CachedResponse struct {
+	Header       http.Header
+	Body         []byte
+	ETag         string
+	LastModified time.Time
+}
+
+// CatalogCache keeps the serialized list responses until a controller writes to the catalog,
+// the keys must contain the role because the download links depend on it
+type CatalogCache struct {
+	mu         sync.RWMutex
+	entries    map[string]CachedResponse
+	generation uint64
+	modified   time.Time
+}
+
+// Invalidate drops every entry, it's safe to call on a nil cache so the controllers can be used without one
+func (c *CatalogCache) Invalidate() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = nil
+	c.generation++
+	c.modified = time.Now().UTC().Truncate(time.Second)
+}
+
+// Generation changes on every invalidation, a response computed during a write must not be stored
+func (c *CatalogCache) Generation() uint64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.generation
+}
+
+func (c *CatalogCache) Get(key string) (CachedResponse, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	response, ok := c.entries[key]
+	return response, ok
+}
+
+// Set stores the response if the catalog didn't change since generation, the response is returned either way
+func (c *CatalogCache) Set(key string, generation uint64, header http.Header, body []byte) CachedResponse {
+	sum := sha256.Sum256(body)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.modified.IsZero() {
+		c.modified = time.Now().UTC().Truncate(time.Second)
+	}
+	response := CachedResponse{
+		Header:       header,
+		Body:         body,
+		ETag:         `"` + hex.EncodeToString(sum[:16]) + `"`,
+		LastModified: c.modified,
+	}
+	if generation == c.generation {
+		if c.entries == nil {
+			c.entries = make(map[string]CachedResponse)
+		}
+		if _, ok := c.entries[key]; !ok && len(c.entries) >= maxCachedResponses {
+			for evicted := range c.entries {
+				delete(c.entries, evicted)
+				break
+			}
+		}
+		c.entries[key] = response
+	}
+	return response
+}
+
+// invalidateOn invalidates the cache when the write that returned err succeeded
+func (c *CatalogCache) invalidateOn(err error) error {
+	if err == nil {
+		c.Invalidate()
+	}
+	return err
+}
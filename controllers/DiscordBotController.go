@@ -3,167 +3,354 @@ package controllers
 import (
 	"fmt"
 	"github.com/bwmarrin/discordgo"
+	"github.com/davide/ModRepository/controllers/helpers"
 	"github.com/davide/ModRepository/models"
-	"net/url"
-	"strconv"
+	"strings"
+	"text/template"
 )
 
 type DiscordBotControllerImpl struct {
-	Session  *discordgo.Session
+	Session *discordgo.Session
+	// Channels receive every announcement
 	Channels []string
+	// Routes receive the announcements matching their filters
+	Routes []models.DiscordRoute
+	// Templates replace the default embeds of their events
+	Templates []models.EmbedTemplate
+}
+
+const (
+	embedColor         = 12590120
+	embedAuthorName    = "Davide"
+	embedAuthorIconUrl = "https://i.imgur.com/M4Am9z1.jpg"
+)
+
+// DefaultEmbedTemplates are the embeds sent for the events without a configured template
+var DefaultEmbedTemplates = []models.EmbedTemplate{
+	{
+		Event:       models.CarAddedEvent,
+		Title:       "{{.Brand.Name}} {{.ModelName}} has been added to the repository!",
+		Description: "[Click here for more]({{pageUrl .}})",
+		Fields: []models.EmbedFieldTemplate{
+			{Name: "Year", Value: "{{.Year}}"},
+			{Name: "Author", Value: "{{authors .Mod}}"},
+		},
+	},
+	{
+		Event:       models.CarUpdatedEvent,
+		Title:       "{{.Brand.Name}} {{.ModelName}} has been updated!",
+		Description: "[Click here for more]({{pageUrl .}})",
+		Fields: []models.EmbedFieldTemplate{
+			{Name: "Year", Value: "{{.Year}}"},
+			{Name: "Author", Value: "{{authors .Mod}}", Inline: true},
+			{Name: "Version", Value: "{{.Version}}", Inline: true},
+		},
+	},
+	{
+		Event:       models.TrackAddedEvent,
+		Title:       "{{.Name}} has been added to the repository!",
+		Description: "[Click here for more]({{pageUrl .}})",
+		Fields: []models.EmbedFieldTemplate{
+			{Name: "Location", Value: "{{.Location}}, {{.Nation.Name}}", Inline: true},
+			{Name: "Year", Value: "{{.Year}}", Inline: true},
+			{Name: "Author", Value: "{{authors .Mod}}"},
+		},
+	},
+	{
+		Event:       models.TrackUpdatedEvent,
+		Title:       "{{.Name}} has been updated!",
+		Description: "[Click here for more]({{pageUrl .}})",
+		Fields: []models.EmbedFieldTemplate{
+			{Name: "Location", Value: "{{.Location}}, {{.Nation.Name}}"},
+			{Name: "Year", Value: "{{.Year}}"},
+			{Name: "Author", Value: "{{authors .Mod}}", Inline: true},
+			{Name: "Version", Value: "{{.Version}}", Inline: true},
+		},
+	},
+}
+
+var embedFuncs = template.FuncMap{
+	"pageUrl": func(mod interface{}) string {
+		switch mod := mod.(type) {
+		case models.Car:
+			return helpers.CarPageUrl(mod)
+		case models.Track:
+			return helpers.TrackPageUrl(mod)
+		}
+		return helpers.SiteUrl
+	},
+	"embedImage": getFavImageUrl,
+	"authors":    getAuthorsFieldValue,
 }
 
 func (d DiscordBotControllerImpl) NotifyCarAdded(car models.Car) error {
-	for _, channel := range d.Channels {
-		_, err := d.Session.ChannelMessageSendComplex(channel, &discordgo.MessageSend{
-			Embed: &discordgo.MessageEmbed{
-				Type:        "image",
-				Title:       fmt.Sprintf("%v %v has been added to the repository!", car.Brand.Name, car.ModelName),
-				Description: fmt.Sprintf("[Click here for more](https://www.acmodrepository.com/cars/%v/%v/%v)", url.PathEscape(car.Brand.Name), url.PathEscape(car.ModelName), car.Year),
-				Color:       12590120,
-				Image:       &discordgo.MessageEmbedImage{URL: getFavImageUrl(car.Images)},
-				Author: &discordgo.MessageEmbedAuthor{
-					Name:    "Davide",
-					IconURL: "https://i.imgur.com/M4Am9z1.jpg",
-				},
-				Fields: []*discordgo.MessageEmbedField{
-					{
-						Name:  "Year",
-						Value: strconv.Itoa(int(car.Year)),
-					},
-					{
-						Name:  "Author",
-						Value: fmt.Sprintf("[%v](%v)", car.Author.Name, car.Author.Link),
-					},
-				},
-			},
-		})
-		if err != nil {
-			return err
+	return d.send(models.CarAddedEvent, car)
+}
+
+func (d DiscordBotControllerImpl) NotifyCarUpdated(car models.Car) error {
+	return d.send(models.CarUpdatedEvent, car)
+}
+
+func (d DiscordBotControllerImpl) NotifyTrackUpdated(track models.Track) error {
+	return d.send(models.TrackUpdatedEvent, track)
+}
+
+func (d DiscordBotControllerImpl) NotifyTrackAdded(track models.Track) error {
+	return d.send(models.TrackAddedEvent, track)
+}
+
+func (d DiscordBotControllerImpl) send(event models.LiveEventType, mod interface{}) error {
+	_, err := d.NotifyTargets(event, mod, nil)
+	return err
+}
+
+// NotifyTargets announces the event of mod to the channels not in delivered. A failing channel doesn't stop the
+// others, the first error is returned with every channel reached so far
+func (d DiscordBotControllerImpl) NotifyTargets(event models.LiveEventType, mod interface{}, delivered []string) ([]string, error) {
+	embed, err := d.PreviewEmbed(event, nil, mod)
+	if err != nil {
+		return delivered, err
+	}
+	skip := map[string]bool{}
+	for _, channel := range delivered {
+		skip[channel] = true
+	}
+	var failed error
+	for _, channel := range d.channelsFor(event, mod) {
+		if skip[channel] {
+			continue
+		}
+		if _, err := d.Session.ChannelMessageSendEmbed(channel, embed); err != nil {
+			if failed == nil {
+				failed = fmt.Errorf("error announcing to channel %v: %w", channel, err)
+			}
+			continue
 		}
+		delivered = append(delivered, channel)
 	}
-	return nil
+	return delivered, failed
 }
 
-func (d DiscordBotControllerImpl) NotifyCarUpdated(car models.Car) error {
-	for _, channel := range d.Channels {
-		_, err := d.Session.ChannelMessageSendComplex(channel, &discordgo.MessageSend{
-			Embed: &discordgo.MessageEmbed{
-				Type:        "image",
-				Title:       fmt.Sprintf("%v %v has been updated!", car.Brand.Name, car.ModelName),
-				Description: fmt.Sprintf("[Click here for more](https://www.acmodrepository.com/cars/%v/%v/%v)", url.PathEscape(car.Brand.Name), url.PathEscape(car.ModelName), car.Year),
-				Color:       12590120,
-				Image:       &discordgo.MessageEmbedImage{URL: getFavImageUrl(car.Images)},
-				Author: &discordgo.MessageEmbedAuthor{
-					Name:    "Davide",
-					IconURL: "https://i.imgur.com/M4Am9z1.jpg",
-				},
-				Fields: []*discordgo.MessageEmbedField{
-					{
-						Name:  "Year",
-						Value: strconv.Itoa(int(car.Year)),
-					},
-					{
-						Name:   "Author",
-						Value:  fmt.Sprintf("[%v](%v)", car.Author.Name, car.Author.Link),
-						Inline: true,
-					},
-					{
-						Name:   "Version",
-						Value:  car.Version,
-						Inline: true,
-					},
-				},
-			},
-		})
-		if err != nil {
-			return err
+// PreviewEmbed renders the embed of the event for mod, with tmpl when given or with the template in use otherwise
+func (d DiscordBotControllerImpl) PreviewEmbed(event models.LiveEventType, tmpl *models.EmbedTemplate, mod interface{}) (*discordgo.MessageEmbed, error) {
+	if tmpl == nil {
+		configured, ok := d.template(event)
+		if !ok {
+			return nil, models.NewValidationError("event", fmt.Sprintf("no embed template for %v", event))
 		}
+		tmpl = &configured
 	}
-	return nil
+	return RenderEmbed(*tmpl, mod)
 }
 
-func (d DiscordBotControllerImpl) NotifyTrackUpdated(track models.Track) error {
+// channelsFor are the channels announcing the event of mod, every channel once
+func (d DiscordBotControllerImpl) channelsFor(event models.LiveEventType, mod interface{}) []string {
+	channels := []string{}
+	seen := map[string]bool{}
 	for _, channel := range d.Channels {
-		_, err := d.Session.ChannelMessageSendEmbed(channel, &discordgo.MessageEmbed{
-			Type:        "image",
-			Title:       fmt.Sprintf("%v has been updated!", track.Name),
-			Description: fmt.Sprintf("[Click here for more](https://www.acmodrepository.com/tracks/%v/%v/%v)", url.PathEscape(track.Nation.Name), url.PathEscape(track.Name), track.Year),
-			Color:       12590120,
-			Image:       &discordgo.MessageEmbedImage{URL: getFavImageUrl(track.Images)},
-			Author: &discordgo.MessageEmbedAuthor{
-				Name:    "Davide",
-				IconURL: "https://i.imgur.com/M4Am9z1.jpg",
-			},
-			Fields: []*discordgo.MessageEmbedField{
-				{
-					Name:  "Location",
-					Value: fmt.Sprintf("%v, %v", track.Location, track.Nation.Name),
-				},
-				{
-					Name:  "Year",
-					Value: strconv.Itoa(int(track.Year)),
-				},
-				{
-					Name:   "Author",
-					Value:  fmt.Sprintf("[%v](%v)", track.Author.Name, track.Author.Link),
-					Inline: true,
-				},
-				{
-					Name:   "Version",
-					Value:  track.Version,
-					Inline: true,
-				},
-			},
-		})
-		if err != nil {
-			return err
+		if !seen[channel] {
+			seen[channel] = true
+			channels = append(channels, channel)
 		}
 	}
-	return nil
+	for _, route := range d.Routes {
+		if !seen[route.Channel] && routeMatches(route, event, mod) {
+			seen[route.Channel] = true
+			channels = append(channels, route.Channel)
+		}
+	}
+	return channels
 }
 
-func (d DiscordBotControllerImpl) NotifyTrackAdded(track models.Track) error {
-	for _, channel := range d.Channels {
-		_, err := d.Session.ChannelMessageSendEmbed(channel, &discordgo.MessageEmbed{
-			Type:        "image",
-			Title:       fmt.Sprintf("%v has been added to the repository!", track.Name),
-			Description: fmt.Sprintf("[Click here for more](https://www.acmodrepository.com/tracks/%v/%v/%v)", url.PathEscape(track.Nation.Name), url.PathEscape(track.Name), track.Year),
-			Color:       12590120,
-			Image:       &discordgo.MessageEmbedImage{URL: getFavImageUrl(track.Images)},
-			Author: &discordgo.MessageEmbedAuthor{
-				Name:    "Davide",
-				IconURL: "https://i.imgur.com/M4Am9z1.jpg",
-			},
-			Fields: []*discordgo.MessageEmbedField{
-				{
-					Name:   "Location",
-					Value:  fmt.Sprintf("%v, %v", track.Location, track.Nation.Name),
-					Inline: true,
-				},
-				{
-					Name:   "Year",
-					Value:  strconv.Itoa(int(track.Year)),
-					Inline: true,
-				},
-				{
-					Name:  "Author",
-					Value: fmt.Sprintf("[%v](%v)", track.Author.Name, track.Author.Link),
-				},
-			},
-		})
-		if err != nil {
-			return err
+func routeMatches(route models.DiscordRoute, event models.LiveEventType, mod interface{}) bool {
+	if len(route.Events) > 0 {
+		matches := false
+		for _, e := range route.Events {
+			matches = matches || e == event
+		}
+		if !matches {
+			return false
+		}
+	}
+
+	var base models.Mod
+	var categories []string
+	var tags []string
+	switch mod := mod.(type) {
+	case models.Car:
+		base = mod.Mod
+		for _, category := range mod.Categories {
+			categories = append(categories, string(category.Name))
+		}
+	case models.Track:
+		base = mod.Mod
+		for _, tag := range mod.Tags {
+			tags = append(tags, string(tag))
+		}
+	default:
+		return false
+	}
+	if route.Premium != nil && *route.Premium != base.Premium {
+		return false
+	}
+	if len(route.Categories) > 0 {
+		matches := false
+		for _, category := range route.Categories {
+			matches = matches || containsFold(categories, string(category))
+		}
+		if !matches {
+			return false
+		}
+	}
+	if len(route.Tags) > 0 {
+		matches := false
+		for _, tag := range route.Tags {
+			matches = matches || containsFold(tags, string(tag))
+		}
+		if !matches {
+			return false
+		}
+	}
+	return true
+}
+
+// Validate checks the routes and renders the configured templates over an empty mod, so a broken one stops
+// the startup instead of an announcement
+func (d DiscordBotControllerImpl) Validate() error {
+	for i, route := range d.Routes {
+		if err := helpers.ValidateDiscordRoute(route); err != nil {
+			return fmt.Errorf("discord route %v: %w", i, err)
+		}
+	}
+	for _, tmpl := range d.Templates {
+		var mod interface{}
+		switch tmpl.Event {
+		case models.CarAddedEvent, models.CarUpdatedEvent:
+			mod = models.Car{}
+		case models.TrackAddedEvent, models.TrackUpdatedEvent:
+			mod = models.Track{}
+		default:
+			return models.NewValidationError("event", fmt.Sprintf("%v is not announced on Discord", tmpl.Event))
+		}
+		if _, err := RenderEmbed(tmpl, mod); err != nil {
+			return fmt.Errorf("embed template of %v: %w", tmpl.Event, err)
 		}
 	}
 	return nil
 }
 
+func (d DiscordBotControllerImpl) template(event models.LiveEventType) (models.EmbedTemplate, bool) {
+	for _, templates := range [][]models.EmbedTemplate{d.Templates, DefaultEmbedTemplates} {
+		for _, tmpl := range templates {
+			if tmpl.Event == event {
+				return tmpl, true
+			}
+		}
+	}
+	return models.EmbedTemplate{}, false
+}
+
+// RenderEmbed executes the template over the car or the track, the color, the author and the image fall back to
+// the defaults when empty. A template that doesn't parse or execute is a validation error
+func RenderEmbed(tmpl models.EmbedTemplate, mod interface{}) (*discordgo.MessageEmbed, error) {
+	if tmpl.Color == 0 {
+		tmpl.Color = embedColor
+	}
+	if tmpl.AuthorName == "" {
+		tmpl.AuthorName, tmpl.AuthorIconUrl = embedAuthorName, embedAuthorIconUrl
+	}
+	if tmpl.ImageUrl == "" {
+		tmpl.ImageUrl = "{{embedImage .Images}}"
+	}
+
+	r := embedRenderer{mod: mod}
+	embed := &discordgo.MessageEmbed{
+		Type:        "image",
+		Title:       r.render("title", tmpl.Title),
+		Description: r.render("description", tmpl.Description),
+		Color:       tmpl.Color,
+		Image:       &discordgo.MessageEmbedImage{URL: r.render("imageUrl", tmpl.ImageUrl)},
+		Author: &discordgo.MessageEmbedAuthor{
+			Name:    r.render("authorName", tmpl.AuthorName),
+			IconURL: r.render("authorIconUrl", tmpl.AuthorIconUrl),
+		},
+	}
+	for i, field := range tmpl.Fields {
+		value := r.render(fmt.Sprintf("fields[%v].value", i), field.Value)
+		if strings.TrimSpace(value) != "" {
+			embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: r.render(fmt.Sprintf("fields[%v].name", i), field.Name), Value: value, Inline: field.Inline})
+		}
+	}
+	if len(r.errors) > 0 {
+		return nil, models.ValidationError{Fields: r.errors}
+	}
+	return embed, nil
+}
+
+// embedRenderer collects the errors of every template so they are reported together
+type embedRenderer struct {
+	mod    interface{}
+	errors []models.FieldError
+}
+
+func (r *embedRenderer) render(field string, text string) string {
+	t, err := template.New(field).Funcs(embedFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		r.errors = append(r.errors, models.FieldError{Field: field, Message: err.Error()})
+		return ""
+	}
+	var out strings.Builder
+	if err := t.Execute(&out, r.mod); err != nil {
+		r.errors = append(r.errors, models.FieldError{Field: field, Message: err.Error()})
+		return ""
+	}
+	return out.String()
+}
+
 func getFavImageUrl(images []models.Image) string {
 	for _, image := range images {
 		if image.Favorite {
-			return image.Url[0:27] + ".jpg"
+			return getEmbedImageUrl(image)
 		}
 	}
 	return ""
 }
+
+// getEmbedImageUrl picks a variant small enough for an embed, imgur links are turned into the direct full size jpg
+func getEmbedImageUrl(image models.Image) string {
+	if url, ok := image.Variants["large"]; ok {
+		return url
+	}
+	if strings.HasPrefix(image.Url, "https://imgur.com/") || strings.HasPrefix(image.Url, "https://i.imgur.com/") {
+		id := image.Url[strings.LastIndex(image.Url, "/")+1:]
+		if dot := strings.Index(id, "."); dot >= 0 {
+			id = id[:dot]
+		}
+		// imgur ids are 7 characters, an eighth one is the thumbnail size suffix
+		if len(id) == 8 && strings.ContainsAny(id[7:], "sbtmlh") {
+			id = id[:7]
+		}
+		return "https://i.imgur.com/" + id + ".jpg"
+	}
+	return image.Url
+}
+
+func getAuthorsFieldValue(mod models.Mod) string {
+	contributors := []models.Contributor{{Author: mod.Author}}
+	for _, contributor := range mod.Contributors {
+		if contributor.Name == mod.Author.Name {
+			contributors[0].Role = contributor.Role
+		} else {
+			contributors = append(contributors, contributor)
+		}
+	}
+	var authors []string
+	for _, contributor := range contributors {
+		if contributor.Role != "" {
+			authors = append(authors, fmt.Sprintf("[%v](%v) (%v)", contributor.Name, contributor.Link, contributor.Role))
+		} else {
+			authors = append(authors, fmt.Sprintf("[%v](%v)", contributor.Name, contributor.Link))
+		}
+	}
+	return strings.Join(authors, ", ")
+}
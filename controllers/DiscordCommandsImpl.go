@@ -0,0 +1,357 @@
+package controllers
+
+import (
+	"fmt"
+	"github.com/bwmarrin/discordgo"
+	"github.com/davide/ModRepository/controllers/helpers"
+	"github.com/davide/ModRepository/models"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	maxCommandEmbeds = 5
+	// a message holds at most 10 embeds
+	maxServerEmbeds   = 10
+	maxCommandChoices = 25
+	// Discord rejects the choices longer than 100 characters
+	maxChoiceLength = 100
+)
+
+// DiscordCommands are the slash commands registered by the bot
+var DiscordCommands = []*discordgo.ApplicationCommand{
+	{
+		Name:        "car",
+		Description: "Search a car on the repository",
+		Options: []*discordgo.ApplicationCommandOption{
+			{Type: discordgo.ApplicationCommandOptionString, Name: "query", Description: "Brand and model", Required: true, Autocomplete: true},
+		},
+	},
+	{
+		Name:        "track",
+		Description: "Search a track on the repository",
+		Options: []*discordgo.ApplicationCommandOption{
+			{Type: discordgo.ApplicationCommandOptionString, Name: "query", Description: "Track name", Required: true, Autocomplete: true},
+		},
+	},
+	{
+		Name:        "server",
+		Description: "The community servers",
+		Options: []*discordgo.ApplicationCommandOption{
+			{Type: discordgo.ApplicationCommandOptionSubCommand, Name: "list", Description: "List the servers"},
+		},
+	},
+	{
+		Name:        "latest",
+		Description: "The latest releases and updates",
+	},
+}
+
+// DiscordCommandsImpl answers the slash commands, HandleInteraction only builds the response so the commands
+// work without a Discord connection
+type DiscordCommandsImpl struct {
+	CarCtrl    CarController
+	TrackCtrl  TrackController
+	ServerCtrl ServersController
+	FeedCtrl   FeedController
+}
+
+// Register creates the commands for the bot user and answers them on the session
+func (d DiscordCommandsImpl) Register(session *discordgo.Session) error {
+	if _, err := session.ApplicationCommandBulkOverwrite(session.State.User.ID, "", DiscordCommands); err != nil {
+		return err
+	}
+	session.AddHandler(func(s *discordgo.Session, i *discordgo.InteractionCreate) {
+		response, err := d.HandleInteraction(i.Interaction)
+		if err != nil {
+			log.Printf("error handling the discord interaction %v: %v", i.ID, err)
+			response = commandMessage("Something went wrong, try again later")
+		}
+		if err := s.InteractionRespond(i.Interaction, response); err != nil {
+			log.Printf("error answering the discord interaction %v: %v", i.ID, err)
+		}
+	})
+	return nil
+}
+
+func (d DiscordCommandsImpl) HandleInteraction(interaction *discordgo.Interaction) (*discordgo.InteractionResponse, error) {
+	switch interaction.Type {
+	case discordgo.InteractionApplicationCommand:
+		data := interaction.ApplicationCommandData()
+		switch data.Name {
+		case "car":
+			return d.searchCars(commandOption(data.Options, "query"))
+		case "track":
+			return d.searchTracks(commandOption(data.Options, "query"))
+		case "server":
+			return d.listServers()
+		case "latest":
+			return d.latest()
+		}
+		return nil, fmt.Errorf("unknown command %v", data.Name)
+	case discordgo.InteractionApplicationCommandAutocomplete:
+		data := interaction.ApplicationCommandData()
+		var choices []string
+		var err error
+		switch data.Name {
+		case "car":
+			choices, err = d.carChoices(commandOption(data.Options, "query"))
+		case "track":
+			choices, err = d.trackChoices(commandOption(data.Options, "query"))
+		}
+		if err != nil {
+			return nil, err
+		}
+		return autocompleteResponse(choices), nil
+	}
+	return nil, fmt.Errorf("unsupported interaction type %v", interaction.Type)
+}
+
+func commandOption(options []*discordgo.ApplicationCommandInteractionDataOption, name string) string {
+	for _, option := range options {
+		if option.Name == name && option.Type == discordgo.ApplicationCommandOptionString {
+			return option.StringValue()
+		}
+	}
+	return ""
+}
+
+// matchesQuery is true when every word of the query is in the name
+func matchesQuery(name string, query string) bool {
+	name = strings.ToLower(name)
+	for _, word := range strings.Fields(strings.ToLower(query)) {
+		if !strings.Contains(name, word) {
+			return false
+		}
+	}
+	return true
+}
+
+func carName(car models.Car) string {
+	return fmt.Sprintf("%v %v %v", car.Brand.Name, car.ModelName, car.Year)
+}
+
+func trackName(track models.Track) string {
+	return fmt.Sprintf("%v %v", track.Name, track.Year)
+}
+
+func (d DiscordCommandsImpl) searchCars(query string) (*discordgo.InteractionResponse, error) {
+	cars, err := d.CarCtrl.SearchCars(query, models.Base, maxCommandEmbeds)
+	if err != nil {
+		return nil, err
+	}
+	var embeds []*discordgo.MessageEmbed
+	for _, car := range cars {
+		var categories []string
+		for _, category := range car.Categories {
+			categories = append(categories, string(category.Name))
+		}
+		embed := modEmbed(carName(car), helpers.CarPageUrl(car), car.Mod)
+		if len(categories) > 0 {
+			embed.Description = strings.Join(categories, ", ")
+		}
+		embeds = append(embeds, embed)
+	}
+	if len(embeds) == 0 {
+		return commandMessage(fmt.Sprintf("No car matches \"%v\"", query)), nil
+	}
+	return commandEmbeds(embeds), nil
+}
+
+func (d DiscordCommandsImpl) searchTracks(query string) (*discordgo.InteractionResponse, error) {
+	tracks, err := d.TrackCtrl.SearchTracks(query, models.Base, maxCommandEmbeds)
+	if err != nil {
+		return nil, err
+	}
+	var embeds []*discordgo.MessageEmbed
+	for _, track := range tracks {
+		embed := modEmbed(trackName(track), helpers.TrackPageUrl(track), track.Mod)
+		embed.Description = fmt.Sprintf("%v, %v", track.Location, track.Nation.Name)
+		embeds = append(embeds, embed)
+	}
+	if len(embeds) == 0 {
+		return commandMessage(fmt.Sprintf("No track matches \"%v\"", query)), nil
+	}
+	return commandEmbeds(embeds), nil
+}
+
+func modEmbed(title string, url string, mod models.Mod) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{
+		Title: title,
+		URL:   url,
+		Color: embedColor,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Author", Value: getAuthorsFieldValue(mod)},
+		},
+	}
+	if image := getFavImageUrl(mod.Images); image != "" {
+		embed.Thumbnail = &discordgo.MessageEmbedThumbnail{URL: image}
+	}
+	if mod.Version != "" {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Version", Value: mod.Version, Inline: true})
+	}
+	if mod.Premium {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Premium", Value: "Yes", Inline: true})
+	}
+	return embed
+}
+
+func (d DiscordCommandsImpl) listServers() (*discordgo.InteractionResponse, error) {
+	servers, err := d.ServerCtrl.GetAllServers()
+	if err != nil {
+		return nil, err
+	}
+	if len(servers) == 0 {
+		return commandMessage("There are no servers right now"), nil
+	}
+	var trackIds []uint
+	for _, server := range servers {
+		if !server.OutsideTrack {
+			trackIds = append(trackIds, server.Track)
+		}
+	}
+	tracks, err := d.TrackCtrl.GetTracksByIds(trackIds, models.Base)
+	if err != nil {
+		return nil, err
+	}
+	trackNames := map[uint]string{}
+	for _, track := range tracks {
+		trackNames[track.Id] = track.Name
+	}
+
+	var embeds []*discordgo.MessageEmbed
+	for _, server := range servers {
+		if len(embeds) == maxServerEmbeds {
+			break
+		}
+		status := "Offline"
+		if server.Online {
+			status = "Online"
+		}
+		track := trackNames[server.Track]
+		if server.OutsideTrack {
+			track = server.OutsideTrackName
+		}
+		embed := &discordgo.MessageEmbed{
+			Title:       server.Name,
+			URL:         server.JoinLink,
+			Description: server.Description,
+			Color:       embedColor,
+			Fields: []*discordgo.MessageEmbedField{
+				{Name: "Status", Value: status, Inline: true},
+				{Name: "Cars", Value: strconv.Itoa(len(server.Cars) + len(server.OutsideCars)), Inline: true},
+			},
+		}
+		if track != "" {
+			embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Track", Value: track, Inline: true})
+		}
+		embeds = append(embeds, embed)
+	}
+	return commandEmbeds(embeds), nil
+}
+
+func (d DiscordCommandsImpl) latest() (*discordgo.InteractionResponse, error) {
+	entries, err := d.FeedCtrl.GetFeed("", models.FeedFilter{}, maxCommandEmbeds)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return commandMessage("Nothing has been released yet"), nil
+	}
+	var embeds []*discordgo.MessageEmbed
+	for _, entry := range entries {
+		embed := &discordgo.MessageEmbed{
+			Title:     entry.Title,
+			URL:       entry.Link,
+			Color:     embedColor,
+			Timestamp: entry.HappenedAt.UTC().Format(time.RFC3339),
+			Fields: []*discordgo.MessageEmbedField{
+				{Name: "Author", Value: entry.Author.Name, Inline: true},
+			},
+		}
+		if entry.Version != "" {
+			embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Version", Value: entry.Version, Inline: true})
+		}
+		if entry.ImageUrl != "" {
+			embed.Thumbnail = &discordgo.MessageEmbedThumbnail{URL: entry.ImageUrl}
+		}
+		embeds = append(embeds, embed)
+	}
+	return commandEmbeds(embeds), nil
+}
+
+// carChoices suggests the brands matching the query first, then the cars. Only the first cars matching the query are
+// loaded, the choices are capped anyway
+func (d DiscordCommandsImpl) carChoices(query string) ([]string, error) {
+	cars, err := d.CarCtrl.SearchCars(query, models.Base, maxCommandChoices)
+	if err != nil {
+		return nil, err
+	}
+	var brands, names []string
+	seen := map[string]bool{}
+	for _, car := range cars {
+		if !seen[car.Brand.Name] && matchesQuery(car.Brand.Name, query) {
+			seen[car.Brand.Name] = true
+			brands = append(brands, car.Brand.Name)
+		}
+		if name := fmt.Sprintf("%v %v", car.Brand.Name, car.ModelName); !seen[name] && matchesQuery(name, query) {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(brands)
+	sort.Strings(names)
+	return append(brands, names...), nil
+}
+
+func (d DiscordCommandsImpl) trackChoices(query string) ([]string, error) {
+	tracks, err := d.TrackCtrl.SearchTracks(query, models.Base, maxCommandChoices)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	seen := map[string]bool{}
+	for _, track := range tracks {
+		if !seen[track.Name] {
+			seen[track.Name] = true
+			names = append(names, track.Name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func autocompleteResponse(values []string) *discordgo.InteractionResponse {
+	choices := []*discordgo.ApplicationCommandOptionChoice{}
+	for _, value := range values {
+		if len(choices) == maxCommandChoices {
+			break
+		}
+		if len(value) > maxChoiceLength {
+			continue
+		}
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: value, Value: value})
+	}
+	return &discordgo.InteractionResponse{
+		Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+		Data: &discordgo.InteractionResponseData{Choices: choices},
+	}
+}
+
+func commandEmbeds(embeds []*discordgo.MessageEmbed) *discordgo.InteractionResponse {
+	return &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{Embeds: embeds},
+	}
+}
+
+// commandMessage is only shown to the member who used the command
+func commandMessage(content string) *discordgo.InteractionResponse {
+	return &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{Content: content, Flags: discordgo.MessageFlagsEphemeral},
+	}
+}
@@ -0,0 +1,333 @@
+package controllers
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/davide/ModRepository/models"
+)
+
+// the stubs embed the interfaces, only the methods used by the commands are implemented
+
+type stubCarController struct {
+	CarController
+	cars []models.Car
+}
+
+// SearchCars matches the query like the repository, the whole catalog is never loaded by the commands
+func (s stubCarController) SearchCars(query string, _ models.Role, limit int) ([]models.Car, error) {
+	var cars []models.Car
+	for _, car := range s.cars {
+		if matchesQuery(carName(car), query) && len(cars) < limit {
+			cars = append(cars, car)
+		}
+	}
+	return cars, nil
+}
+
+type stubTrackController struct {
+	TrackController
+	tracks []models.Track
+}
+
+func (s stubTrackController) SearchTracks(query string, _ models.Role, limit int) ([]models.Track, error) {
+	var tracks []models.Track
+	for _, track := range s.tracks {
+		if matchesQuery(trackName(track)+" "+track.Location, query) && len(tracks) < limit {
+			tracks = append(tracks, track)
+		}
+	}
+	return tracks, nil
+}
+
+func (s stubTrackController) GetTracksByIds(ids []uint, _ models.Role) ([]models.Track, error) {
+	var tracks []models.Track
+	for _, track := range s.tracks {
+		for _, id := range ids {
+			if track.Id == id {
+				tracks = append(tracks, track)
+			}
+		}
+	}
+	return tracks, nil
+}
+
+type stubServersController struct {
+	ServersController
+	servers []models.Server
+}
+
+func (s stubServersController) GetAllServers() ([]models.Server, error) {
+	return s.servers, nil
+}
+
+type stubFeedController struct {
+	entries []models.FeedEntry
+}
+
+func (s stubFeedController) GetFeed(_ models.ModType, _ models.FeedFilter, limit int) ([]models.FeedEntry, error) {
+	if limit > 0 && len(s.entries) > limit {
+		return s.entries[:limit], nil
+	}
+	return s.entries, nil
+}
+
+func testCar(id uint, brand string, model string) models.Car {
+	return models.Car{
+		Mod:       models.Mod{Id: id, Version: "1.0", Author: models.Author{Name: "Kunos"}},
+		Brand:     models.CarBrand{Name: brand},
+		ModelName: model,
+		Year:      1990,
+	}
+}
+
+func commandInteraction(name string, query string) *discordgo.Interaction {
+	data := discordgo.ApplicationCommandInteractionData{Name: name}
+	if query != "" {
+		data.Options = []*discordgo.ApplicationCommandInteractionDataOption{
+			{Name: "query", Type: discordgo.ApplicationCommandOptionString, Value: query},
+		}
+	}
+	return &discordgo.Interaction{Type: discordgo.InteractionApplicationCommand, Data: data}
+}
+
+func autocompleteInteraction(name string, query string) *discordgo.Interaction {
+	interaction := commandInteraction(name, query)
+	interaction.Type = discordgo.InteractionApplicationCommandAutocomplete
+	return interaction
+}
+
+func TestHandleInteraction(t *testing.T) {
+	cars := []models.Car{testCar(1, "Ferrari", "F40"), testCar(2, "Ferrari", "F50"), testCar(3, "Porsche", "911 GT1")}
+	var manyCars []models.Car
+	for i := 0; i < 30; i++ {
+		manyCars = append(manyCars, testCar(uint(i+1), "Lotus", fmt.Sprintf("Type %02d", i)))
+	}
+	// sorted before the others, it must be skipped and not counted
+	manyCars = append(manyCars, testCar(31, "Lotus", "A"+strings.Repeat("x", 100)))
+	tracks := []models.Track{
+		{Mod: models.Mod{Id: 1}, Name: "Monza", Location: "Monza", Year: 2020, Nation: models.Nation{Name: "Italy"}},
+		{Mod: models.Mod{Id: 2}, Name: "Spa", Location: "Stavelot", Year: 2020, Nation: models.Nation{Name: "Belgium"}},
+	}
+	servers := []models.Server{
+		{Id: 1, Name: "GT Night", Online: true, Track: 1, Cars: []uint{1, 2}},
+		{Id: 2, Name: "Rally", OutsideTrack: true, OutsideTrackName: "Rally Finland"},
+	}
+	entries := []models.FeedEntry{
+		{Title: "Ferrari F40 1990 has been updated to 1.1", Version: "1.1", Author: models.Author{Name: "Kunos"}, HappenedAt: time.Now()},
+	}
+
+	commands := DiscordCommandsImpl{
+		CarCtrl:    stubCarController{cars: cars},
+		TrackCtrl:  stubTrackController{tracks: tracks},
+		ServerCtrl: stubServersController{servers: servers},
+		FeedCtrl:   stubFeedController{entries: entries},
+	}
+	manyCommands := commands
+	manyCommands.CarCtrl = stubCarController{cars: manyCars}
+	emptyCommands := DiscordCommandsImpl{
+		CarCtrl:    stubCarController{},
+		TrackCtrl:  stubTrackController{},
+		ServerCtrl: stubServersController{},
+		FeedCtrl:   stubFeedController{},
+	}
+
+	tests := []struct {
+		name        string
+		commands    DiscordCommandsImpl
+		interaction *discordgo.Interaction
+		check       func(t *testing.T, response *discordgo.InteractionResponse)
+		wantErr     bool
+	}{
+		{
+			name:        "car search",
+			commands:    commands,
+			interaction: commandInteraction("car", "ferrari f40"),
+			check: func(t *testing.T, response *discordgo.InteractionResponse) {
+				expectEmbeds(t, response, "Ferrari F40 1990")
+			},
+		},
+		{
+			name:        "car search is capped",
+			commands:    manyCommands,
+			interaction: commandInteraction("car", "lotus"),
+			check: func(t *testing.T, response *discordgo.InteractionResponse) {
+				if len(response.Data.Embeds) != maxCommandEmbeds {
+					t.Errorf("expected %v embeds, got %v", maxCommandEmbeds, len(response.Data.Embeds))
+				}
+			},
+		},
+		{
+			name:        "car without matches",
+			commands:    commands,
+			interaction: commandInteraction("car", "lamborghini"),
+			check: func(t *testing.T, response *discordgo.InteractionResponse) {
+				expectMessage(t, response, `No car matches "lamborghini"`)
+			},
+		},
+		{
+			name:        "track search by location",
+			commands:    commands,
+			interaction: commandInteraction("track", "stavelot"),
+			check: func(t *testing.T, response *discordgo.InteractionResponse) {
+				expectEmbeds(t, response, "Spa 2020")
+				if description := response.Data.Embeds[0].Description; description != "Stavelot, Belgium" {
+					t.Errorf("unexpected description %q", description)
+				}
+			},
+		},
+		{
+			name:        "server list",
+			commands:    commands,
+			interaction: commandInteraction("server", ""),
+			check: func(t *testing.T, response *discordgo.InteractionResponse) {
+				expectEmbeds(t, response, "GT Night", "Rally")
+				expectField(t, response.Data.Embeds[0], "Status", "Online")
+				expectField(t, response.Data.Embeds[0], "Track", "Monza")
+				expectField(t, response.Data.Embeds[0], "Cars", "2")
+				expectField(t, response.Data.Embeds[1], "Status", "Offline")
+				expectField(t, response.Data.Embeds[1], "Track", "Rally Finland")
+			},
+		},
+		{
+			name:        "no servers",
+			commands:    emptyCommands,
+			interaction: commandInteraction("server", ""),
+			check: func(t *testing.T, response *discordgo.InteractionResponse) {
+				expectMessage(t, response, "There are no servers right now")
+			},
+		},
+		{
+			name:        "latest",
+			commands:    commands,
+			interaction: commandInteraction("latest", ""),
+			check: func(t *testing.T, response *discordgo.InteractionResponse) {
+				expectEmbeds(t, response, "Ferrari F40 1990 has been updated to 1.1")
+				expectField(t, response.Data.Embeds[0], "Version", "1.1")
+			},
+		},
+		{
+			name:        "nothing released",
+			commands:    emptyCommands,
+			interaction: commandInteraction("latest", ""),
+			check: func(t *testing.T, response *discordgo.InteractionResponse) {
+				expectMessage(t, response, "Nothing has been released yet")
+			},
+		},
+		{
+			name:        "car autocomplete suggests brands first",
+			commands:    commands,
+			interaction: autocompleteInteraction("car", "f"),
+			check: func(t *testing.T, response *discordgo.InteractionResponse) {
+				expectChoices(t, response, "Ferrari", "Ferrari F40", "Ferrari F50")
+			},
+		},
+		{
+			name:        "autocomplete limits",
+			commands:    manyCommands,
+			interaction: autocompleteInteraction("car", "lotus"),
+			check: func(t *testing.T, response *discordgo.InteractionResponse) {
+				if response.Type != discordgo.InteractionApplicationCommandAutocompleteResult {
+					t.Errorf("unexpected response type %v", response.Type)
+				}
+				if len(response.Data.Choices) != maxCommandChoices {
+					t.Errorf("expected %v choices, got %v", maxCommandChoices, len(response.Data.Choices))
+				}
+				for _, choice := range response.Data.Choices {
+					if len(choice.Name) > maxChoiceLength {
+						t.Errorf("choice longer than %v characters: %v", maxChoiceLength, choice.Name)
+					}
+				}
+				if first, second := response.Data.Choices[0].Name, response.Data.Choices[1].Name; first != "Lotus" || second != "Lotus Type 00" {
+					t.Errorf("unexpected first choices %q, %q", first, second)
+				}
+			},
+		},
+		{
+			name:        "track autocomplete",
+			commands:    commands,
+			interaction: autocompleteInteraction("track", "mon"),
+			check: func(t *testing.T, response *discordgo.InteractionResponse) {
+				expectChoices(t, response, "Monza")
+			},
+		},
+		{
+			name:        "unknown command",
+			commands:    commands,
+			interaction: commandInteraction("weather", ""),
+			wantErr:     true,
+		},
+		{
+			name:        "unsupported interaction",
+			commands:    commands,
+			interaction: &discordgo.Interaction{Type: discordgo.InteractionPing},
+			wantErr:     true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			response, err := test.commands.HandleInteraction(test.interaction)
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %+v", response)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			test.check(t, response)
+		})
+	}
+}
+
+func expectEmbeds(t *testing.T, response *discordgo.InteractionResponse, titles ...string) {
+	t.Helper()
+	if len(response.Data.Embeds) != len(titles) {
+		t.Fatalf("expected %v embeds, got %v", len(titles), len(response.Data.Embeds))
+	}
+	for i, title := range titles {
+		if response.Data.Embeds[i].Title != title {
+			t.Errorf("embed %v: expected %q, got %q", i, title, response.Data.Embeds[i].Title)
+		}
+	}
+}
+
+func expectMessage(t *testing.T, response *discordgo.InteractionResponse, content string) {
+	t.Helper()
+	if response.Data.Content != content {
+		t.Errorf("expected %q, got %q", content, response.Data.Content)
+	}
+	if response.Data.Flags&discordgo.MessageFlagsEphemeral == 0 {
+		t.Error("the message should be ephemeral")
+	}
+}
+
+func expectField(t *testing.T, embed *discordgo.MessageEmbed, name string, value string) {
+	t.Helper()
+	for _, field := range embed.Fields {
+		if field.Name == name {
+			if field.Value != value {
+				t.Errorf("field %v: expected %q, got %q", name, value, field.Value)
+			}
+			return
+		}
+	}
+	t.Errorf("field %v missing from %q", name, embed.Title)
+}
+
+func expectChoices(t *testing.T, response *discordgo.InteractionResponse, names ...string) {
+	t.Helper()
+	if len(response.Data.Choices) != len(names) {
+		t.Fatalf("expected %v choices, got %v", len(names), len(response.Data.Choices))
+	}
+	for i, name := range names {
+		if response.Data.Choices[i].Name != name {
+			t.Errorf("choice %v: expected %q, got %q", i, name, response.Data.Choices[i].Name)
+		}
+	}
+}
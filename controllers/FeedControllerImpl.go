@@ -0,0 +1,162 @@
+package controllers
+
+import (
+	"fmt"
+	"github.com/davide/ModRepository/controllers/helpers"
+	"github.com/davide/ModRepository/models"
+	"github.com/davide/ModRepository/repositories"
+	"sort"
+	"strings"
+)
+
+type FeedControllerImpl struct {
+	LogRepo   repositories.LogRepository
+	CarRepo   repositories.CarRepository
+	TrackRepo repositories.TrackRepository
+}
+
+// GetFeed returns the latest releases and updates, newest first. An empty modType mixes cars and tracks
+func (f FeedControllerImpl) GetFeed(modType models.ModType, filter models.FeedFilter, limit int) ([]models.FeedEntry, error) {
+	var entries []models.FeedEntry
+	if modType == "" || modType == models.CarModType {
+		carEntries, err := f.carEntries(filter, limit)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, carEntries...)
+	}
+	if modType == "" || modType == models.TrackModType {
+		trackEntries, err := f.trackEntries(filter, limit)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, trackEntries...)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].HappenedAt.After(entries[j].HappenedAt)
+	})
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries, nil
+}
+
+// carEntries loads only the latest logs matching the filter and the cars they name
+func (f FeedControllerImpl) carEntries(filter models.FeedFilter, limit int) ([]models.FeedEntry, error) {
+	entries := []models.FeedEntry{}
+	logs, err := f.LogRepo.SelectCarLogs(filter, limit)
+	if err != nil {
+		return nil, err
+	}
+	if len(logs) == 0 {
+		return entries, nil
+	}
+	var ids []uint
+	for _, log := range logs {
+		ids = append(ids, log.CarId)
+	}
+	cars, err := f.CarRepo.SelectCarsByIds(ids, false, false)
+	if err != nil {
+		return nil, err
+	}
+	carsById := map[uint]models.Car{}
+	for _, car := range cars {
+		carsById[car.Id] = car
+	}
+
+	for _, log := range logs {
+		car, ok := carsById[log.CarId]
+		if !ok {
+			continue
+		}
+		var categories []string
+		for _, category := range car.Categories {
+			categories = append(categories, string(category.Name))
+		}
+		entries = append(entries, models.FeedEntry{
+			Id:         fmt.Sprintf("car-%v-%v", log.CarId, log.LogId),
+			ModType:    models.CarModType,
+			ModId:      log.CarId,
+			Title:      feedTitle(fmt.Sprintf("%v %v %v", car.Brand.Name, car.ModelName, car.Year), log.Action, log.Version),
+			Action:     log.Action,
+			Version:    log.Version,
+			Author:     car.Author,
+			ImageUrl:   helpers.FavoriteImageUrl(car.Images),
+			Link:       helpers.CarPageUrl(car),
+			Premium:    car.Premium,
+			Categories: categories,
+			HappenedAt: log.HappenedAt,
+		})
+	}
+	return entries, nil
+}
+
+// trackEntries loads only the latest logs matching the filter and the tracks they name
+func (f FeedControllerImpl) trackEntries(filter models.FeedFilter, limit int) ([]models.FeedEntry, error) {
+	entries := []models.FeedEntry{}
+	logs, err := f.LogRepo.SelectTrackLogs(filter, limit)
+	if err != nil {
+		return nil, err
+	}
+	if len(logs) == 0 {
+		return entries, nil
+	}
+	var ids []uint
+	for _, log := range logs {
+		ids = append(ids, log.TrackId)
+	}
+	tracks, err := f.TrackRepo.SelectTracksByIds(ids, false, false)
+	if err != nil {
+		return nil, err
+	}
+	tracksById := map[uint]models.Track{}
+	for _, track := range tracks {
+		tracksById[track.Id] = track
+	}
+
+	for _, log := range logs {
+		track, ok := tracksById[log.TrackId]
+		if !ok {
+			continue
+		}
+		var tags []string
+		for _, tag := range track.Tags {
+			tags = append(tags, string(tag))
+		}
+		entries = append(entries, models.FeedEntry{
+			Id:         fmt.Sprintf("track-%v-%v", log.TrackId, log.LogId),
+			ModType:    models.TrackModType,
+			ModId:      log.TrackId,
+			Title:      feedTitle(fmt.Sprintf("%v %v", track.Name, track.Year), log.Action, log.Version),
+			Action:     log.Action,
+			Version:    log.Version,
+			Author:     track.Author,
+			ImageUrl:   helpers.FavoriteImageUrl(track.Images),
+			Link:       helpers.TrackPageUrl(track),
+			Premium:    track.Premium,
+			Categories: tags,
+			HappenedAt: log.HappenedAt,
+		})
+	}
+	return entries, nil
+}
+
+func feedTitle(name string, action models.Action, version string) string {
+	if action == models.Update {
+		return fmt.Sprintf("%v has been updated to %v", name, version)
+	}
+	return fmt.Sprintf("%v has been added to the repository", name)
+}
+
+func containsFold(values []string, value string) bool {
+	if value == "" {
+		return true
+	}
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
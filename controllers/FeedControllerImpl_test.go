@@ -0,0 +1,65 @@
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/davide/ModRepository/models"
+	"github.com/davide/ModRepository/repositories"
+)
+
+// stubLogRepository records the filter and the limit of the queries and returns the logs it has
+type stubLogRepository struct {
+	repositories.LogRepository
+	carLogs []models.CarLog
+	filters []models.FeedFilter
+	limits  []int
+}
+
+func (s *stubLogRepository) SelectCarLogs(filter models.FeedFilter, limit int) ([]models.CarLog, error) {
+	s.filters = append(s.filters, filter)
+	s.limits = append(s.limits, limit)
+	return s.carLogs, nil
+}
+
+func (s *stubLogRepository) SelectTrackLogs(filter models.FeedFilter, limit int) ([]models.TrackLog, error) {
+	s.filters = append(s.filters, filter)
+	s.limits = append(s.limits, limit)
+	return []models.TrackLog{}, nil
+}
+
+type stubFeedCarRepository struct {
+	repositories.CarRepository
+	cars []models.Car
+}
+
+func (s stubFeedCarRepository) SelectCarsByIds(_ []uint, _ bool, _ bool) ([]models.Car, error) {
+	return s.cars, nil
+}
+
+func TestGetFeedQueriesOnlyTheLatestMatchingLogs(t *testing.T) {
+	now := time.Now()
+	logs := &stubLogRepository{carLogs: []models.CarLog{
+		{LogId: 2, CarId: 1, Action: models.Update, Version: "1.1", HappenedAt: now},
+		{LogId: 1, CarId: 1, Action: models.Insert, HappenedAt: now.Add(-time.Hour)},
+	}}
+	ctrl := FeedControllerImpl{
+		LogRepo: logs,
+		CarRepo: stubFeedCarRepository{cars: []models.Car{{Mod: models.Mod{Id: 1}, ModelName: "F40", Brand: models.CarBrand{Name: "Ferrari"}}}},
+	}
+	filter := models.FeedFilter{Brand: "Ferrari", Author: "Mario"}
+
+	entries, err := ctrl.GetFeed("", filter, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logs.filters) != 2 || logs.filters[0] != filter || logs.filters[1] != filter {
+		t.Errorf("the filter should reach both queries, got %v", logs.filters)
+	}
+	if len(logs.limits) != 2 || logs.limits[0] != 2 || logs.limits[1] != 2 {
+		t.Errorf("the limit should reach both queries, got %v", logs.limits)
+	}
+	if len(entries) != 2 || entries[0].Version != "1.1" {
+		t.Errorf("unexpected entries %+v", entries)
+	}
+}
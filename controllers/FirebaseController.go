@@ -4,72 +4,258 @@ import (
 	"context"
 	"firebase.google.com/go/v4/messaging"
 	"fmt"
+	"github.com/davide/ModRepository/controllers/helpers"
 	"github.com/davide/ModRepository/models"
+	"github.com/davide/ModRepository/repositories"
+	"log"
+	"strings"
+	"time"
 )
 
+// a condition combines at most five topics
+const maxConditionTopics = 5
+
+// invalidToken tells if the error of a dry run is a token Firebase doesn't know or can't parse, the tests replace it
+// since the messaging errors can't be built outside of the sdk
+var invalidToken = func(err error) bool {
+	return messaging.IsUnregistered(err) || messaging.IsInvalidArgument(err)
+}
+
+// MessagingClient is the part of the Firebase messaging client in use, *messaging.Client implements it
+type MessagingClient interface {
+	Send(ctx context.Context, message *messaging.Message) (string, error)
+	SendDryRun(ctx context.Context, message *messaging.Message) (string, error)
+	SubscribeToTopic(ctx context.Context, tokens []string, topic string) (*messaging.TopicManagementResponse, error)
+	UnsubscribeFromTopic(ctx context.Context, tokens []string, topic string) (*messaging.TopicManagementResponse, error)
+}
+
+// FirebaseControllerImpl keeps a record of the devices subscribed by every user, the tokens reported as invalid
+// by Firebase are deleted
 type FirebaseControllerImpl struct {
-	Client  *messaging.Client
+	Client  MessagingClient
 	Context context.Context
+	Repo    repositories.DeviceTokenRepository
 }
 
-func (f FirebaseControllerImpl) RegisterToTopic(token string, topic string) error {
-	if _, err := f.Client.SubscribeToTopic(f.Context, []string{token}, topic); err != nil {
+func (f FirebaseControllerImpl) RegisterToTopic(username string, token string, topic string) error {
+	if err := helpers.ValidateTopic(topic); err != nil {
+		return err
+	}
+	if strings.TrimSpace(token) == "" {
+		return models.NewValidationError("token", "is required")
+	}
+	response, err := f.Client.SubscribeToTopic(f.Context, []string{token}, topic)
+	if err != nil {
 		return err
 	}
-	return nil
+	if err := f.topicManagementError(token, response); err != nil {
+		return err
+	}
+	return f.Repo.InsertDeviceTopic(username, token, topic)
 }
 
-func (f FirebaseControllerImpl) NotifyCarUpdated(car models.Car) error {
-	payload := &messaging.Message{
-		Webpush: &messaging.WebpushConfig{Notification: &messaging.WebpushNotification{Actions: []*messaging.WebpushNotificationAction{
-			{Action: "car_updated", Title: "Check It Out!"},
-		}}},
-		Notification: &messaging.Notification{Title: fmt.Sprintf("%v %v has been updated", car.Brand.Name, car.ModelName), Body: "A resource has been updated", ImageURL: "https://imgur.com/0GuN24g"},
-		Topic:        "modsUpdates",
+// UnregisterFromTopic unsubscribes a device of the user, the subscriptions of others are not found
+func (f FirebaseControllerImpl) UnregisterFromTopic(username string, token string, topic string) error {
+	devices, err := f.Repo.SelectDeviceTokensByUser(username)
+	if err != nil {
+		return err
+	}
+	if !followsTopic(devices, token, topic) {
+		return fmt.Errorf("%w: the device doesn't follow %v", models.ErrNotFound, topic)
 	}
-	if _, err := f.Client.Send(f.Context, payload); err != nil {
+	response, err := f.Client.UnsubscribeFromTopic(f.Context, []string{token}, topic)
+	if err != nil {
 		return err
 	}
-	return nil
+	if err := f.topicManagementError(token, response); err != nil {
+		return err
+	}
+	return f.Repo.DeleteDeviceTopic(username, token, topic)
 }
 
-func (f FirebaseControllerImpl) NotifyCarAdded(car models.Car) error {
-	payload := &messaging.Message{
-		Webpush: &messaging.WebpushConfig{Notification: &messaging.WebpushNotification{Actions: []*messaging.WebpushNotificationAction{
-			{Action: "car_added", Title: "Check It Out!"},
-		}}},
-		Notification: &messaging.Notification{Title: fmt.Sprintf("%v %v has been added to repository", car.Brand.Name, car.ModelName), Body: "A resource has been added", ImageURL: "https://imgur.com/0GuN24g"},
-		Topic:        "modsUpdates",
+func followsTopic(devices []models.DeviceToken, token string, topic string) bool {
+	for _, device := range devices {
+		if device.Token != token {
+			continue
+		}
+		for _, t := range device.Topics {
+			if t == topic {
+				return true
+			}
+		}
 	}
-	if _, err := f.Client.Send(f.Context, payload); err != nil {
-		return err
+	return false
+}
+
+func (f FirebaseControllerImpl) GetDevices(username string) ([]models.DeviceToken, error) {
+	return f.Repo.SelectDeviceTokensByUser(username)
+}
+
+// topicManagementError turns the failure of a single token into an error, an invalid token is pruned
+func (f FirebaseControllerImpl) topicManagementError(token string, response *messaging.TopicManagementResponse) error {
+	if response == nil || response.FailureCount == 0 || len(response.Errors) == 0 {
+		return nil
 	}
-	return nil
+	reason := response.Errors[0].Reason
+	if reason == "NOT_FOUND" || reason == "INVALID_ARGUMENT" {
+		if err := f.Repo.DeleteDeviceTokens([]string{token}); err != nil {
+			log.Printf("error pruning an invalid device token: %v", err)
+		}
+		return models.NewValidationError("token", "is not a valid registration token")
+	}
+	return fmt.Errorf("firebase refused the token: %v", reason)
 }
 
-func (f FirebaseControllerImpl) NotifyTrackAdded(track models.Track) error {
-	payload := &messaging.Message{
-		Webpush: &messaging.WebpushConfig{Notification: &messaging.WebpushNotification{Actions: []*messaging.WebpushNotificationAction{
-			{Action: "track_added", Title: "Check It Out!"},
-		}}},
-		Notification: &messaging.Notification{Title: fmt.Sprintf("%v has been added to repository", track.Name), Body: "A resource has been added", ImageURL: "https://imgur.com/0GuN24g"},
-		Topic:        "modsUpdates",
+// PruneInvalidTokens validates a message to every recorded token and deletes the ones Firebase doesn't know
+// anymore. Every token is sent on its own, the multicast dry run used the batch endpoint Google shut down.
+// A token failing for another reason is kept and counted in the returned error, the run goes on with the others
+func (f FirebaseControllerImpl) PruneInvalidTokens() (int, error) {
+	tokens, err := f.Repo.SelectAllDeviceTokens()
+	if err != nil {
+		return 0, err
 	}
-	if _, err := f.Client.Send(f.Context, payload); err != nil {
-		return err
+	var invalid []string
+	failed := 0
+	var firstErr error
+	for _, token := range tokens {
+		if _, err := f.Client.SendDryRun(f.Context, &messaging.Message{Token: token}); err != nil {
+			if invalidToken(err) {
+				invalid = append(invalid, token)
+				continue
+			}
+			if firstErr == nil {
+				firstErr = err
+			}
+			failed++
+		}
 	}
-	return nil
+	if err := f.Repo.DeleteDeviceTokens(invalid); err != nil {
+		return 0, err
+	}
+	if failed > 0 {
+		return len(invalid), fmt.Errorf("%v tokens could not be checked: %w", failed, firstErr)
+	}
+	return len(invalid), nil
+}
+
+// RunPruning prunes the invalid tokens every interval until ctx is done
+func (f FirebaseControllerImpl) RunPruning(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			pruned, err := f.PruneInvalidTokens()
+			if err != nil {
+				log.Printf("error pruning the device tokens: %v", err)
+			}
+			if pruned > 0 {
+				log.Printf("pruned %v invalid device tokens", pruned)
+			}
+		}
+	}
+}
+
+func (f FirebaseControllerImpl) NotifyCarUpdated(car models.Car) error {
+	_, err := f.NotifyTargets(models.CarUpdatedEvent, car, nil)
+	return err
 }
+
+func (f FirebaseControllerImpl) NotifyCarAdded(car models.Car) error {
+	_, err := f.NotifyTargets(models.CarAddedEvent, car, nil)
+	return err
+}
+
+func (f FirebaseControllerImpl) NotifyTrackAdded(track models.Track) error {
+	_, err := f.NotifyTargets(models.TrackAddedEvent, track, nil)
+	return err
+}
+
 func (f FirebaseControllerImpl) NotifyTrackUpdated(track models.Track) error {
-	payload := &messaging.Message{
+	_, err := f.NotifyTargets(models.TrackUpdatedEvent, track, nil)
+	return err
+}
+
+// NotifyTargets publishes the announcement of the event to the topic conditions not in delivered, the targets are
+// the conditions so a retry doesn't send again the ones that went through
+func (f FirebaseControllerImpl) NotifyTargets(event models.LiveEventType, payload interface{}, delivered []string) ([]string, error) {
+	message, topics, err := announcement(event, payload)
+	if err != nil {
+		return delivered, err
+	}
+	return f.send(message, topics, delivered)
+}
+
+func announcement(event models.LiveEventType, payload interface{}) (messaging.Message, []string, error) {
+	switch mod := payload.(type) {
+	case models.Car:
+		switch event {
+		case models.CarAddedEvent:
+			return modMessage("car_added", fmt.Sprintf("%v %v has been added to repository", mod.Brand.Name, mod.ModelName), "A resource has been added"), helpers.CarTopics(mod), nil
+		case models.CarUpdatedEvent:
+			return modMessage("car_updated", fmt.Sprintf("%v %v has been updated", mod.Brand.Name, mod.ModelName), "A resource has been updated"), helpers.CarTopics(mod), nil
+		}
+	case models.Track:
+		switch event {
+		case models.TrackAddedEvent:
+			return modMessage("track_added", fmt.Sprintf("%v has been added to repository", mod.Name), "A resource has been added"), helpers.TrackTopics(mod), nil
+		case models.TrackUpdatedEvent:
+			return modMessage("track_updated", fmt.Sprintf("%v has been updated", mod.Name), "A resource has been updated"), helpers.TrackTopics(mod), nil
+		}
+	}
+	return messaging.Message{}, nil, fmt.Errorf("%v is not a notification for %T", event, payload)
+}
+
+func modMessage(action string, title string, body string) messaging.Message {
+	return messaging.Message{
 		Webpush: &messaging.WebpushConfig{Notification: &messaging.WebpushNotification{Actions: []*messaging.WebpushNotificationAction{
-			{Action: "track_updated", Title: "Check It Out!"},
+			{Action: action, Title: "Check It Out!"},
 		}}},
-		Notification: &messaging.Notification{Title: fmt.Sprintf("%v has been updated", track.Name), Body: "A resource has been updated", ImageURL: "https://imgur.com/0GuN24g"},
-		Topic:        "modsUpdates",
+		Notification: &messaging.Notification{Title: title, Body: body, ImageURL: "https://imgur.com/0GuN24g"},
 	}
-	if _, err := f.Client.Send(f.Context, payload); err != nil {
-		return err
+}
+
+// send publishes the message to every topic condition not in delivered and returns them with the ones sent now.
+// A condition combines at most five topics, so a device following topics of two conditions gets the message once
+// for each of them: the topics of a mod are ordered from the broadest, most of the devices match the first condition
+// and the duplicates are limited to the ones following only the narrower topics of several conditions
+func (f FirebaseControllerImpl) send(message messaging.Message, topics []string, delivered []string) ([]string, error) {
+	skip := map[string]bool{}
+	for _, condition := range delivered {
+		skip[condition] = true
+	}
+	var failed error
+	for _, condition := range topicConditions(topics) {
+		if skip[condition] {
+			continue
+		}
+		payload := message
+		payload.Condition = condition
+		if _, err := f.Client.Send(f.Context, &payload); err != nil {
+			if failed == nil {
+				failed = fmt.Errorf("error sending to %v: %w", condition, err)
+			}
+			continue
+		}
+		delivered = append(delivered, condition)
+	}
+	return delivered, failed
+}
+
+func topicConditions(topics []string) []string {
+	var conditions []string
+	for start := 0; start < len(topics); start += maxConditionTopics {
+		end := start + maxConditionTopics
+		if end > len(topics) {
+			end = len(topics)
+		}
+		var terms []string
+		for _, topic := range topics[start:end] {
+			terms = append(terms, fmt.Sprintf("'%v' in topics", topic))
+		}
+		conditions = append(conditions, strings.Join(terms, " || "))
 	}
-	return nil
+	return conditions
 }
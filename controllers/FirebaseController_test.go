@@ -0,0 +1,305 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"firebase.google.com/go/v4/messaging"
+	"github.com/davide/ModRepository/controllers/helpers"
+	"github.com/davide/ModRepository/models"
+)
+
+var (
+	// errUnregistered stands for the error Firebase returns for a token it doesn't know
+	errUnregistered = errors.New("unregistered")
+	// errUnavailable is a transient failure, the token may be valid
+	errUnavailable = errors.New("unavailable")
+)
+
+// fakeMessagingClient rejects the tokens in invalid, fails the dry runs of the ones in unavailable, fails once the
+// conditions in failing and records the sent conditions and the topic subscriptions
+type fakeMessagingClient struct {
+	invalid      map[string]bool
+	unavailable  map[string]bool
+	failing      map[string]bool
+	sent         []string
+	subscribed   []string
+	unsubscribed []string
+	dryRuns      []string
+}
+
+func (f *fakeMessagingClient) Send(_ context.Context, message *messaging.Message) (string, error) {
+	if f.failing[message.Condition] {
+		f.failing[message.Condition] = false
+		return "", errUnavailable
+	}
+	f.sent = append(f.sent, message.Condition)
+	return "sent", nil
+}
+
+func (f *fakeMessagingClient) SendDryRun(_ context.Context, message *messaging.Message) (string, error) {
+	f.dryRuns = append(f.dryRuns, message.Token)
+	if f.invalid[message.Token] {
+		return "", errUnregistered
+	}
+	if f.unavailable[message.Token] {
+		return "", errUnavailable
+	}
+	return "validated", nil
+}
+
+func (f *fakeMessagingClient) topicResponse(token string) *messaging.TopicManagementResponse {
+	if f.invalid[token] {
+		return &messaging.TopicManagementResponse{FailureCount: 1, Errors: []*messaging.ErrorInfo{{Reason: "NOT_FOUND"}}}
+	}
+	return &messaging.TopicManagementResponse{SuccessCount: 1}
+}
+
+func (f *fakeMessagingClient) SubscribeToTopic(_ context.Context, tokens []string, topic string) (*messaging.TopicManagementResponse, error) {
+	f.subscribed = append(f.subscribed, topic)
+	return f.topicResponse(tokens[0]), nil
+}
+
+func (f *fakeMessagingClient) UnsubscribeFromTopic(_ context.Context, tokens []string, topic string) (*messaging.TopicManagementResponse, error) {
+	f.unsubscribed = append(f.unsubscribed, topic)
+	return f.topicResponse(tokens[0]), nil
+}
+
+// memoryDeviceTokenRepository keeps the topics of every token of every user
+type memoryDeviceTokenRepository struct {
+	topics map[string]map[string][]string
+}
+
+func newMemoryDeviceTokenRepository() *memoryDeviceTokenRepository {
+	return &memoryDeviceTokenRepository{topics: map[string]map[string][]string{}}
+}
+
+func (m *memoryDeviceTokenRepository) InsertDeviceTopic(username string, token string, topic string) error {
+	if m.topics[username] == nil {
+		m.topics[username] = map[string][]string{}
+	}
+	m.topics[username][token] = append(m.topics[username][token], topic)
+	return nil
+}
+
+func (m *memoryDeviceTokenRepository) DeleteDeviceTopic(username string, token string, topic string) error {
+	var topics []string
+	for _, t := range m.topics[username][token] {
+		if t != topic {
+			topics = append(topics, t)
+		}
+	}
+	m.topics[username][token] = topics
+	return nil
+}
+
+func (m *memoryDeviceTokenRepository) SelectDeviceTokensByUser(username string) ([]models.DeviceToken, error) {
+	var devices []models.DeviceToken
+	for token, topics := range m.topics[username] {
+		if len(topics) > 0 {
+			devices = append(devices, models.DeviceToken{Token: token, Topics: topics})
+		}
+	}
+	return devices, nil
+}
+
+func (m *memoryDeviceTokenRepository) SelectAllDeviceTokens() ([]string, error) {
+	var tokens []string
+	for _, devices := range m.topics {
+		for token := range devices {
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens, nil
+}
+
+func (m *memoryDeviceTokenRepository) DeleteDeviceTokens(tokens []string) error {
+	for _, devices := range m.topics {
+		for _, token := range tokens {
+			delete(devices, token)
+		}
+	}
+	return nil
+}
+
+func newTestFirebaseController(invalid ...string) (FirebaseControllerImpl, *fakeMessagingClient, *memoryDeviceTokenRepository) {
+	client := &fakeMessagingClient{invalid: map[string]bool{}}
+	for _, token := range invalid {
+		client.invalid[token] = true
+	}
+	repo := newMemoryDeviceTokenRepository()
+	return FirebaseControllerImpl{Client: client, Context: context.Background(), Repo: repo}, client, repo
+}
+
+func TestRegisterToTopic(t *testing.T) {
+	ctrl, client, _ := newTestFirebaseController()
+
+	if err := ctrl.RegisterToTopic("mario", "token", "modsUpdates"); err != nil {
+		t.Fatal(err)
+	}
+	devices, _ := ctrl.GetDevices("mario")
+	if len(devices) != 1 || devices[0].Token != "token" || len(devices[0].Topics) != 1 || devices[0].Topics[0] != "modsUpdates" {
+		t.Errorf("unexpected devices %+v", devices)
+	}
+	if len(client.subscribed) != 1 {
+		t.Errorf("expected 1 subscription, got %v", client.subscribed)
+	}
+}
+
+func TestRegisterToTopicValidation(t *testing.T) {
+	ctrl, client, _ := newTestFirebaseController()
+
+	if err := ctrl.RegisterToTopic("mario", "token", "not a topic"); !errors.Is(err, models.ErrValidation) {
+		t.Errorf("expected a validation error for the topic, got %v", err)
+	}
+	if err := ctrl.RegisterToTopic("mario", " ", "modsUpdates"); !errors.Is(err, models.ErrValidation) {
+		t.Errorf("expected a validation error for the token, got %v", err)
+	}
+	if len(client.subscribed) != 0 {
+		t.Errorf("invalid requests reached firebase: %v", client.subscribed)
+	}
+}
+
+func TestRegisterToTopicPrunesInvalidToken(t *testing.T) {
+	ctrl, _, repo := newTestFirebaseController("stale")
+	_ = repo.InsertDeviceTopic("mario", "stale", "official")
+
+	if err := ctrl.RegisterToTopic("mario", "stale", "modsUpdates"); !errors.Is(err, models.ErrValidation) {
+		t.Errorf("expected a validation error, got %v", err)
+	}
+	if devices, _ := ctrl.GetDevices("mario"); len(devices) != 0 {
+		t.Errorf("the invalid token wasn't pruned: %+v", devices)
+	}
+}
+
+func TestUnregisterFromTopic(t *testing.T) {
+	ctrl, client, repo := newTestFirebaseController()
+	_ = repo.InsertDeviceTopic("mario", "token", "modsUpdates")
+
+	if err := ctrl.UnregisterFromTopic("luigi", "token", "modsUpdates"); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("another user unregistered the device: %v", err)
+	}
+	if err := ctrl.UnregisterFromTopic("mario", "token", "official"); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("expected not found for a topic the device doesn't follow, got %v", err)
+	}
+	if len(client.unsubscribed) != 0 {
+		t.Errorf("unexpected unsubscriptions %v", client.unsubscribed)
+	}
+
+	if err := ctrl.UnregisterFromTopic("mario", "token", "modsUpdates"); err != nil {
+		t.Fatal(err)
+	}
+	if devices, _ := ctrl.GetDevices("mario"); len(devices) != 0 {
+		t.Errorf("unexpected devices %+v", devices)
+	}
+}
+
+func TestPruneInvalidTokens(t *testing.T) {
+	defer func(original func(error) bool) { invalidToken = original }(invalidToken)
+	invalidToken = func(err error) bool { return errors.Is(err, errUnregistered) }
+
+	ctrl, client, repo := newTestFirebaseController("stale", "expired")
+	_ = repo.InsertDeviceTopic("mario", "valid", "modsUpdates")
+	_ = repo.InsertDeviceTopic("mario", "stale", "modsUpdates")
+	_ = repo.InsertDeviceTopic("luigi", "expired", "official")
+
+	pruned, err := ctrl.PruneInvalidTokens()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pruned != 2 {
+		t.Errorf("expected 2 pruned tokens, got %v", pruned)
+	}
+	if len(client.dryRuns) != 3 {
+		t.Errorf("expected a dry run for every token, got %v", client.dryRuns)
+	}
+	tokens, _ := repo.SelectAllDeviceTokens()
+	if len(tokens) != 1 || tokens[0] != "valid" {
+		t.Errorf("unexpected tokens left %v", tokens)
+	}
+}
+
+func TestPruneInvalidTokensGoesOnAfterOtherErrors(t *testing.T) {
+	defer func(original func(error) bool) { invalidToken = original }(invalidToken)
+	invalidToken = func(err error) bool { return errors.Is(err, errUnregistered) }
+
+	ctrl, client, repo := newTestFirebaseController("stale", "expired")
+	client.unavailable = map[string]bool{"flaky": true}
+	_ = repo.InsertDeviceTopic("mario", "stale", "modsUpdates")
+	_ = repo.InsertDeviceTopic("mario", "flaky", "modsUpdates")
+	_ = repo.InsertDeviceTopic("luigi", "expired", "official")
+
+	pruned, err := ctrl.PruneInvalidTokens()
+	if err == nil || !errors.Is(err, errUnavailable) {
+		t.Errorf("expected the error of the flaky token, got %v", err)
+	}
+	if pruned != 2 {
+		t.Errorf("expected 2 pruned tokens, got %v", pruned)
+	}
+	if tokens, _ := repo.SelectAllDeviceTokens(); len(tokens) != 1 || tokens[0] != "flaky" {
+		t.Errorf("only the token that couldn't be checked should be left, got %v", tokens)
+	}
+}
+
+// crowdedCar has more topics than a single condition can hold
+func crowdedCar() models.Car {
+	return models.Car{
+		Mod:        models.Mod{Author: models.Author{Name: "Mario"}, Contributors: []models.Contributor{{Author: models.Author{Name: "Luigi"}}}},
+		ModelName:  "F40",
+		Brand:      models.CarBrand{Name: "Ferrari"},
+		Categories: []models.CarCategory{{Name: "GT"}, {Name: "Road"}},
+	}
+}
+
+func TestNotifySplitsTheTopicsInConditions(t *testing.T) {
+	ctrl, client, _ := newTestFirebaseController()
+	car := crowdedCar()
+
+	if err := ctrl.NotifyCarAdded(car); err != nil {
+		t.Fatal(err)
+	}
+	// a device following a topic of each condition gets the message twice, FCM doesn't allow more than five topics
+	// in a condition
+	if len(client.sent) != 2 {
+		t.Fatalf("expected 2 conditions for %v topics, got %v", len(helpers.CarTopics(car)), client.sent)
+	}
+	for _, topic := range helpers.CarTopics(car) {
+		found := 0
+		for _, condition := range client.sent {
+			if strings.Contains(condition, "'"+topic+"' in topics") {
+				found++
+			}
+		}
+		if found != 1 {
+			t.Errorf("topic %v is in %v conditions", topic, found)
+		}
+	}
+}
+
+func TestNotifyTargetsRetriesOnlyTheFailedConditions(t *testing.T) {
+	ctrl, client, _ := newTestFirebaseController()
+	car := crowdedCar()
+	conditions := topicConditions(helpers.CarTopics(car))
+	client.failing = map[string]bool{conditions[1]: true}
+
+	delivered, err := ctrl.NotifyTargets(models.CarAddedEvent, car, nil)
+	if !errors.Is(err, errUnavailable) {
+		t.Errorf("expected the error of the second condition, got %v", err)
+	}
+	if len(delivered) != 1 || delivered[0] != conditions[0] {
+		t.Fatalf("only the first condition should be delivered, got %v", delivered)
+	}
+
+	delivered, err = ctrl.NotifyTargets(models.CarAddedEvent, car, delivered)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(delivered) != 2 {
+		t.Errorf("expected both conditions delivered, got %v", delivered)
+	}
+	if len(client.sent) != 2 || client.sent[0] != conditions[0] || client.sent[1] != conditions[1] {
+		t.Errorf("every condition should be sent once, got %v", client.sent)
+	}
+}
@@ -0,0 +1,134 @@
+package controllers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/davide/ModRepository/controllers/helpers"
+	"github.com/davide/ModRepository/models"
+	"github.com/davide/ModRepository/repositories"
+)
+
+type ImageControllerImpl struct {
+	Storage repositories.ImageStorage
+	Repo    repositories.ImageRepository
+	Cache   *CatalogCache
+}
+
+// storeImage saves the processed upload and the given variants under folder, the original is the image url
+func (i ImageControllerImpl) storeImage(folder string, content []byte, keepAlpha bool, variants []helpers.ImageVariant) (models.Image, error) {
+	encoded, err := helpers.ProcessImage(content, keepAlpha, variants)
+	if errors.Is(err, models.ErrValidation) {
+		return models.Image{}, err
+	} else if err != nil {
+		return models.Image{}, models.NewValidationError("image", err.Error())
+	}
+
+	random := make([]byte, 12)
+	if _, err := rand.Read(random); err != nil {
+		return models.Image{}, err
+	}
+	name := hex.EncodeToString(random)
+
+	image := models.Image{Variants: map[string]string{}}
+	for _, variant := range encoded {
+		key := fmt.Sprintf("%v/%v_%v.%v", folder, name, variant.Variant, variant.Extension)
+		url, err := i.Storage.Save(key, variant.Content)
+		if err != nil {
+			i.deleteStoredImage(image)
+			return models.Image{}, err
+		}
+		if variant.Variant == "original" {
+			image.Url = url
+		} else {
+			image.Variants[variant.Variant] = url
+		}
+	}
+	return image, nil
+}
+
+func (i ImageControllerImpl) deleteStoredImage(image models.Image) {
+	if image.Url != "" {
+		_ = i.Storage.Delete(image.Url)
+	}
+	for _, url := range image.Variants {
+		_ = i.Storage.Delete(url)
+	}
+}
+
+func (i ImageControllerImpl) UploadImage(modType models.ModType, modId uint, content []byte, image models.Image) (models.Image, error) {
+	stored, err := i.storeImage(fmt.Sprintf("%vs/%v", modType, modId), content, false, helpers.ImageVariants)
+	if err != nil {
+		return models.Image{}, err
+	}
+	image.Id = 0
+	image.Url = stored.Url
+	image.Variants = stored.Variants
+	if err := i.Repo.InsertImage(modType, modId, &image); err != nil {
+		i.deleteStoredImage(stored)
+		return models.Image{}, err
+	}
+	i.Cache.Invalidate()
+	return image, nil
+}
+
+func (i ImageControllerImpl) GetImages(modType models.ModType, modId uint) ([]models.Image, error) {
+	return i.Repo.SelectImages(modType, modId)
+}
+
+func (i ImageControllerImpl) AddImage(modType models.ModType, modId uint, image models.Image) (models.Image, error) {
+	if err := helpers.ValidateImage(image); err != nil {
+		return models.Image{}, err
+	}
+	image.Id = 0
+	if err := i.Repo.InsertImage(modType, modId, &image); err != nil {
+		return models.Image{}, err
+	}
+	i.Cache.Invalidate()
+	return image, nil
+}
+
+func (i ImageControllerImpl) UpdateImage(modType models.ModType, modId uint, image models.Image) error {
+	return i.Cache.invalidateOn(i.Repo.UpdateImage(modType, modId, image))
+}
+
+func (i ImageControllerImpl) RemoveImage(modType models.ModType, modId uint, imageId uint) error {
+	image, err := i.Repo.DeleteImage(modType, modId, imageId)
+	if err != nil {
+		return err
+	}
+	i.deleteStoredImage(image)
+	i.Cache.Invalidate()
+	return nil
+}
+
+func (i ImageControllerImpl) ReorderImages(modType models.ModType, modId uint, imageIds []uint) error {
+	return i.Cache.invalidateOn(i.Repo.ReorderImages(modType, modId, imageIds))
+}
+
+// UploadSkinImage stores only the original, a skin has a single image url and no room for the variants
+func (i ImageControllerImpl) UploadSkinImage(skinId uint, content []byte) (models.Image, error) {
+	image, err := i.storeImage(fmt.Sprintf("skins/%v", skinId), content, false, nil)
+	if err != nil {
+		return models.Image{}, err
+	}
+	if err := i.Repo.UpdateSkinImage(skinId, image.Url); err != nil {
+		return models.Image{}, err
+	}
+	i.Cache.Invalidate()
+	return image, nil
+}
+
+// UploadBrandLogo stores only the original, like the skin images
+func (i ImageControllerImpl) UploadBrandLogo(name string, content []byte) (models.Image, error) {
+	image, err := i.storeImage("brands", content, true, nil)
+	if err != nil {
+		return models.Image{}, err
+	}
+	if err := i.Repo.UpdateBrandLogo(name, image.Url); err != nil {
+		return models.Image{}, err
+	}
+	i.Cache.Invalidate()
+	return image, nil
+}
@@ -0,0 +1,108 @@
+package controllers
+
+import (
+	"github.com/davide/ModRepository/controllers/helpers"
+	"github.com/davide/ModRepository/models"
+	"sync"
+	"time"
+)
+
+const (
+	liveHistorySize = 256
+	liveBufferSize  = 64
+)
+
+// LiveSubscription receives the events masked for its role, Events is closed when the subscriber is too slow
+// and has to reconnect with its cursor
+type LiveSubscription struct {
+	Events <-chan models.LiveEvent
+	events chan models.LiveEvent
+	role   models.Role
+}
+
+// LiveControllerImpl fans out the catalog changes to the connected clients, the latest events are kept so a
+// client can resume from the id of the last event it received. The events are also sent to Webhooks when set
+type LiveControllerImpl struct {
+	Webhooks    WebhookController
+	mu          sync.Mutex
+	lastId      uint64
+	history     []models.LiveEvent
+	subscribers map[*LiveSubscription]bool
+}
+
+func (l *LiveControllerImpl) Publish(eventType models.LiveEventType, payload interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.lastId++
+	event := models.LiveEvent{Id: l.lastId, Type: eventType, CreatedAt: time.Now().UTC(), Payload: payload}
+	l.history = append(l.history, event)
+	if len(l.history) > liveHistorySize {
+		l.history = l.history[len(l.history)-liveHistorySize:]
+	}
+
+	for subscription := range l.subscribers {
+		select {
+		case subscription.events <- maskLiveEvent(event, subscription.role):
+		default:
+			delete(l.subscribers, subscription)
+			close(subscription.events)
+		}
+	}
+	if l.Webhooks != nil {
+		// the partners get what a visitor sees, never the premium links
+		l.Webhooks.Dispatch(maskLiveEvent(event, models.Base))
+	}
+}
+
+// Subscribe registers a client, the events after the cursor are replayed first. A cursor of 0 starts from now,
+// one no longer in the history gets a resync event
+func (l *LiveControllerImpl) Subscribe(role models.Role, cursor uint64) *LiveSubscription {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var backlog []models.LiveEvent
+	if cursor > 0 {
+		if cursor > l.lastId || (len(l.history) > 0 && cursor < l.history[0].Id-1) {
+			backlog = append(backlog, models.LiveEvent{Id: l.lastId, Type: models.ResyncEvent, CreatedAt: time.Now().UTC()})
+		} else {
+			for _, event := range l.history {
+				if event.Id > cursor {
+					backlog = append(backlog, maskLiveEvent(event, role))
+				}
+			}
+		}
+	}
+
+	events := make(chan models.LiveEvent, liveBufferSize+len(backlog))
+	for _, event := range backlog {
+		events <- event
+	}
+	subscription := &LiveSubscription{Events: events, events: events, role: role}
+	if l.subscribers == nil {
+		l.subscribers = make(map[*LiveSubscription]bool)
+	}
+	l.subscribers[subscription] = true
+	return subscription
+}
+
+func (l *LiveControllerImpl) Unsubscribe(subscription *LiveSubscription) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.subscribers[subscription] {
+		delete(l.subscribers, subscription)
+		close(subscription.events)
+	}
+}
+
+func maskLiveEvent(event models.LiveEvent, role models.Role) models.LiveEvent {
+	switch payload := event.Payload.(type) {
+	case models.Car:
+		payload.Mod = helpers.MaskMod(payload.Mod, role)
+		event.Payload = payload
+	case models.Track:
+		payload.Mod = helpers.MaskMod(payload.Mod, role)
+		event.Payload = payload
+	}
+	return event
+}
@@ -0,0 +1,138 @@
+package controllers
+
+import (
+	"fmt"
+	"github.com/davide/ModRepository/controllers/helpers"
+	"github.com/davide/ModRepository/models"
+	"github.com/davide/ModRepository/repositories"
+	"log"
+)
+
+// ModUpdateControllerImpl applies the releases of the authors, a new version is announced like the ones
+// released by the admins
+type ModUpdateControllerImpl struct {
+	Repo      repositories.ModUpdateRepository
+	CarRepo   repositories.CarRepository
+	TrackRepo repositories.TrackRepository
+	Notifiers *NotifierRegistry
+	LiveCtrl  LiveController
+	// RequireApproval holds the updates submitted by authors until an admin approves them
+	RequireApproval bool
+	Cache           *CatalogCache
+}
+
+func (m ModUpdateControllerImpl) SubmitUpdate(update models.ModUpdate, username string, role models.Role) (models.ModUpdate, error) {
+	if err := helpers.ValidateModUpdate(update); err != nil {
+		return models.ModUpdate{}, err
+	}
+	if !helpers.IsAdmin(role) {
+		if credited, err := m.Repo.IsModCreditedTo(update.ModType, update.ModId, username); err != nil {
+			return models.ModUpdate{}, err
+		} else if !credited {
+			return models.ModUpdate{}, fmt.Errorf("%w: you are not credited for this mod", models.ErrForbidden)
+		}
+	}
+
+	update.Id = 0
+	update.SubmittedBy = username
+	update.Images = helpers.NormalizeGallery(update.Images)
+
+	if m.RequireApproval && !helpers.IsAdmin(role) {
+		update.Status = models.PendingUpdate
+	} else {
+		if err := m.apply(update); err != nil {
+			return models.ModUpdate{}, err
+		}
+		update.Status = models.ApprovedUpdate
+	}
+
+	if err := m.Repo.InsertModUpdate(&update); err != nil {
+		return models.ModUpdate{}, err
+	}
+	return update, nil
+}
+
+func (m ModUpdateControllerImpl) GetPendingUpdates() ([]models.ModUpdate, error) {
+	return m.Repo.SelectModUpdatesByStatus(models.PendingUpdate)
+}
+
+func (m ModUpdateControllerImpl) ApproveUpdate(id uint) (models.ModUpdate, error) {
+	update, err := m.Repo.SelectModUpdateById(id)
+	if err != nil {
+		return models.ModUpdate{}, err
+	}
+	if update.Status != models.PendingUpdate {
+		return models.ModUpdate{}, fmt.Errorf("%w: update is not pending", models.ErrConflict)
+	}
+	if err := m.apply(update); err != nil {
+		return models.ModUpdate{}, err
+	}
+	if err := m.Repo.UpdateModUpdateStatus(id, models.ApprovedUpdate); err != nil {
+		return models.ModUpdate{}, err
+	}
+	update.Status = models.ApprovedUpdate
+	return update, nil
+}
+
+func (m ModUpdateControllerImpl) RejectUpdate(id uint) error {
+	update, err := m.Repo.SelectModUpdateById(id)
+	if err != nil {
+		return err
+	}
+	if update.Status != models.PendingUpdate {
+		return fmt.Errorf("%w: update is not pending", models.ErrConflict)
+	}
+	return m.Repo.UpdateModUpdateStatus(id, models.RejectedUpdate)
+}
+
+// apply writes the update with the outbox notifications of a new version, the live clients and the webhooks
+// get the mod as stored once the transaction is committed
+func (m ModUpdateControllerImpl) apply(update models.ModUpdate) error {
+	// the pending updates stored before the gallery rules are normalized when approved
+	update.Images = helpers.NormalizeGallery(update.Images)
+
+	var event models.LiveEventType
+	var mod models.Mod
+	switch update.ModType {
+	case models.CarModType:
+		car, err := m.CarRepo.SelectCarById(update.ModId, true, true)
+		if err != nil {
+			return err
+		}
+		event, mod = models.CarUpdatedEvent, car.Mod
+	case models.TrackModType:
+		track, err := m.TrackRepo.SelectTrackById(update.ModId, true, true)
+		if err != nil {
+			return err
+		}
+		event, mod = models.TrackUpdatedEvent, track.Mod
+	default:
+		return models.NewValidationError("modType", "unknown mod type")
+	}
+
+	var notifications []models.Notification
+	if update.Version != "" && update.Version != mod.Version {
+		notifications = outboxNotifications(m.Notifiers, event, mod)
+	}
+	if err := m.Repo.ApplyModUpdate(update, notifications...); err != nil {
+		return err
+	}
+	m.Cache.Invalidate()
+
+	if m.LiveCtrl == nil {
+		return nil
+	}
+	var payload interface{}
+	var err error
+	if update.ModType == models.CarModType {
+		payload, err = m.CarRepo.SelectCarById(update.ModId, true, true)
+	} else {
+		payload, err = m.TrackRepo.SelectTrackById(update.ModId, true, true)
+	}
+	if err != nil {
+		log.Printf("error loading the %v %v to publish its update: %v", update.ModType, update.ModId, err)
+		return nil
+	}
+	m.LiveCtrl.Publish(event, payload)
+	return nil
+}
@@ -1,18 +1,36 @@
 package controllers
 
 import (
+	"github.com/davide/ModRepository/controllers/helpers"
 	"github.com/davide/ModRepository/models"
 	"github.com/davide/ModRepository/repositories"
 )
 
 type NationControllerImpl struct {
-	Repo repositories.NationRepository
+	Repo  repositories.NationRepository
+	Cache *CatalogCache
 }
 
-func (n NationControllerImpl) GetAllTracksNations() ([]models.Nation, error) {
-	return n.Repo.SelectAllTrackNations()
+func (n NationControllerImpl) GetAllNations(continent models.Continent) ([]models.Nation, error) {
+	return n.Repo.SelectAllNations(continent)
 }
 
-func (n NationControllerImpl) GetAllBrandsNations() ([]models.Nation, error) {
-	return n.Repo.SelectAllBrandsNations()
+func (n NationControllerImpl) GetAllTracksNations(continent models.Continent) ([]models.Nation, error) {
+	return n.Repo.SelectAllTrackNations(continent)
+}
+
+func (n NationControllerImpl) GetAllBrandsNations(continent models.Continent) ([]models.Nation, error) {
+	return n.Repo.SelectAllBrandsNations(continent)
+}
+
+func (n NationControllerImpl) GetAllContinents() []models.Continent {
+	return models.Continents
+}
+
+func (n NationControllerImpl) SeedNations() error {
+	return n.Cache.invalidateOn(n.Repo.SeedNations(helpers.IsoNations()))
+}
+
+func (n NationControllerImpl) MergeNations(source string, target string) error {
+	return n.Cache.invalidateOn(n.Repo.MergeNations(source, target))
 }
@@ -0,0 +1,151 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/davide/ModRepository/models"
+	"github.com/davide/ModRepository/repositories"
+	"log"
+	"time"
+)
+
+const (
+	defaultNotificationAttempts  = 8
+	defaultNotificationBaseDelay = 30 * time.Second
+	notificationBatchSize        = 20
+	maxNotificationErrorLength   = 255
+)
+
+// NotificationControllerImpl delivers the outbox written by the mod repositories. A failed notification is
+// retried with an exponential backoff starting from BaseDelay, after MaxAttempts it's dead until an admin replays it
+type NotificationControllerImpl struct {
+	Repo        repositories.NotificationRepository
+	Notifiers   *NotifierRegistry
+	MaxAttempts int
+	BaseDelay   time.Duration
+}
+
+// outboxNotifications are the announcements of a mod change for the repositories to write with it, one for every
+// channel routed by the registry
+func outboxNotifications(notifiers *NotifierRegistry, event models.LiveEventType, mod models.Mod) []models.Notification {
+	var notifications []models.Notification
+	for _, channel := range notifiers.Channels(event, mod) {
+		notifications = append(notifications, models.Notification{Channel: channel, Event: event})
+	}
+	return notifications
+}
+
+// Run delivers the due notifications every interval until ctx is done, the outbox left by a restart is sent
+// on the first tick
+func (n NotificationControllerImpl) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := n.DeliverDue(); err != nil {
+			log.Printf("error delivering the notifications: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (n NotificationControllerImpl) DeliverDue() error {
+	notifications, err := n.Repo.SelectDueNotifications(time.Now(), notificationBatchSize)
+	if err != nil {
+		return err
+	}
+	for _, notification := range notifications {
+		notification = n.attempt(notification)
+		if err := n.Repo.UpdateNotification(notification); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (n NotificationControllerImpl) attempt(notification models.Notification) models.Notification {
+	notification.Attempts++
+	err := n.send(&notification)
+	if err == nil {
+		now := time.Now()
+		notification.Status = models.DeliveredNotification
+		notification.DeliveredAt = &now
+		notification.LastError = ""
+		return notification
+	}
+
+	notification.LastError = err.Error()
+	if len(notification.LastError) > maxNotificationErrorLength {
+		notification.LastError = notification.LastError[:maxNotificationErrorLength]
+	}
+	maxAttempts := n.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultNotificationAttempts
+	}
+	if notification.Attempts >= maxAttempts {
+		notification.Status = models.DeadNotification
+		log.Printf("notification %v to %v is dead after %v attempts: %v", notification.Id, notification.Channel, notification.Attempts, err)
+		return notification
+	}
+	delay := n.BaseDelay
+	if delay <= 0 {
+		delay = defaultNotificationBaseDelay
+	}
+	notification.NextAttemptAt = time.Now().Add(delay << uint(notification.Attempts-1))
+	return notification
+}
+
+// send delivers the notification, the targets reached by a TargetedNotifier are recorded on it
+func (n NotificationControllerImpl) send(notification *models.Notification) error {
+	notifier, ok := n.Notifiers.Notifier(notification.Channel)
+	if !ok {
+		return fmt.Errorf("no notifier registered for %v", notification.Channel)
+	}
+	var payload interface{}
+	switch notification.ModType {
+	case models.CarModType:
+		var car models.Car
+		if err := json.Unmarshal(notification.Payload, &car); err != nil {
+			return err
+		}
+		payload = car
+	case models.TrackModType:
+		var track models.Track
+		if err := json.Unmarshal(notification.Payload, &track); err != nil {
+			return err
+		}
+		payload = track
+	}
+	if targeted, ok := notifier.(TargetedNotifier); ok {
+		delivered, err := targeted.NotifyTargets(notification.Event, payload, notification.Delivered)
+		notification.Delivered = delivered
+		return err
+	}
+	return notify(notifier, notification.Event, payload)
+}
+
+func (n NotificationControllerImpl) GetNotifications(status models.NotificationStatus, limit int) ([]models.Notification, error) {
+	return n.Repo.SelectNotificationsByStatus(status, limit)
+}
+
+// ReplayNotification puts a dead notification back in the outbox with its attempts reset
+func (n NotificationControllerImpl) ReplayNotification(id uint) (models.Notification, error) {
+	notification, err := n.Repo.SelectNotificationById(id)
+	if err != nil {
+		return models.Notification{}, err
+	}
+	if notification.Status != models.DeadNotification {
+		return models.Notification{}, fmt.Errorf("%w: only dead notifications can be replayed", models.ErrConflict)
+	}
+	notification.Status = models.PendingNotification
+	notification.Attempts = 0
+	notification.NextAttemptAt = time.Now()
+	if err := n.Repo.UpdateNotification(notification); err != nil {
+		return models.Notification{}, err
+	}
+	return notification, nil
+}
@@ -0,0 +1,70 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/davide/ModRepository/models"
+)
+
+// flakyTargetNotifier announces to targets, the ones in failing fail once and then succeed
+type flakyTargetNotifier struct {
+	Notifier
+	targets []string
+	failing map[string]bool
+	sent    []string
+}
+
+func (f *flakyTargetNotifier) NotifyTargets(_ models.LiveEventType, _ interface{}, delivered []string) ([]string, error) {
+	var failed error
+	for _, target := range f.targets {
+		if containsString(delivered, target) {
+			continue
+		}
+		if f.failing[target] {
+			f.failing[target] = false
+			failed = errors.New("unavailable")
+			continue
+		}
+		f.sent = append(f.sent, target)
+		delivered = append(delivered, target)
+	}
+	return delivered, failed
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func TestNotificationRetrySkipsDeliveredTargets(t *testing.T) {
+	notifier := &flakyTargetNotifier{targets: []string{"general", "releases", "gt"}, failing: map[string]bool{"releases": true}}
+	registry := &NotifierRegistry{}
+	registry.Register(models.DiscordChannel, notifier)
+	ctrl := NotificationControllerImpl{Notifiers: registry, BaseDelay: time.Millisecond}
+
+	payload, _ := json.Marshal(models.Car{ModelName: "F40"})
+	notification := models.Notification{Channel: models.DiscordChannel, Event: models.CarAddedEvent, ModType: models.CarModType, Payload: payload, Status: models.PendingNotification}
+
+	notification = ctrl.attempt(notification)
+	if notification.Status != models.PendingNotification || notification.LastError == "" {
+		t.Fatalf("the first attempt should fail, got %+v", notification)
+	}
+	if len(notification.Delivered) != 2 {
+		t.Errorf("expected 2 delivered targets, got %v", notification.Delivered)
+	}
+
+	notification = ctrl.attempt(notification)
+	if notification.Status != models.DeliveredNotification {
+		t.Fatalf("the retry should deliver, got %+v", notification)
+	}
+	if len(notifier.sent) != 3 {
+		t.Errorf("every target should be announced once, got %v", notifier.sent)
+	}
+}
@@ -0,0 +1,120 @@
+package controllers
+
+import (
+	"fmt"
+	"github.com/davide/ModRepository/models"
+	"log"
+)
+
+// NotifierRegistry holds the notifiers configured at startup and routes every mod change to the enabled
+// channels whose rules match it
+type NotifierRegistry struct {
+	Rules     []models.NotifierRule
+	notifiers map[models.NotificationChannel]Notifier
+}
+
+func (r *NotifierRegistry) Register(channel models.NotificationChannel, notifier Notifier) {
+	if r.notifiers == nil {
+		r.notifiers = make(map[models.NotificationChannel]Notifier)
+	}
+	r.notifiers[channel] = notifier
+}
+
+func (r *NotifierRegistry) Notifier(channel models.NotificationChannel) (Notifier, bool) {
+	if r == nil {
+		return nil, false
+	}
+	notifier, ok := r.notifiers[channel]
+	return notifier, ok
+}
+
+// Channels are the enabled channels announcing the event of mod, it's safe to call on a nil registry
+func (r *NotifierRegistry) Channels(event models.LiveEventType, mod models.Mod) []models.NotificationChannel {
+	if r == nil {
+		return nil
+	}
+	var channels []models.NotificationChannel
+	for _, rule := range r.Rules {
+		if _, ok := r.notifiers[rule.Channel]; ok && rule.Enabled && ruleMatches(rule, event, mod) && !containsChannel(channels, rule.Channel) {
+			channels = append(channels, rule.Channel)
+		}
+	}
+	return channels
+}
+
+func ruleMatches(rule models.NotifierRule, event models.LiveEventType, mod models.Mod) bool {
+	if rule.Official != nil && *rule.Official != mod.Official {
+		return false
+	}
+	if len(rule.Events) == 0 {
+		return true
+	}
+	for _, e := range rule.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+func containsChannel(channels []models.NotificationChannel, channel models.NotificationChannel) bool {
+	for _, c := range channels {
+		if c == channel {
+			return true
+		}
+	}
+	return false
+}
+
+// TargetedNotifier is a notifier announcing to several targets of its channel, like the Discord channels or the
+// Firebase topic conditions. It skips the delivered targets and returns them with the ones reached now even when it
+// fails, so a retry doesn't announce twice to the targets that already got it
+type TargetedNotifier interface {
+	NotifyTargets(event models.LiveEventType, payload interface{}, delivered []string) ([]string, error)
+}
+
+// notify calls the method of the notifier for the event, payload is the car or the track
+func notify(notifier Notifier, event models.LiveEventType, payload interface{}) error {
+	switch mod := payload.(type) {
+	case models.Car:
+		switch event {
+		case models.CarAddedEvent:
+			return notifier.NotifyCarAdded(mod)
+		case models.CarUpdatedEvent:
+			return notifier.NotifyCarUpdated(mod)
+		}
+	case models.Track:
+		switch event {
+		case models.TrackAddedEvent:
+			return notifier.NotifyTrackAdded(mod)
+		case models.TrackUpdatedEvent:
+			return notifier.NotifyTrackUpdated(mod)
+		}
+	}
+	return fmt.Errorf("%v is not a notification for %T", event, payload)
+}
+
+// NoopNotifier logs the announcements instead of sending them, for local development
+type NoopNotifier struct {
+	Channel models.NotificationChannel
+}
+
+func (n NoopNotifier) NotifyCarAdded(car models.Car) error {
+	log.Printf("[%v] car added: %v %v", n.Channel, car.Brand.Name, car.ModelName)
+	return nil
+}
+
+func (n NoopNotifier) NotifyCarUpdated(car models.Car) error {
+	log.Printf("[%v] car updated: %v %v %v", n.Channel, car.Brand.Name, car.ModelName, car.Version)
+	return nil
+}
+
+func (n NoopNotifier) NotifyTrackUpdated(track models.Track) error {
+	log.Printf("[%v] track updated: %v %v", n.Channel, track.Name, track.Version)
+	return nil
+}
+
+func (n NoopNotifier) NotifyTrackAdded(track models.Track) error {
+	log.Printf("[%v] track added: %v", n.Channel, track.Name)
+	return nil
+}
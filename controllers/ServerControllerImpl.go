@@ -1,20 +1,32 @@
 package controllers
 
 import (
+	"github.com/davide/ModRepository/controllers/helpers"
 	"github.com/davide/ModRepository/models"
 	"github.com/davide/ModRepository/repositories"
 )
 
 type ServersControllerImpl struct {
-	Repo repositories.ServersRepository
+	Repo  repositories.ServersRepository
+	Cache *CatalogCache
 }
 
-func (s ServersControllerImpl) AddServer(server models.Server) error {
-	return s.Repo.AddServer(server)
+func (s ServersControllerImpl) AddServer(server *models.Server) error {
+	if err := helpers.ValidateServer(*server); err != nil {
+		return err
+	}
+	return s.Cache.invalidateOn(s.Repo.AddServer(server))
 }
 
 func (s ServersControllerImpl) UpdateServer(server models.Server) error {
-	return s.Repo.UpdateServer(server)
+	if err := helpers.ValidateServer(server); err != nil {
+		return err
+	}
+	return s.Cache.invalidateOn(s.Repo.UpdateServer(server))
+}
+
+func (s ServersControllerImpl) GetServer(id uint) (models.Server, error) {
+	return s.Repo.GetServerById(id)
 }
 
 func (s ServersControllerImpl) GetAllServers() ([]models.Server, error) {
@@ -22,5 +34,5 @@ func (s ServersControllerImpl) GetAllServers() ([]models.Server, error) {
 }
 
 func (s ServersControllerImpl) DeleteServer(server models.Server) error {
-	return s.Repo.DeleteServer(server)
+	return s.Cache.invalidateOn(s.Repo.DeleteServer(server))
 }
@@ -1,12 +1,14 @@
 package controllers
 
 import (
+	"github.com/davide/ModRepository/controllers/helpers"
 	"github.com/davide/ModRepository/models"
 	"github.com/davide/ModRepository/repositories"
 )
 
 type SkinControllerImpl struct {
-	Repo repositories.SkinRepository
+	Repo  repositories.SkinRepository
+	Cache *CatalogCache
 }
 
 func (s SkinControllerImpl) SelectCarSkins(carId uint) ([]models.Skin, error) {
@@ -17,10 +19,24 @@ func (s SkinControllerImpl) GetAllSkins() ([]models.Skin, error) {
 	return s.Repo.GetAllSkins()
 }
 
-func (s SkinControllerImpl) AddSkin(skin models.Skin) error {
-	return s.Repo.AddSkin(skin)
+func (s SkinControllerImpl) GetSkin(id uint) (models.Skin, error) {
+	return s.Repo.SelectSkinById(id)
+}
+
+func (s SkinControllerImpl) AddSkin(skin *models.Skin) error {
+	if err := helpers.ValidateSkin(*skin); err != nil {
+		return err
+	}
+	return s.Cache.invalidateOn(s.Repo.AddSkin(skin))
 }
 
 func (s SkinControllerImpl) UpdateSkin(skin models.Skin) error {
-	return s.Repo.UpdateSkin(skin)
+	if err := helpers.ValidateSkin(skin); err != nil {
+		return err
+	}
+	return s.Cache.invalidateOn(s.Repo.UpdateSkin(skin))
+}
+
+func (s SkinControllerImpl) DeleteSkin(id uint) error {
+	return s.Cache.invalidateOn(s.Repo.DeleteSkin(id))
 }
@@ -7,7 +7,10 @@ import (
 )
 
 type TrackControllerImpl struct {
-	Repo repositories.TrackRepository
+	Repo  repositories.TrackRepository
+	Cache *CatalogCache
+	// Notifiers route the announcements written to the outbox with the track
+	Notifiers *NotifierRegistry
 }
 
 func (t TrackControllerImpl) GetAllTracks(role models.Role) ([]models.Track, error) {
@@ -15,9 +18,74 @@ func (t TrackControllerImpl) GetAllTracks(role models.Role) ([]models.Track, err
 }
 
 func (t TrackControllerImpl) AddTrack(track *models.Track) error {
-	return t.Repo.InsertTrack(track)
+	if err := helpers.ValidateTrack(*track); err != nil {
+		return err
+	}
+	nation, err := helpers.NormalizeNation(track.Nation)
+	if err != nil {
+		return err
+	}
+	track.Nation = nation
+	track.Images = helpers.NormalizeGallery(track.Images)
+
+	return t.Cache.invalidateOn(t.Repo.InsertTrack(track, outboxNotifications(t.Notifiers, models.TrackAddedEvent, track.Mod)...))
 }
 
 func (t TrackControllerImpl) UpdateTrack(track models.Track) (bool, error) {
-	return t.Repo.UpdateTrack(track)
+	if err := helpers.ValidateTrack(track); err != nil {
+		return false, err
+	}
+	nation, err := helpers.NormalizeNation(track.Nation)
+	if err != nil {
+		return false, err
+	}
+	track.Nation = nation
+	track.Images = helpers.NormalizeGallery(track.Images)
+
+	versionChange, err := t.Repo.UpdateTrack(track, outboxNotifications(t.Notifiers, models.TrackUpdatedEvent, track.Mod)...)
+	if err != nil {
+		return false, err
+	}
+	t.Cache.Invalidate()
+	return versionChange, nil
+}
+
+func (t TrackControllerImpl) GetTrack(id uint, role models.Role) (models.Track, error) {
+	return t.Repo.SelectTrackById(id, helpers.IsPremium(role), helpers.IsAdmin(role))
+}
+
+func (t TrackControllerImpl) GetTracksByIds(ids []uint, role models.Role) ([]models.Track, error) {
+	return t.Repo.SelectTracksByIds(ids, helpers.IsPremium(role), helpers.IsAdmin(role))
+}
+
+// SearchTracks returns at most limit tracks whose name, year and location contain every word of the query
+func (t TrackControllerImpl) SearchTracks(query string, role models.Role, limit int) ([]models.Track, error) {
+	return t.Repo.SearchTracks(query, limit, helpers.IsPremium(role), helpers.IsAdmin(role))
+}
+
+func (t TrackControllerImpl) DeleteTrack(id uint) error {
+	return t.Cache.invalidateOn(t.Repo.DeleteTrack(id))
+}
+
+func (t TrackControllerImpl) GetRelatedTracks(trackId uint, role models.Role, limit int) (models.RelatedTracks, error) {
+	premium, admin := helpers.IsPremium(role), helpers.IsAdmin(role)
+
+	track, err := t.Repo.SelectTrackById(trackId, premium, admin)
+	if err != nil {
+		return models.RelatedTracks{}, err
+	}
+
+	var related models.RelatedTracks
+
+	if related.SameAuthor, err = t.Repo.SelectTracksByAuthor(track.Author.Name, track.Id, limit, premium, admin); err != nil {
+		return models.RelatedTracks{}, err
+	}
+	if related.SameNation, err = t.Repo.SelectTracksByNation(track.Nation.Name, track.Id, limit, premium, admin); err != nil {
+		return models.RelatedTracks{}, err
+	}
+	if related.SimilarTags, err = t.Repo.SelectTracksByTags(track.Tags, track.Id, limit, premium, admin); err != nil {
+		return models.RelatedTracks{}, err
+	}
+
+	return related, nil
 }
@@ -13,11 +13,12 @@ func (u UserControllerImpl) Login(username string, password string) (models.User
 	return u.Repo.Login(models.User{Username: username, Password: password})
 }
 
-func (u UserControllerImpl) SignIn(username string, password string, role models.Role) (models.User, error) {
+func (u UserControllerImpl) SignIn(username string, password string, role models.Role, author string) (models.User, error) {
 	return u.Repo.SignIn(models.User{
 		Username: username,
 		Password: password,
 		Role:     role,
+		Author:   author,
 	})
 }
 
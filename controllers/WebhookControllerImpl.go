@@ -0,0 +1,218 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/davide/ModRepository/controllers/helpers"
+	"github.com/davide/ModRepository/models"
+	"github.com/davide/ModRepository/repositories"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultWebhookAttempts  = 5
+	defaultWebhookBaseDelay = 2 * time.Second
+	webhookTimeout          = 10 * time.Second
+	maxWebhookErrorLength   = 255
+)
+
+// webhookEvents maps the live events to the events the endpoints subscribe to, the server events are all
+// server.updated and the resync is only for the live clients
+var webhookEvents = map[models.LiveEventType]models.WebhookEventType{
+	models.CarAddedEvent:            models.CarAddedWebhook,
+	models.CarUpdatedEvent:          models.CarUpdatedWebhook,
+	models.TrackAddedEvent:          models.TrackAddedWebhook,
+	models.TrackUpdatedEvent:        models.TrackUpdatedWebhook,
+	models.SkinAddedEvent:           models.SkinAddedWebhook,
+	models.ServerOnlineEvent:        models.ServerUpdatedWebhook,
+	models.ServerOfflineEvent:       models.ServerUpdatedWebhook,
+	models.ServerLineupChangedEvent: models.ServerUpdatedWebhook,
+}
+
+// WebhookControllerImpl delivers the catalog events to the endpoints registered by the admins. Every attempt
+// is logged, failed ones are retried with an exponential backoff starting from BaseDelay
+type WebhookControllerImpl struct {
+	Repo        repositories.WebhookRepository
+	Client      *http.Client
+	MaxAttempts int
+	BaseDelay   time.Duration
+}
+
+func (w WebhookControllerImpl) GetWebhooks() ([]models.WebhookEndpoint, error) {
+	endpoints, err := w.Repo.SelectAllWebhooks()
+	if err != nil {
+		return nil, err
+	}
+	for i := range endpoints {
+		endpoints[i].Secret = ""
+	}
+	return endpoints, nil
+}
+
+func (w WebhookControllerImpl) GetWebhook(id uint) (models.WebhookEndpoint, error) {
+	endpoint, err := w.Repo.SelectWebhookById(id)
+	endpoint.Secret = ""
+	return endpoint, err
+}
+
+// AddWebhook registers an endpoint, a secret is generated when none is given. The secret is only returned here
+func (w WebhookControllerImpl) AddWebhook(endpoint models.WebhookEndpoint) (models.WebhookEndpoint, error) {
+	if err := helpers.ValidateWebhook(endpoint); err != nil {
+		return models.WebhookEndpoint{}, err
+	}
+	if endpoint.Secret == "" {
+		secret, err := helpers.NewWebhookSecret()
+		if err != nil {
+			return models.WebhookEndpoint{}, err
+		}
+		endpoint.Secret = secret
+	}
+	if err := w.Repo.InsertWebhook(&endpoint); err != nil {
+		return models.WebhookEndpoint{}, err
+	}
+	return endpoint, nil
+}
+
+func (w WebhookControllerImpl) UpdateWebhook(endpoint models.WebhookEndpoint) (models.WebhookEndpoint, error) {
+	if err := helpers.ValidateWebhook(endpoint); err != nil {
+		return models.WebhookEndpoint{}, err
+	}
+	if err := w.Repo.UpdateWebhook(endpoint); err != nil {
+		return models.WebhookEndpoint{}, err
+	}
+	return w.GetWebhook(endpoint.Id)
+}
+
+func (w WebhookControllerImpl) DeleteWebhook(id uint) error {
+	return w.Repo.DeleteWebhook(id)
+}
+
+func (w WebhookControllerImpl) GetDeliveries(id uint, limit int) ([]models.WebhookDelivery, error) {
+	if _, err := w.Repo.SelectWebhookById(id); err != nil {
+		return nil, err
+	}
+	return w.Repo.SelectWebhookDeliveries(id, limit)
+}
+
+// Dispatch sends the event to the subscribed endpoints in the background, it never blocks the caller
+func (w WebhookControllerImpl) Dispatch(event models.LiveEvent) {
+	eventType, ok := webhookEvents[event.Type]
+	if !ok {
+		return
+	}
+	go func() {
+		endpoints, err := w.Repo.SelectAllWebhooks()
+		if err != nil {
+			log.Printf("error loading the webhooks for event %v: %v", event.Id, err)
+			return
+		}
+		id, err := helpers.NewWebhookEventId()
+		if err != nil {
+			log.Printf("error generating the webhook id for event %v: %v", event.Id, err)
+			return
+		}
+		payload := models.WebhookPayload{Id: id, Type: eventType, CreatedAt: event.CreatedAt, Data: event.Payload}
+		body, err := json.Marshal(payload)
+		if err != nil {
+			log.Printf("error encoding the webhook payload for event %v: %v", event.Id, err)
+			return
+		}
+		for _, endpoint := range endpoints {
+			if endpoint.Active && subscribedTo(endpoint, eventType) {
+				go w.deliver(endpoint, payload, body)
+			}
+		}
+	}()
+}
+
+func subscribedTo(endpoint models.WebhookEndpoint, eventType models.WebhookEventType) bool {
+	for _, event := range endpoint.Events {
+		if event == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+func (w WebhookControllerImpl) deliver(endpoint models.WebhookEndpoint, payload models.WebhookPayload, body []byte) {
+	maxAttempts := w.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultWebhookAttempts
+	}
+	delay := w.BaseDelay
+	if delay <= 0 {
+		delay = defaultWebhookBaseDelay
+	}
+
+	for attempt := 1; ; attempt++ {
+		delivery, retry := w.attempt(endpoint, payload, body)
+		delivery.Attempt = attempt
+		if err := w.Repo.InsertWebhookDelivery(&delivery); err != nil {
+			log.Printf("error logging the delivery of event %v to webhook %v: %v", payload.Id, endpoint.Id, err)
+		}
+		if delivery.Succeeded {
+			return
+		}
+		if !retry || attempt >= maxAttempts {
+			log.Printf("giving up the delivery of event %v to webhook %v after %v attempts", payload.Id, endpoint.Id, attempt)
+			return
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// attempt posts the payload once, retry is false when the endpoint rejected it and sending it again won't help
+func (w WebhookControllerImpl) attempt(endpoint models.WebhookEndpoint, payload models.WebhookPayload, body []byte) (models.WebhookDelivery, bool) {
+	delivery := models.WebhookDelivery{EndpointId: endpoint.Id, EventId: payload.Id, EventType: payload.Type}
+	client := w.Client
+	if client == nil {
+		client = &http.Client{Timeout: webhookTimeout}
+	}
+
+	request, err := http.NewRequest(http.MethodPost, endpoint.Url, bytes.NewReader(body))
+	if err != nil {
+		delivery.Error = truncateError(err)
+		return delivery, false
+	}
+	timestamp := time.Now().Unix()
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "ACModRepository-Webhooks")
+	request.Header.Set(helpers.WebhookEventHeader, string(payload.Type))
+	request.Header.Set(helpers.WebhookDeliveryHeader, payload.Id)
+	request.Header.Set(helpers.WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	request.Header.Set(helpers.WebhookSignatureHeader, helpers.SignWebhook(endpoint.Secret, timestamp, body))
+
+	start := time.Now()
+	response, err := client.Do(request)
+	delivery.DurationMs = time.Since(start).Milliseconds()
+	if err != nil {
+		delivery.Error = truncateError(err)
+		return delivery, true
+	}
+	_, _ = io.Copy(ioutil.Discard, io.LimitReader(response.Body, 64<<10))
+	response.Body.Close()
+
+	delivery.StatusCode = response.StatusCode
+	if response.StatusCode >= 200 && response.StatusCode < 300 {
+		delivery.Succeeded = true
+		return delivery, false
+	}
+	delivery.Error = truncateError(fmt.Errorf("unexpected status %v", response.Status))
+	retry := response.StatusCode >= 500 || response.StatusCode == http.StatusRequestTimeout || response.StatusCode == http.StatusTooManyRequests
+	return delivery, retry
+}
+
+func truncateError(err error) string {
+	message := err.Error()
+	if len(message) > maxWebhookErrorLength {
+		return message[:maxWebhookErrorLength]
+	}
+	return message
+}
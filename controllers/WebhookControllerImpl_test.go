@@ -0,0 +1,240 @@
+package controllers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/davide/ModRepository/controllers/helpers"
+	"github.com/davide/ModRepository/models"
+)
+
+// memoryWebhookRepository keeps the endpoints and the delivery log in memory, every logged delivery is also
+// sent on logged so the tests can wait for the background deliveries
+type memoryWebhookRepository struct {
+	mu         sync.Mutex
+	endpoints  []models.WebhookEndpoint
+	deliveries []models.WebhookDelivery
+	logged     chan models.WebhookDelivery
+}
+
+func newMemoryWebhookRepository(endpoints ...models.WebhookEndpoint) *memoryWebhookRepository {
+	return &memoryWebhookRepository{endpoints: endpoints, logged: make(chan models.WebhookDelivery, 16)}
+}
+
+func (m *memoryWebhookRepository) SelectAllWebhooks() ([]models.WebhookEndpoint, error) {
+	return m.endpoints, nil
+}
+
+func (m *memoryWebhookRepository) SelectWebhookById(id uint) (models.WebhookEndpoint, error) {
+	for _, endpoint := range m.endpoints {
+		if endpoint.Id == id {
+			return endpoint, nil
+		}
+	}
+	return models.WebhookEndpoint{}, models.ErrNotFound
+}
+
+func (m *memoryWebhookRepository) InsertWebhook(endpoint *models.WebhookEndpoint) error {
+	endpoint.Id = uint(len(m.endpoints) + 1)
+	m.endpoints = append(m.endpoints, *endpoint)
+	return nil
+}
+
+func (m *memoryWebhookRepository) UpdateWebhook(models.WebhookEndpoint) error { return nil }
+
+func (m *memoryWebhookRepository) DeleteWebhook(uint) error { return nil }
+
+func (m *memoryWebhookRepository) InsertWebhookDelivery(delivery *models.WebhookDelivery) error {
+	m.mu.Lock()
+	delivery.Id = uint(len(m.deliveries) + 1)
+	m.deliveries = append(m.deliveries, *delivery)
+	m.mu.Unlock()
+	m.logged <- *delivery
+	return nil
+}
+
+func (m *memoryWebhookRepository) SelectWebhookDeliveries(endpointId uint, _ int) ([]models.WebhookDelivery, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var deliveries []models.WebhookDelivery
+	for _, delivery := range m.deliveries {
+		if delivery.EndpointId == endpointId {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	return deliveries, nil
+}
+
+func (m *memoryWebhookRepository) waitDelivery(t *testing.T) models.WebhookDelivery {
+	t.Helper()
+	select {
+	case delivery := <-m.logged:
+		return delivery
+	case <-time.After(5 * time.Second):
+		t.Fatal("no delivery logged")
+		return models.WebhookDelivery{}
+	}
+}
+
+// receiver answers with statuses in order, the last one is repeated
+func receiver(t *testing.T, statuses ...int) (*httptest.Server, chan *http.Request, chan []byte) {
+	requests, bodies := make(chan *http.Request, 16), make(chan []byte, 16)
+	var mu sync.Mutex
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("error reading the delivery: %v", err)
+		}
+		mu.Lock()
+		status := statuses[len(statuses)-1]
+		if calls < len(statuses) {
+			status = statuses[calls]
+		}
+		calls++
+		mu.Unlock()
+		requests <- r
+		bodies <- body
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server, requests, bodies
+}
+
+func testEndpoint(url string) models.WebhookEndpoint {
+	return models.WebhookEndpoint{
+		Id:     1,
+		Url:    url,
+		Events: []models.WebhookEventType{models.CarAddedWebhook},
+		Active: true,
+		Secret: "whsec_test",
+	}
+}
+
+func TestWebhookSignature(t *testing.T) {
+	server, requests, bodies := receiver(t, http.StatusNoContent)
+	repo := newMemoryWebhookRepository(testEndpoint(server.URL))
+	ctrl := WebhookControllerImpl{Repo: repo, BaseDelay: time.Millisecond}
+
+	ctrl.Dispatch(models.LiveEvent{Id: 1, Type: models.CarAddedEvent, CreatedAt: time.Now(), Payload: models.Car{ModelName: "F40"}})
+	request, body := <-requests, <-bodies
+	repo.waitDelivery(t)
+
+	mac := hmac.New(sha256.New, []byte("whsec_test"))
+	mac.Write([]byte(request.Header.Get(helpers.WebhookTimestampHeader) + "."))
+	mac.Write(body)
+	if signature := "sha256=" + hex.EncodeToString(mac.Sum(nil)); request.Header.Get(helpers.WebhookSignatureHeader) != signature {
+		t.Errorf("signature %v, expected %v", request.Header.Get(helpers.WebhookSignatureHeader), signature)
+	}
+	if event := request.Header.Get(helpers.WebhookEventHeader); event != string(models.CarAddedWebhook) {
+		t.Errorf("event header %v", event)
+	}
+
+	var payload models.WebhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatal(err)
+	}
+	if len(payload.Id) != 36 || request.Header.Get(helpers.WebhookDeliveryHeader) != payload.Id {
+		t.Errorf("payload id %q, delivery header %q", payload.Id, request.Header.Get(helpers.WebhookDeliveryHeader))
+	}
+}
+
+func TestWebhookIdsAreUnique(t *testing.T) {
+	server, _, bodies := receiver(t, http.StatusNoContent)
+	repo := newMemoryWebhookRepository(testEndpoint(server.URL))
+	ctrl := WebhookControllerImpl{Repo: repo}
+
+	ids := map[string]bool{}
+	for i := 0; i < 3; i++ {
+		// a restart starts the live ids from 1 again
+		ctrl.Dispatch(models.LiveEvent{Id: 1, Type: models.CarAddedEvent, Payload: models.Car{}})
+		var payload models.WebhookPayload
+		if err := json.Unmarshal(<-bodies, &payload); err != nil {
+			t.Fatal(err)
+		}
+		repo.waitDelivery(t)
+		ids[payload.Id] = true
+	}
+	if len(ids) != 3 {
+		t.Errorf("expected 3 distinct ids, got %v", ids)
+	}
+}
+
+func TestWebhookRetriesServerErrors(t *testing.T) {
+	server, requests, _ := receiver(t, http.StatusServiceUnavailable, http.StatusNoContent)
+	repo := newMemoryWebhookRepository()
+	ctrl := WebhookControllerImpl{Repo: repo, BaseDelay: 50 * time.Millisecond}
+
+	start := time.Now()
+	ctrl.deliver(testEndpoint(server.URL), models.WebhookPayload{Id: "id", Type: models.CarAddedWebhook}, []byte("{}"))
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("retried after %v, before the backoff", elapsed)
+	}
+	if len(requests) != 2 {
+		t.Errorf("expected 2 requests, got %v", len(requests))
+	}
+
+	deliveries, _ := repo.SelectWebhookDeliveries(1, 10)
+	if len(deliveries) != 2 {
+		t.Fatalf("expected 2 logged deliveries, got %v", len(deliveries))
+	}
+	if first := deliveries[0]; first.Attempt != 1 || first.Succeeded || first.StatusCode != http.StatusServiceUnavailable || first.Error == "" {
+		t.Errorf("unexpected first delivery %+v", first)
+	}
+	if second := deliveries[1]; second.Attempt != 2 || !second.Succeeded || second.StatusCode != http.StatusNoContent || second.EventId != "id" {
+		t.Errorf("unexpected second delivery %+v", second)
+	}
+}
+
+func TestWebhookGivesUpAfterMaxAttempts(t *testing.T) {
+	server, requests, _ := receiver(t, http.StatusBadGateway)
+	repo := newMemoryWebhookRepository()
+	ctrl := WebhookControllerImpl{Repo: repo, MaxAttempts: 3, BaseDelay: time.Millisecond}
+
+	ctrl.deliver(testEndpoint(server.URL), models.WebhookPayload{Id: "id", Type: models.CarAddedWebhook}, []byte("{}"))
+	if len(requests) != 3 {
+		t.Errorf("expected 3 requests, got %v", len(requests))
+	}
+}
+
+func TestWebhookDoesNotRetryClientErrors(t *testing.T) {
+	server, requests, _ := receiver(t, http.StatusBadRequest, http.StatusNoContent)
+	repo := newMemoryWebhookRepository()
+	ctrl := WebhookControllerImpl{Repo: repo, BaseDelay: time.Millisecond}
+
+	ctrl.deliver(testEndpoint(server.URL), models.WebhookPayload{Id: "id", Type: models.CarAddedWebhook}, []byte("{}"))
+	if len(requests) != 1 {
+		t.Errorf("expected 1 request, got %v", len(requests))
+	}
+	deliveries, _ := repo.SelectWebhookDeliveries(1, 10)
+	if len(deliveries) != 1 || deliveries[0].Succeeded || deliveries[0].StatusCode != http.StatusBadRequest {
+		t.Errorf("unexpected deliveries %+v", deliveries)
+	}
+}
+
+func TestWebhookSkipsUnsubscribedEndpoints(t *testing.T) {
+	server, requests, _ := receiver(t, http.StatusNoContent)
+	inactive := testEndpoint(server.URL)
+	inactive.Active = false
+	other := testEndpoint(server.URL)
+	other.Id = 2
+	other.Events = []models.WebhookEventType{models.TrackAddedWebhook}
+	repo := newMemoryWebhookRepository(inactive, other)
+	ctrl := WebhookControllerImpl{Repo: repo}
+
+	ctrl.Dispatch(models.LiveEvent{Id: 1, Type: models.CarAddedEvent, Payload: models.Car{}})
+	ctrl.Dispatch(models.LiveEvent{Id: 2, Type: models.ResyncEvent})
+	select {
+	case <-requests:
+		t.Error("an endpoint received an event it isn't subscribed to")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -0,0 +1,135 @@
+package helpers
+
+import (
+	"github.com/davide/ModRepository/models"
+	"math"
+	"sort"
+)
+
+type ScoredCar struct {
+	Id    uint
+	Score float64
+}
+
+var drivetrains = []models.Drivetrain{models.RearWheelDrive, models.FrontWheelDrive, models.AllWheelDrive}
+var transmissions = []models.Transmission{models.Sequential, models.Manual}
+var carTypes = []models.CarType{models.EnduranceCar, models.OpenWheel, models.GT, models.Prototype, models.RallyCar, models.Street, models.Tuned, models.Touring, models.Vintage, models.StockCar}
+
+// numeric specs weigh more than the categorical ones, a GT3 and a GT4 should still look alike
+const numericWeight = 1.0
+const categoricalWeight = 0.5
+
+type specRange struct {
+	min, max float64
+}
+
+func (r specRange) normalize(value float64) float64 {
+	if r.max == r.min {
+		return 0
+	}
+	return (value - r.min) / (r.max - r.min)
+}
+
+func numericSpecs(car models.Car) []float64 {
+	return []float64{float64(car.BHP), float64(car.Weight), float64(car.Torque), float64(car.TopSpeed), float64(car.Year)}
+}
+
+// CarSpecVectors builds the normalized spec vector of every car, numeric specs are scaled on the catalog min-max
+// and a zero value is treated as unknown
+func CarSpecVectors(cars []models.Car) map[uint][]float64 {
+	ranges := make([]specRange, len(numericSpecs(models.Car{})))
+	for i := range ranges {
+		ranges[i] = specRange{min: math.Inf(1), max: math.Inf(-1)}
+	}
+	for _, car := range cars {
+		for i, value := range numericSpecs(car) {
+			if value == 0 {
+				continue
+			}
+			ranges[i].min = math.Min(ranges[i].min, value)
+			ranges[i].max = math.Max(ranges[i].max, value)
+		}
+	}
+
+	vectors := make(map[uint][]float64, len(cars))
+	for _, car := range cars {
+		var vector []float64
+		for i, value := range numericSpecs(car) {
+			if value == 0 {
+				vector = append(vector, math.NaN())
+			} else {
+				vector = append(vector, ranges[i].normalize(value))
+			}
+		}
+		for _, drivetrain := range drivetrains {
+			vector = append(vector, oneHot(car.Drivetrain == drivetrain))
+		}
+		for _, transmission := range transmissions {
+			vector = append(vector, oneHot(car.Transmission == transmission))
+		}
+		for _, carType := range carTypes {
+			vector = append(vector, oneHot(hasCategory(car.Categories, carType)))
+		}
+		vectors[car.Id] = vector
+	}
+	return vectors
+}
+
+// SpecSimilarity returns a score between 0 and 1, unknown numeric specs are skipped
+func SpecSimilarity(a []float64, b []float64) float64 {
+	numeric := len(numericSpecs(models.Car{}))
+	var distance, maxDistance float64
+	for i := range a {
+		weight := categoricalWeight
+		if i < numeric {
+			if math.IsNaN(a[i]) || math.IsNaN(b[i]) {
+				continue
+			}
+			weight = numericWeight
+		}
+		distance += weight * (a[i] - b[i]) * (a[i] - b[i])
+		maxDistance += weight
+	}
+	if maxDistance == 0 {
+		return 0
+	}
+	return 1 - math.Sqrt(distance/maxDistance)
+}
+
+// RankBySimilarity sorts every other car by similarity to the given one, best match first
+func RankBySimilarity(carId uint, vectors map[uint][]float64) []ScoredCar {
+	target, ok := vectors[carId]
+	if !ok {
+		return nil
+	}
+	var ranking []ScoredCar
+	for id, vector := range vectors {
+		if id == carId {
+			continue
+		}
+		ranking = append(ranking, ScoredCar{Id: id, Score: SpecSimilarity(target, vector)})
+	}
+	sort.Slice(ranking, func(i, j int) bool {
+		if ranking[i].Score == ranking[j].Score {
+			return ranking[i].Id < ranking[j].Id
+		}
+		return ranking[i].Score > ranking[j].Score
+	})
+	return ranking
+}
+
+func oneHot(condition bool) float64 {
+	if condition {
+		return 1
+	}
+	return 0
+}
+
+func hasCategory(categories []models.CarCategory, carType models.CarType) bool {
+	for _, category := range categories {
+		if category.Name == carType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,32 @@
+package helpers
+
+import (
+	"github.com/davide/ModRepository/models"
+	"sort"
+)
+
+// NormalizeGallery sorts the images by position, renumbers them from 0 and keeps exactly one favorite:
+// the first one flagged or, when none is, the first image
+func NormalizeGallery(images []models.Image) []models.Image {
+	if len(images) == 0 {
+		return images
+	}
+	gallery := make([]models.Image, len(images))
+	copy(gallery, images)
+	sort.SliceStable(gallery, func(i, j int) bool {
+		return gallery[i].Position < gallery[j].Position
+	})
+
+	favorite := 0
+	for i, image := range gallery {
+		if image.Favorite {
+			favorite = i
+			break
+		}
+	}
+	for i := range gallery {
+		gallery[i].Position = i
+		gallery[i].Favorite = i == favorite
+	}
+	return gallery
+}
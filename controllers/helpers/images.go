@@ -0,0 +1,232 @@
+package helpers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"github.com/davide/ModRepository/models"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+)
+
+// maxImagePixels bounds the decoded size of an upload, a few kilobytes of png can declare gigapixel dimensions
+const maxImagePixels = 40 * 1000 * 1000
+
+type ImageVariant struct {
+	Name  string
+	Width int
+}
+
+// ImageVariants are the responsive sizes generated for the gallery uploads, smaller images are never upscaled
+var ImageVariants = []ImageVariant{
+	{Name: "thumbnail", Width: 320},
+	{Name: "small", Width: 640},
+	{Name: "medium", Width: 1280},
+	{Name: "large", Width: 1920},
+}
+
+type EncodedImage struct {
+	Variant   string
+	Content   []byte
+	Extension string
+}
+
+// ProcessImage decodes an upload and re-encodes it with the given variants. Re-encoding drops EXIF and any other
+// metadata, the orientation tag is applied to the pixels first so photos don't come out rotated.
+// Images with transparency, like brand logos, are kept as png when keepAlpha is set.
+func ProcessImage(content []byte, keepAlpha bool, variants []ImageVariant) ([]EncodedImage, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+	if config.Width*config.Height > maxImagePixels {
+		return nil, models.NewValidationError("image", fmt.Sprintf("is %vx%v, more than the %v megapixels allowed", config.Width, config.Height, maxImagePixels/1000/1000))
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+
+	rgba := toRGBA(src, keepAlpha)
+	rgba = applyOrientation(rgba, jpegOrientation(content))
+
+	encoded := make([]EncodedImage, 0, len(variants)+1)
+	original, ext, err := encodeImage(rgba, keepAlpha)
+	if err != nil {
+		return nil, err
+	}
+	encoded = append(encoded, EncodedImage{Variant: "original", Content: original, Extension: ext})
+
+	for _, variant := range variants {
+		if rgba.Bounds().Dx() <= variant.Width {
+			continue
+		}
+		resized := resize(rgba, variant.Width, rgba.Bounds().Dy()*variant.Width/rgba.Bounds().Dx())
+		content, ext, err := encodeImage(resized, keepAlpha)
+		if err != nil {
+			return nil, err
+		}
+		encoded = append(encoded, EncodedImage{Variant: variant.Name, Content: content, Extension: ext})
+	}
+	return encoded, nil
+}
+
+func encodeImage(img *image.RGBA, keepAlpha bool) ([]byte, string, error) {
+	var buffer bytes.Buffer
+	if keepAlpha {
+		if err := png.Encode(&buffer, img); err != nil {
+			return nil, "", err
+		}
+		return buffer.Bytes(), "png", nil
+	}
+	if err := jpeg.Encode(&buffer, img, &jpeg.Options{Quality: 88}); err != nil {
+		return nil, "", err
+	}
+	return buffer.Bytes(), "jpg", nil
+}
+
+func toRGBA(src image.Image, keepAlpha bool) *image.RGBA {
+	bounds := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	if !keepAlpha {
+		draw.Draw(dst, dst.Bounds(), &image.Uniform{C: color.White}, image.Point{}, draw.Src)
+	}
+	draw.Draw(dst, dst.Bounds(), src, bounds.Min, draw.Over)
+	return dst
+}
+
+// resize scales down with a box filter, every destination pixel is the average of the source pixels it covers
+func resize(src *image.RGBA, width int, height int) *image.RGBA {
+	if height < 1 {
+		height = 1
+	}
+	srcWidth, srcHeight := src.Bounds().Dx(), src.Bounds().Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		sy0 := y * srcHeight / height
+		sy1 := (y + 1) * srcHeight / height
+		if sy1 <= sy0 {
+			sy1 = sy0 + 1
+		}
+		for x := 0; x < width; x++ {
+			sx0 := x * srcWidth / width
+			sx1 := (x + 1) * srcWidth / width
+			if sx1 <= sx0 {
+				sx1 = sx0 + 1
+			}
+			var r, g, b, a, count uint32
+			for sy := sy0; sy < sy1; sy++ {
+				offset := src.PixOffset(sx0, sy)
+				for sx := sx0; sx < sx1; sx++ {
+					r += uint32(src.Pix[offset])
+					g += uint32(src.Pix[offset+1])
+					b += uint32(src.Pix[offset+2])
+					a += uint32(src.Pix[offset+3])
+					offset += 4
+					count++
+				}
+			}
+			offset := dst.PixOffset(x, y)
+			dst.Pix[offset] = uint8(r / count)
+			dst.Pix[offset+1] = uint8(g / count)
+			dst.Pix[offset+2] = uint8(b / count)
+			dst.Pix[offset+3] = uint8(a / count)
+		}
+	}
+	return dst
+}
+
+// applyOrientation rotates and flips the pixels as described by the EXIF orientation tag (1 to 8)
+func applyOrientation(src *image.RGBA, orientation int) *image.RGBA {
+	if orientation < 2 || orientation > 8 {
+		return src
+	}
+	width, height := src.Bounds().Dx(), src.Bounds().Dy()
+	dstWidth, dstHeight := width, height
+	if orientation >= 5 {
+		dstWidth, dstHeight = height, width
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2:
+				dx, dy = width-1-x, y
+			case 3:
+				dx, dy = width-1-x, height-1-y
+			case 4:
+				dx, dy = x, height-1-y
+			case 5:
+				dx, dy = y, x
+			case 6:
+				dx, dy = height-1-y, x
+			case 7:
+				dx, dy = height-1-y, width-1-x
+			case 8:
+				dx, dy = y, width-1-x
+			}
+			dst.SetRGBA(dx, dy, src.RGBAAt(x, y))
+		}
+	}
+	return dst
+}
+
+// jpegOrientation reads the orientation tag from the EXIF segment of a jpeg, 0 when missing
+func jpegOrientation(content []byte) int {
+	if len(content) < 4 || content[0] != 0xFF || content[1] != 0xD8 {
+		return 0
+	}
+	offset := 2
+	for offset+4 <= len(content) {
+		if content[offset] != 0xFF {
+			return 0
+		}
+		marker := content[offset+1]
+		length := int(binary.BigEndian.Uint16(content[offset+2:]))
+		if marker == 0xDA || length < 2 || offset+2+length > len(content) {
+			return 0
+		}
+		segment := content[offset+4 : offset+2+length]
+		if marker == 0xE1 && len(segment) > 14 && string(segment[:6]) == "Exif\x00\x00" {
+			return tiffOrientation(segment[6:])
+		}
+		offset += 2 + length
+	}
+	return 0
+}
+
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 0
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd+2 > len(tiff) {
+		return 0
+	}
+	entries := int(order.Uint16(tiff[ifd:]))
+	for i := 0; i < entries; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 0
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			return int(order.Uint16(tiff[entry+8:]))
+		}
+	}
+	return 0
+}
@@ -0,0 +1,365 @@
+package helpers
+
+import (
+	"fmt"
+	"github.com/davide/ModRepository/models"
+	"strings"
+)
+
+type isoCountry struct {
+	code      string
+	name      string
+	continent models.Continent
+	region    string
+}
+
+// isoCountries lists the ISO 3166-1 alpha-2 countries with their UN M49 region
+var isoCountries = []isoCountry{
+	{"AD", "Andorra", models.Europe, "Southern Europe"},
+	{"AE", "United Arab Emirates", models.Asia, "Western Asia"},
+	{"AF", "Afghanistan", models.Asia, "Southern Asia"},
+	{"AG", "Antigua and Barbuda", models.NorthAmerica, "Caribbean"},
+	{"AI", "Anguilla", models.NorthAmerica, "Caribbean"},
+	{"AL", "Albania", models.Europe, "Southern Europe"},
+	{"AM", "Armenia", models.Asia, "Western Asia"},
+	{"AO", "Angola", models.Africa, "Middle Africa"},
+	{"AQ", "Antarctica", models.Antarctica, "Antarctica"},
+	{"AR", "Argentina", models.SouthAmerica, "South America"},
+	{"AS", "American Samoa", models.Oceania, "Polynesia"},
+	{"AT", "Austria", models.Europe, "Western Europe"},
+	{"AU", "Australia", models.Oceania, "Australia and New Zealand"},
+	{"AW", "Aruba", models.NorthAmerica, "Caribbean"},
+	{"AX", "Aland Islands", models.Europe, "Northern Europe"},
+	{"AZ", "Azerbaijan", models.Asia, "Western Asia"},
+	{"BA", "Bosnia and Herzegovina", models.Europe, "Southern Europe"},
+	{"BB", "Barbados", models.NorthAmerica, "Caribbean"},
+	{"BD", "Bangladesh", models.Asia, "Southern Asia"},
+	{"BE", "Belgium", models.Europe, "Western Europe"},
+	{"BF", "Burkina Faso", models.Africa, "Western Africa"},
+	{"BG", "Bulgaria", models.Europe, "Eastern Europe"},
+	{"BH", "Bahrain", models.Asia, "Western Asia"},
+	{"BI", "Burundi", models.Africa, "Eastern Africa"},
+	{"BJ", "Benin", models.Africa, "Western Africa"},
+	{"BL", "Saint Barthelemy", models.NorthAmerica, "Caribbean"},
+	{"BM", "Bermuda", models.NorthAmerica, "Northern America"},
+	{"BN", "Brunei", models.Asia, "South-eastern Asia"},
+	{"BO", "Bolivia", models.SouthAmerica, "South America"},
+	{"BQ", "Caribbean Netherlands", models.NorthAmerica, "Caribbean"},
+	{"BR", "Brazil", models.SouthAmerica, "South America"},
+	{"BS", "Bahamas", models.NorthAmerica, "Caribbean"},
+	{"BT", "Bhutan", models.Asia, "Southern Asia"},
+	{"BV", "Bouvet Island", models.SouthAmerica, "South America"},
+	{"BW", "Botswana", models.Africa, "Southern Africa"},
+	{"BY", "Belarus", models.Europe, "Eastern Europe"},
+	{"BZ", "Belize", models.NorthAmerica, "Central America"},
+	{"CA", "Canada", models.NorthAmerica, "Northern America"},
+	{"CC", "Cocos (Keeling) Islands", models.Oceania, "Australia and New Zealand"},
+	{"CD", "Democratic Republic of the Congo", models.Africa, "Middle Africa"},
+	{"CF", "Central African Republic", models.Africa, "Middle Africa"},
+	{"CG", "Republic of the Congo", models.Africa, "Middle Africa"},
+	{"CH", "Switzerland", models.Europe, "Western Europe"},
+	{"CI", "Ivory Coast", models.Africa, "Western Africa"},
+	{"CK", "Cook Islands", models.Oceania, "Polynesia"},
+	{"CL", "Chile", models.SouthAmerica, "South America"},
+	{"CM", "Cameroon", models.Africa, "Middle Africa"},
+	{"CN", "China", models.Asia, "Eastern Asia"},
+	{"CO", "Colombia", models.SouthAmerica, "South America"},
+	{"CR", "Costa Rica", models.NorthAmerica, "Central America"},
+	{"CU", "Cuba", models.NorthAmerica, "Caribbean"},
+	{"CV", "Cape Verde", models.Africa, "Western Africa"},
+	{"CW", "Curacao", models.NorthAmerica, "Caribbean"},
+	{"CX", "Christmas Island", models.Oceania, "Australia and New Zealand"},
+	{"CY", "Cyprus", models.Asia, "Western Asia"},
+	{"CZ", "Czech Republic", models.Europe, "Eastern Europe"},
+	{"DE", "Germany", models.Europe, "Western Europe"},
+	{"DJ", "Djibouti", models.Africa, "Eastern Africa"},
+	{"DK", "Denmark", models.Europe, "Northern Europe"},
+	{"DM", "Dominica", models.NorthAmerica, "Caribbean"},
+	{"DO", "Dominican Republic", models.NorthAmerica, "Caribbean"},
+	{"DZ", "Algeria", models.Africa, "Northern Africa"},
+	{"EC", "Ecuador", models.SouthAmerica, "South America"},
+	{"EE", "Estonia", models.Europe, "Northern Europe"},
+	{"EG", "Egypt", models.Africa, "Northern Africa"},
+	{"EH", "Western Sahara", models.Africa, "Northern Africa"},
+	{"ER", "Eritrea", models.Africa, "Eastern Africa"},
+	{"ES", "Spain", models.Europe, "Southern Europe"},
+	{"ET", "Ethiopia", models.Africa, "Eastern Africa"},
+	{"FI", "Finland", models.Europe, "Northern Europe"},
+	{"FJ", "Fiji", models.Oceania, "Melanesia"},
+	{"FK", "Falkland Islands", models.SouthAmerica, "South America"},
+	{"FM", "Micronesia", models.Oceania, "Micronesia"},
+	{"FO", "Faroe Islands", models.Europe, "Northern Europe"},
+	{"FR", "France", models.Europe, "Western Europe"},
+	{"GA", "Gabon", models.Africa, "Middle Africa"},
+	{"GB", "United Kingdom", models.Europe, "Northern Europe"},
+	{"GD", "Grenada", models.NorthAmerica, "Caribbean"},
+	{"GE", "Georgia", models.Asia, "Western Asia"},
+	{"GF", "French Guiana", models.SouthAmerica, "South America"},
+	{"GG", "Guernsey", models.Europe, "Northern Europe"},
+	{"GH", "Ghana", models.Africa, "Western Africa"},
+	{"GI", "Gibraltar", models.Europe, "Southern Europe"},
+	{"GL", "Greenland", models.NorthAmerica, "Northern America"},
+	{"GM", "Gambia", models.Africa, "Western Africa"},
+	{"GN", "Guinea", models.Africa, "Western Africa"},
+	{"GP", "Guadeloupe", models.NorthAmerica, "Caribbean"},
+	{"GQ", "Equatorial Guinea", models.Africa, "Middle Africa"},
+	{"GR", "Greece", models.Europe, "Southern Europe"},
+	{"GS", "South Georgia and the South Sandwich Islands", models.SouthAmerica, "South America"},
+	{"GT", "Guatemala", models.NorthAmerica, "Central America"},
+	{"GU", "Guam", models.Oceania, "Micronesia"},
+	{"GW", "Guinea-Bissau", models.Africa, "Western Africa"},
+	{"GY", "Guyana", models.SouthAmerica, "South America"},
+	{"HK", "Hong Kong", models.Asia, "Eastern Asia"},
+	{"HM", "Heard Island and McDonald Islands", models.Oceania, "Australia and New Zealand"},
+	{"HN", "Honduras", models.NorthAmerica, "Central America"},
+	{"HR", "Croatia", models.Europe, "Southern Europe"},
+	{"HT", "Haiti", models.NorthAmerica, "Caribbean"},
+	{"HU", "Hungary", models.Europe, "Eastern Europe"},
+	{"ID", "Indonesia", models.Asia, "South-eastern Asia"},
+	{"IE", "Ireland", models.Europe, "Northern Europe"},
+	{"IL", "Israel", models.Asia, "Western Asia"},
+	{"IM", "Isle of Man", models.Europe, "Northern Europe"},
+	{"IN", "India", models.Asia, "Southern Asia"},
+	{"IO", "British Indian Ocean Territory", models.Africa, "Eastern Africa"},
+	{"IQ", "Iraq", models.Asia, "Western Asia"},
+	{"IR", "Iran", models.Asia, "Southern Asia"},
+	{"IS", "Iceland", models.Europe, "Northern Europe"},
+	{"IT", "Italy", models.Europe, "Southern Europe"},
+	{"JE", "Jersey", models.Europe, "Northern Europe"},
+	{"JM", "Jamaica", models.NorthAmerica, "Caribbean"},
+	{"JO", "Jordan", models.Asia, "Western Asia"},
+	{"JP", "Japan", models.Asia, "Eastern Asia"},
+	{"KE", "Kenya", models.Africa, "Eastern Africa"},
+	{"KG", "Kyrgyzstan", models.Asia, "Central Asia"},
+	{"KH", "Cambodia", models.Asia, "South-eastern Asia"},
+	{"KI", "Kiribati", models.Oceania, "Micronesia"},
+	{"KM", "Comoros", models.Africa, "Eastern Africa"},
+	{"KN", "Saint Kitts and Nevis", models.NorthAmerica, "Caribbean"},
+	{"KP", "North Korea", models.Asia, "Eastern Asia"},
+	{"KR", "South Korea", models.Asia, "Eastern Asia"},
+	{"KW", "Kuwait", models.Asia, "Western Asia"},
+	{"KY", "Cayman Islands", models.NorthAmerica, "Caribbean"},
+	{"KZ", "Kazakhstan", models.Asia, "Central Asia"},
+	{"LA", "Laos", models.Asia, "South-eastern Asia"},
+	{"LB", "Lebanon", models.Asia, "Western Asia"},
+	{"LC", "Saint Lucia", models.NorthAmerica, "Caribbean"},
+	{"LI", "Liechtenstein", models.Europe, "Western Europe"},
+	{"LK", "Sri Lanka", models.Asia, "Southern Asia"},
+	{"LR", "Liberia", models.Africa, "Western Africa"},
+	{"LS", "Lesotho", models.Africa, "Southern Africa"},
+	{"LT", "Lithuania", models.Europe, "Northern Europe"},
+	{"LU", "Luxembourg", models.Europe, "Western Europe"},
+	{"LV", "Latvia", models.Europe, "Northern Europe"},
+	{"LY", "Libya", models.Africa, "Northern Africa"},
+	{"MA", "Morocco", models.Africa, "Northern Africa"},
+	{"MC", "Monaco", models.Europe, "Western Europe"},
+	{"MD", "Moldova", models.Europe, "Eastern Europe"},
+	{"ME", "Montenegro", models.Europe, "Southern Europe"},
+	{"MF", "Saint Martin", models.NorthAmerica, "Caribbean"},
+	{"MG", "Madagascar", models.Africa, "Eastern Africa"},
+	{"MH", "Marshall Islands", models.Oceania, "Micronesia"},
+	{"MK", "North Macedonia", models.Europe, "Southern Europe"},
+	{"ML", "Mali", models.Africa, "Western Africa"},
+	{"MM", "Myanmar", models.Asia, "South-eastern Asia"},
+	{"MN", "Mongolia", models.Asia, "Eastern Asia"},
+	{"MO", "Macau", models.Asia, "Eastern Asia"},
+	{"MP", "Northern Mariana Islands", models.Oceania, "Micronesia"},
+	{"MQ", "Martinique", models.NorthAmerica, "Caribbean"},
+	{"MR", "Mauritania", models.Africa, "Western Africa"},
+	{"MS", "Montserrat", models.NorthAmerica, "Caribbean"},
+	{"MT", "Malta", models.Europe, "Southern Europe"},
+	{"MU", "Mauritius", models.Africa, "Eastern Africa"},
+	{"MV", "Maldives", models.Asia, "Southern Asia"},
+	{"MW", "Malawi", models.Africa, "Eastern Africa"},
+	{"MX", "Mexico", models.NorthAmerica, "Central America"},
+	{"MY", "Malaysia", models.Asia, "South-eastern Asia"},
+	{"MZ", "Mozambique", models.Africa, "Eastern Africa"},
+	{"NA", "Namibia", models.Africa, "Southern Africa"},
+	{"NC", "New Caledonia", models.Oceania, "Melanesia"},
+	{"NE", "Niger", models.Africa, "Western Africa"},
+	{"NF", "Norfolk Island", models.Oceania, "Australia and New Zealand"},
+	{"NG", "Nigeria", models.Africa, "Western Africa"},
+	{"NI", "Nicaragua", models.NorthAmerica, "Central America"},
+	{"NL", "Netherlands", models.Europe, "Western Europe"},
+	{"NO", "Norway", models.Europe, "Northern Europe"},
+	{"NP", "Nepal", models.Asia, "Southern Asia"},
+	{"NR", "Nauru", models.Oceania, "Micronesia"},
+	{"NU", "Niue", models.Oceania, "Polynesia"},
+	{"NZ", "New Zealand", models.Oceania, "Australia and New Zealand"},
+	{"OM", "Oman", models.Asia, "Western Asia"},
+	{"PA", "Panama", models.NorthAmerica, "Central America"},
+	{"PE", "Peru", models.SouthAmerica, "South America"},
+	{"PF", "French Polynesia", models.Oceania, "Polynesia"},
+	{"PG", "Papua New Guinea", models.Oceania, "Melanesia"},
+	{"PH", "Philippines", models.Asia, "South-eastern Asia"},
+	{"PK", "Pakistan", models.Asia, "Southern Asia"},
+	{"PL", "Poland", models.Europe, "Eastern Europe"},
+	{"PM", "Saint Pierre and Miquelon", models.NorthAmerica, "Northern America"},
+	{"PN", "Pitcairn", models.Oceania, "Polynesia"},
+	{"PR", "Puerto Rico", models.NorthAmerica, "Caribbean"},
+	{"PS", "Palestine", models.Asia, "Western Asia"},
+	{"PT", "Portugal", models.Europe, "Southern Europe"},
+	{"PW", "Palau", models.Oceania, "Micronesia"},
+	{"PY", "Paraguay", models.SouthAmerica, "South America"},
+	{"QA", "Qatar", models.Asia, "Western Asia"},
+	{"RE", "Reunion", models.Africa, "Eastern Africa"},
+	{"RO", "Romania", models.Europe, "Eastern Europe"},
+	{"RS", "Serbia", models.Europe, "Southern Europe"},
+	{"RU", "Russia", models.Europe, "Eastern Europe"},
+	{"RW", "Rwanda", models.Africa, "Eastern Africa"},
+	{"SA", "Saudi Arabia", models.Asia, "Western Asia"},
+	{"SB", "Solomon Islands", models.Oceania, "Melanesia"},
+	{"SC", "Seychelles", models.Africa, "Eastern Africa"},
+	{"SD", "Sudan", models.Africa, "Northern Africa"},
+	{"SE", "Sweden", models.Europe, "Northern Europe"},
+	{"SG", "Singapore", models.Asia, "South-eastern Asia"},
+	{"SH", "Saint Helena", models.Africa, "Western Africa"},
+	{"SI", "Slovenia", models.Europe, "Southern Europe"},
+	{"SJ", "Svalbard and Jan Mayen", models.Europe, "Northern Europe"},
+	{"SK", "Slovakia", models.Europe, "Eastern Europe"},
+	{"SL", "Sierra Leone", models.Africa, "Western Africa"},
+	{"SM", "San Marino", models.Europe, "Southern Europe"},
+	{"SN", "Senegal", models.Africa, "Western Africa"},
+	{"SO", "Somalia", models.Africa, "Eastern Africa"},
+	{"SR", "Suriname", models.SouthAmerica, "South America"},
+	{"SS", "South Sudan", models.Africa, "Eastern Africa"},
+	{"ST", "Sao Tome and Principe", models.Africa, "Middle Africa"},
+	{"SV", "El Salvador", models.NorthAmerica, "Central America"},
+	{"SX", "Sint Maarten", models.NorthAmerica, "Caribbean"},
+	{"SY", "Syria", models.Asia, "Western Asia"},
+	{"SZ", "Eswatini", models.Africa, "Southern Africa"},
+	{"TC", "Turks and Caicos Islands", models.NorthAmerica, "Caribbean"},
+	{"TD", "Chad", models.Africa, "Middle Africa"},
+	{"TF", "French Southern Territories", models.Africa, "Eastern Africa"},
+	{"TG", "Togo", models.Africa, "Western Africa"},
+	{"TH", "Thailand", models.Asia, "South-eastern Asia"},
+	{"TJ", "Tajikistan", models.Asia, "Central Asia"},
+	{"TK", "Tokelau", models.Oceania, "Polynesia"},
+	{"TL", "Timor-Leste", models.Asia, "South-eastern Asia"},
+	{"TM", "Turkmenistan", models.Asia, "Central Asia"},
+	{"TN", "Tunisia", models.Africa, "Northern Africa"},
+	{"TO", "Tonga", models.Oceania, "Polynesia"},
+	{"TR", "Turkey", models.Asia, "Western Asia"},
+	{"TT", "Trinidad and Tobago", models.NorthAmerica, "Caribbean"},
+	{"TV", "Tuvalu", models.Oceania, "Polynesia"},
+	{"TW", "Taiwan", models.Asia, "Eastern Asia"},
+	{"TZ", "Tanzania", models.Africa, "Eastern Africa"},
+	{"UA", "Ukraine", models.Europe, "Eastern Europe"},
+	{"UG", "Uganda", models.Africa, "Eastern Africa"},
+	{"UM", "United States Minor Outlying Islands", models.Oceania, "Micronesia"},
+	{"US", "United States", models.NorthAmerica, "Northern America"},
+	{"UY", "Uruguay", models.SouthAmerica, "South America"},
+	{"UZ", "Uzbekistan", models.Asia, "Central Asia"},
+	{"VA", "Vatican City", models.Europe, "Southern Europe"},
+	{"VC", "Saint Vincent and the Grenadines", models.NorthAmerica, "Caribbean"},
+	{"VE", "Venezuela", models.SouthAmerica, "South America"},
+	{"VG", "British Virgin Islands", models.NorthAmerica, "Caribbean"},
+	{"VI", "United States Virgin Islands", models.NorthAmerica, "Caribbean"},
+	{"VN", "Vietnam", models.Asia, "South-eastern Asia"},
+	{"VU", "Vanuatu", models.Oceania, "Melanesia"},
+	{"WF", "Wallis and Futuna", models.Oceania, "Polynesia"},
+	{"WS", "Samoa", models.Oceania, "Polynesia"},
+	{"YE", "Yemen", models.Asia, "Western Asia"},
+	{"YT", "Mayotte", models.Africa, "Eastern Africa"},
+	{"ZA", "South Africa", models.Africa, "Southern Africa"},
+	{"ZM", "Zambia", models.Africa, "Eastern Africa"},
+	{"ZW", "Zimbabwe", models.Africa, "Eastern Africa"},
+}
+
+var nationAliases = map[string]string{
+	"uk":                       "GB",
+	"great britain":            "GB",
+	"britain":                  "GB",
+	"england":                  "GB",
+	"scotland":                 "GB",
+	"wales":                    "GB",
+	"northern ireland":         "GB",
+	"usa":                      "US",
+	"united states of america": "US",
+	"america":                  "US",
+	"holland":                  "NL",
+	"the netherlands":          "NL",
+	"korea":                    "KR",
+	"republic of korea":        "KR",
+	"czechia":                  "CZ",
+	"russian federation":       "RU",
+	"uae":                      "AE",
+	"macao":                    "MO",
+	"turkiye":                  "TR",
+	"swaziland":                "SZ",
+	"burma":                    "MM",
+	"east timor":               "TL",
+	"cote d'ivoire":            "CI",
+}
+
+// FlagUrl derives the flag image of a nation from its ISO 3166 code
+func FlagUrl(code string) string {
+	return fmt.Sprintf("https://flagcdn.com/%v.svg", strings.ToLower(code))
+}
+
+func (c isoCountry) toNation() models.Nation {
+	return models.Nation{
+		Name:      c.name,
+		Code:      c.code,
+		Flag:      FlagUrl(c.code),
+		Continent: c.continent,
+		Region:    c.region,
+	}
+}
+
+func IsoNations() []models.Nation {
+	nations := make([]models.Nation, 0, len(isoCountries))
+	for _, country := range isoCountries {
+		nations = append(nations, country.toNation())
+	}
+	return nations
+}
+
+func IsValidContinent(continent models.Continent) bool {
+	for _, c := range models.Continents {
+		if c == continent {
+			return true
+		}
+	}
+	return false
+}
+
+func nationByCode(code string) (models.Nation, bool) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	for _, country := range isoCountries {
+		if country.code == code {
+			return country.toNation(), true
+		}
+	}
+	return models.Nation{}, false
+}
+
+func nationByName(name string) (models.Nation, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if code, ok := nationAliases[name]; ok {
+		return nationByCode(code)
+	}
+	for _, country := range isoCountries {
+		if strings.ToLower(country.name) == name {
+			return country.toNation(), true
+		}
+	}
+	return models.Nation{}, false
+}
+
+// NormalizeNation resolves a nation sent by a client to its ISO 3166 entry, the code wins over the name
+func NormalizeNation(nation models.Nation) (models.Nation, error) {
+	if nation.Code != "" {
+		if normalized, ok := nationByCode(nation.Code); ok {
+			return normalized, nil
+		}
+		if normalized, ok := nationByName(nation.Code); ok {
+			return normalized, nil
+		}
+		return models.Nation{}, models.NewValidationError("nation.code", fmt.Sprintf("'%v' is not an ISO 3166 code", nation.Code))
+	}
+	if normalized, ok := nationByName(nation.Name); ok {
+		return normalized, nil
+	}
+	return models.Nation{}, models.NewValidationError("nation.name", fmt.Sprintf("unknown nation '%v'", nation.Name))
+}
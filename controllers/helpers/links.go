@@ -0,0 +1,30 @@
+package helpers
+
+import (
+	"fmt"
+	"github.com/davide/ModRepository/models"
+	"net/url"
+)
+
+const SiteUrl = "https://www.acmodrepository.com"
+
+func CarPageUrl(car models.Car) string {
+	return fmt.Sprintf("%v/cars/%v/%v/%v", SiteUrl, url.PathEscape(car.Brand.Name), url.PathEscape(car.ModelName), car.Year)
+}
+
+func TrackPageUrl(track models.Track) string {
+	return fmt.Sprintf("%v/tracks/%v/%v/%v", SiteUrl, url.PathEscape(track.Nation.Name), url.PathEscape(track.Name), track.Year)
+}
+
+// FavoriteImageUrl is the url of the favorite image, or of the first one when none is flagged
+func FavoriteImageUrl(images []models.Image) string {
+	for _, image := range images {
+		if image.Favorite {
+			return image.Url
+		}
+	}
+	if len(images) > 0 {
+		return images[0].Url
+	}
+	return ""
+}
@@ -11,3 +11,11 @@ func IsAdmin(role models.Role) bool {
 func IsPremium(role models.Role) bool {
 	return role == models.Premium || role == models.FSRTeam || role == models.Admin
 }
+
+// MaskMod hides the download link the role can't use, the same way the repositories do
+func MaskMod(mod models.Mod, role models.Role) models.Mod {
+	if (mod.Premium && !IsPremium(role)) || (mod.Personal && !IsAdmin(role)) {
+		mod.DownloadLink = mod.Source
+	}
+	return mod
+}
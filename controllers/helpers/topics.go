@@ -0,0 +1,165 @@
+package helpers
+
+import (
+	"fmt"
+	"github.com/davide/ModRepository/models"
+	"strings"
+)
+
+// The Firebase topics, the named ones are a prefix followed by the slug of the name
+const (
+	AllModsTopic   = "modsUpdates"
+	OfficialTopic  = "official"
+	CommunityTopic = "community"
+
+	brandTopicPrefix    = "brand_"
+	categoryTopicPrefix = "category_"
+	authorTopicPrefix   = "author_"
+	nationTopicPrefix   = "nation_"
+	trackTagTopicPrefix = "tag_"
+)
+
+// topicSlug keeps the lowercase letters and digits of name, every other run of characters becomes a dash
+func topicSlug(name string) string {
+	var slug strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if dash && slug.Len() > 0 {
+				slug.WriteByte('-')
+			}
+			slug.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	return slug.String()
+}
+
+func BrandTopic(name string) string {
+	return brandTopicPrefix + topicSlug(name)
+}
+
+func CategoryTopic(category models.CarType) string {
+	return categoryTopicPrefix + topicSlug(string(category))
+}
+
+func AuthorTopic(name string) string {
+	return authorTopicPrefix + topicSlug(name)
+}
+
+func NationTopic(code string) string {
+	return nationTopicPrefix + strings.ToLower(code)
+}
+
+func TrackTagTopic(tag models.TrackTag) string {
+	return trackTagTopicPrefix + topicSlug(string(tag))
+}
+
+func modTopics(mod models.Mod) []string {
+	topics := []string{AllModsTopic, CommunityTopic}
+	if mod.Official {
+		topics[1] = OfficialTopic
+	}
+	if mod.Author.Name != "" {
+		topics = append(topics, AuthorTopic(mod.Author.Name))
+	}
+	for _, contributor := range mod.Contributors {
+		if contributor.Name != "" {
+			topics = append(topics, AuthorTopic(contributor.Name))
+		}
+	}
+	return topics
+}
+
+// CarTopics are the topics announcing the car, each one once
+func CarTopics(car models.Car) []string {
+	topics := modTopics(car.Mod)
+	if car.Brand.Name != "" {
+		topics = append(topics, BrandTopic(car.Brand.Name))
+	}
+	for _, category := range car.Categories {
+		topics = append(topics, CategoryTopic(category.Name))
+	}
+	if car.Brand.Nation.Code != "" {
+		topics = append(topics, NationTopic(car.Brand.Nation.Code))
+	}
+	return uniqueTopics(topics)
+}
+
+// TrackTopics are the topics announcing the track, each one once
+func TrackTopics(track models.Track) []string {
+	topics := modTopics(track.Mod)
+	for _, tag := range track.Tags {
+		topics = append(topics, TrackTagTopic(tag))
+	}
+	if track.Nation.Code != "" {
+		topics = append(topics, NationTopic(track.Nation.Code))
+	}
+	return uniqueTopics(topics)
+}
+
+func uniqueTopics(topics []string) []string {
+	seen := map[string]bool{}
+	unique := topics[:0]
+	for _, topic := range topics {
+		if !seen[topic] {
+			seen[topic] = true
+			unique = append(unique, topic)
+		}
+	}
+	return unique
+}
+
+// ValidateTopic accepts only the topics of the scheme, the categories, the tags and the nations must exist
+// while brands and authors only need a valid slug
+func ValidateTopic(topic string) error {
+	switch topic {
+	case AllModsTopic, OfficialTopic, CommunityTopic:
+		return nil
+	}
+	invalid := models.NewValidationError("topic", fmt.Sprintf("'%v' is not a valid topic", topic))
+	switch {
+	case strings.HasPrefix(topic, brandTopicPrefix):
+		return slugTopic(topic, brandTopicPrefix, invalid)
+	case strings.HasPrefix(topic, authorTopicPrefix):
+		return slugTopic(topic, authorTopicPrefix, invalid)
+	case strings.HasPrefix(topic, categoryTopicPrefix):
+		for _, category := range models.CarTypes {
+			if topic == CategoryTopic(category) {
+				return nil
+			}
+		}
+	case strings.HasPrefix(topic, trackTagTopicPrefix):
+		for _, tag := range models.TrackTags {
+			if topic == TrackTagTopic(tag) {
+				return nil
+			}
+		}
+	case strings.HasPrefix(topic, nationTopicPrefix):
+		if nation, ok := nationByCode(strings.TrimPrefix(topic, nationTopicPrefix)); ok && topic == NationTopic(nation.Code) {
+			return nil
+		}
+	}
+	return invalid
+}
+
+// TopicScheme lists the fixed topics and, with {slug} or {code} in place of the name, the patterns of the others
+func TopicScheme() []string {
+	scheme := []string{AllModsTopic, OfficialTopic, CommunityTopic}
+	for _, category := range models.CarTypes {
+		scheme = append(scheme, CategoryTopic(category))
+	}
+	for _, tag := range models.TrackTags {
+		scheme = append(scheme, TrackTagTopic(tag))
+	}
+	return append(scheme, brandTopicPrefix+"{slug}", authorTopicPrefix+"{slug}", nationTopicPrefix+"{code}")
+}
+
+func slugTopic(topic string, prefix string, invalid error) error {
+	if name := strings.TrimPrefix(topic, prefix); name != "" && topicSlug(name) == name {
+		return nil
+	}
+	return invalid
+}
@@ -0,0 +1,283 @@
+package helpers
+
+import (
+	"fmt"
+	"github.com/davide/ModRepository/models"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	minCarYear   = 1885
+	minTrackYear = 1800
+	maxBHP       = 5000
+	maxTopSpeed  = 600
+	maxWeight    = 20000
+	maxTorque    = 10000
+)
+
+type validator struct {
+	fields []models.FieldError
+}
+
+func (v *validator) add(field string, format string, args ...interface{}) {
+	v.fields = append(v.fields, models.FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) required(field string, value string) {
+	if strings.TrimSpace(value) == "" {
+		v.add(field, "is required")
+	}
+}
+
+func (v *validator) url(field string, value string) {
+	if value == "" {
+		return
+	}
+	if u, err := url.ParseRequestURI(value); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		v.add(field, "must be a valid http or https url")
+	}
+}
+
+func (v *validator) requiredUrl(field string, value string) {
+	if strings.TrimSpace(value) == "" {
+		v.add(field, "is required")
+	} else {
+		v.url(field, value)
+	}
+}
+
+func (v *validator) max(field string, value uint, max uint) {
+	if value > max {
+		v.add(field, "must be at most %v", max)
+	}
+}
+
+func (v *validator) year(field string, value uint, min uint) {
+	if max := uint(time.Now().Year() + 1); value < min || value > max {
+		v.add(field, "must be between %v and %v", min, max)
+	}
+}
+
+func (v *validator) oneOf(field string, value string, allowed []string) {
+	for _, a := range allowed {
+		if a == value {
+			return
+		}
+	}
+	v.add(field, "must be one of: %v", strings.Join(allowed, ", "))
+}
+
+func (v validator) err() error {
+	if len(v.fields) == 0 {
+		return nil
+	}
+	return models.ValidationError{Fields: v.fields}
+}
+
+func carTypeNames() []string {
+	names := make([]string, 0, len(models.CarTypes))
+	for _, t := range models.CarTypes {
+		names = append(names, string(t))
+	}
+	return names
+}
+
+func drivetrainNames() []string {
+	names := make([]string, 0, len(models.Drivetrains))
+	for _, d := range models.Drivetrains {
+		names = append(names, string(d))
+	}
+	return names
+}
+
+func transmissionNames() []string {
+	names := make([]string, 0, len(models.Transmissions))
+	for _, t := range models.Transmissions {
+		names = append(names, string(t))
+	}
+	return names
+}
+
+func trackTagNames() []string {
+	names := make([]string, 0, len(models.TrackTags))
+	for _, t := range models.TrackTags {
+		names = append(names, string(t))
+	}
+	return names
+}
+
+func layoutTypeNames() []string {
+	names := make([]string, 0, len(models.LayoutTypes))
+	for _, t := range models.LayoutTypes {
+		names = append(names, string(t))
+	}
+	return names
+}
+
+func contributionRoleNames() []string {
+	names := make([]string, 0, len(models.ContributionRoles))
+	for _, r := range models.ContributionRoles {
+		names = append(names, string(r))
+	}
+	return names
+}
+
+func (v *validator) images(field string, images []models.Image) {
+	for i, image := range images {
+		v.requiredUrl(fmt.Sprintf("%v[%v].url", field, i), image.Url)
+	}
+}
+
+func (v *validator) mod(mod models.Mod) {
+	v.requiredUrl("downloadLink", mod.DownloadLink)
+	v.url("source", mod.Source)
+	v.required("author.name", mod.Author.Name)
+	v.url("author.link", mod.Author.Link)
+	v.images("images", mod.Images)
+	for i, contributor := range mod.Contributors {
+		v.required(fmt.Sprintf("contributors[%v].name", i), contributor.Name)
+		v.url(fmt.Sprintf("contributors[%v].link", i), contributor.Link)
+		v.oneOf(fmt.Sprintf("contributors[%v].role", i), string(contributor.Role), contributionRoleNames())
+	}
+}
+
+func ValidateCar(car models.Car) error {
+	v := validator{}
+	v.mod(car.Mod)
+	v.required("brand.name", car.Brand.Name)
+	v.url("brand.logo", car.Brand.Logo)
+	v.required("modelName", car.ModelName)
+	if len(car.Categories) == 0 {
+		v.add("categories", "at least one category is required")
+	}
+	for i, category := range car.Categories {
+		v.oneOf(fmt.Sprintf("categories[%v].name", i), string(category.Name), carTypeNames())
+	}
+	v.year("year", car.Year, minCarYear)
+	v.oneOf("drivetrain", string(car.Drivetrain), drivetrainNames())
+	v.oneOf("transmission", string(car.Transmission), transmissionNames())
+	v.max("bhp", car.BHP, maxBHP)
+	v.max("topSpeed", car.TopSpeed, maxTopSpeed)
+	v.max("weight", car.Weight, maxWeight)
+	v.max("torque", car.Torque, maxTorque)
+	return v.err()
+}
+
+func ValidateTrack(track models.Track) error {
+	v := validator{}
+	v.mod(track.Mod)
+	v.required("name", track.Name)
+	for i, tag := range track.Tags {
+		v.oneOf(fmt.Sprintf("tags[%v]", i), string(tag), trackTagNames())
+	}
+	if len(track.Layouts) == 0 {
+		v.add("layouts", "at least one layout is required")
+	}
+	for i, layout := range track.Layouts {
+		v.required(fmt.Sprintf("layouts[%v].name", i), layout.Name)
+		if layout.LengthM <= 0 {
+			v.add(fmt.Sprintf("layouts[%v].lengthM", i), "must be greater than 0")
+		}
+		v.oneOf(fmt.Sprintf("layouts[%v].category", i), string(layout.Category), layoutTypeNames())
+	}
+	v.required("nation.name", track.Nation.Name)
+	if track.Year != 0 {
+		v.year("year", track.Year, minTrackYear)
+	}
+	return v.err()
+}
+
+func ValidateServer(server models.Server) error {
+	v := validator{}
+	v.required("name", server.Name)
+	v.requiredUrl("joinLink", server.JoinLink)
+	if server.OutsideTrack {
+		v.required("outsideTrackName", server.OutsideTrackName)
+		v.url("outsideTrackLink", server.OutsideTrackLink)
+	} else if server.Track == 0 {
+		v.add("track", "is required")
+	}
+	for i, car := range server.OutsideCars {
+		v.required(fmt.Sprintf("outsideCars[%v].name", i), car.Name)
+		v.url(fmt.Sprintf("outsideCars[%v].downloadLink", i), car.DownloadLink)
+	}
+	return v.err()
+}
+
+func ValidateSkin(skin models.Skin) error {
+	v := validator{}
+	v.required("name", skin.Name)
+	v.requiredUrl("downloadLink", skin.DownloadLink)
+	v.url("imageUrl", skin.ImageUrl)
+	if skin.CarId == 0 {
+		v.add("carId", "is required")
+	}
+	return v.err()
+}
+
+func ValidateImage(image models.Image) error {
+	v := validator{}
+	v.requiredUrl("url", image.Url)
+	return v.err()
+}
+
+func ValidateModUpdate(update models.ModUpdate) error {
+	v := validator{}
+	v.oneOf("modType", string(update.ModType), []string{string(models.CarModType), string(models.TrackModType)})
+	if update.ModId == 0 {
+		v.add("modId", "is required")
+	}
+	v.required("version", update.Version)
+	v.url("downloadLink", update.DownloadLink)
+	v.images("images", update.Images)
+	return v.err()
+}
+
+func ValidateBrand(brand models.CarBrand) error {
+	v := validator{}
+	v.url("logo", brand.Logo)
+	return v.err()
+}
+
+func ValidateAuthor(author models.Author) error {
+	v := validator{}
+	v.url("link", author.Link)
+	return v.err()
+}
+
+func webhookEventNames() []string {
+	names := make([]string, 0, len(models.WebhookEventTypes))
+	for _, event := range models.WebhookEventTypes {
+		names = append(names, string(event))
+	}
+	return names
+}
+
+func ValidateWebhook(endpoint models.WebhookEndpoint) error {
+	v := validator{}
+	v.requiredUrl("url", endpoint.Url)
+	if len(endpoint.Events) == 0 {
+		v.add("events", "at least one event is required")
+	}
+	for _, event := range endpoint.Events {
+		v.oneOf("events", string(event), webhookEventNames())
+	}
+	return v.err()
+}
+
+func ValidateDiscordRoute(route models.DiscordRoute) error {
+	v := validator{}
+	v.required("channel", route.Channel)
+	for _, category := range route.Categories {
+		v.oneOf("categories", string(category), carTypeNames())
+	}
+	for _, tag := range route.Tags {
+		v.oneOf("tags", string(tag), trackTagNames())
+	}
+	if len(route.Categories) > 0 && len(route.Tags) > 0 {
+		v.add("tags", "a route matches either car categories or track tags")
+	}
+	return v.err()
+}
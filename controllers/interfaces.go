@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"github.com/bwmarrin/discordgo"
 	"github.com/davide/ModRepository/models"
 )
 
@@ -9,12 +10,23 @@ type CarController interface {
 	GetAllCarCategories() ([]models.CarCategory, error)
 	AddCar(car *models.Car) error
 	UpdateCar(car models.Car) (bool, error)
+	GetCar(id uint, role models.Role) (models.Car, error)
+	GetCarsByIds(ids []uint, role models.Role) ([]models.Car, error)
+	SearchCars(query string, role models.Role, limit int) ([]models.Car, error)
+	DeleteCar(id uint) error
+	GetRelatedCars(carId uint, role models.Role, limit int) (models.RelatedCars, error)
+	GetSimilarCars(carId uint, role models.Role, limit int) ([]models.SimilarCar, error)
 }
 
 type TrackController interface {
 	GetAllTracks(role models.Role) ([]models.Track, error)
 	AddTrack(track *models.Track) error
 	UpdateTrack(track models.Track) (bool, error)
+	GetTrack(id uint, role models.Role) (models.Track, error)
+	GetTracksByIds(ids []uint, role models.Role) ([]models.Track, error)
+	SearchTracks(query string, role models.Role, limit int) ([]models.Track, error)
+	DeleteTrack(id uint) error
+	GetRelatedTracks(trackId uint, role models.Role, limit int) (models.RelatedTracks, error)
 }
 
 type LogController interface {
@@ -24,16 +36,23 @@ type LogController interface {
 
 type BrandController interface {
 	GetAllBrands() ([]models.CarBrand, error)
+	GetBrand(name string, role models.Role) (models.BrandDetails, error)
+	UpdateBrand(name string, brand models.CarBrand) (models.CarBrand, error)
+	MergeBrands(source string, target string) error
 }
 
 type NationController interface {
-	GetAllTracksNations() ([]models.Nation, error)
-	GetAllBrandsNations() ([]models.Nation, error)
+	GetAllNations(continent models.Continent) ([]models.Nation, error)
+	GetAllTracksNations(continent models.Continent) ([]models.Nation, error)
+	GetAllBrandsNations(continent models.Continent) ([]models.Nation, error)
+	GetAllContinents() []models.Continent
+	SeedNations() error
+	MergeNations(source string, target string) error
 }
 
 type UserController interface {
 	Login(username string, password string) (models.User, error)
-	SignIn(username string, password string, role models.Role) (models.User, error)
+	SignIn(username string, password string, role models.Role, author string) (models.User, error)
 	UpdatePassword(username string, password string) error
 }
 
@@ -41,11 +60,15 @@ type AuthorController interface {
 	GetAllAuthors() ([]models.Author, error)
 	GetAllCarAuthors() ([]models.Author, error)
 	GetAllTrackAuthors() ([]models.Author, error)
+	GetAuthor(name string, role models.Role) (models.AuthorDetails, error)
+	UpdateAuthor(name string, author models.Author) (models.Author, error)
+	MergeAuthors(source string, target string) error
 }
 
 type ServersController interface {
 	GetAllServers() ([]models.Server, error)
-	AddServer(server models.Server) error
+	GetServer(id uint) (models.Server, error)
+	AddServer(server *models.Server) error
 	UpdateServer(server models.Server) error
 	DeleteServer(server models.Server) error
 }
@@ -53,21 +76,71 @@ type ServersController interface {
 type SkinController interface {
 	SelectCarSkins(carId uint) ([]models.Skin, error)
 	GetAllSkins() ([]models.Skin, error)
-	AddSkin(skin models.Skin) error
+	GetSkin(id uint) (models.Skin, error)
+	AddSkin(skin *models.Skin) error
 	UpdateSkin(skin models.Skin) error
+	DeleteSkin(id uint) error
 }
 
-type FirebaseController interface {
-	RegisterToTopic(token string, topic string) error
+// Notifier announces the mod changes on a channel, it's registered in the NotifierRegistry
+type Notifier interface {
 	NotifyCarAdded(car models.Car) error
 	NotifyCarUpdated(car models.Car) error
 	NotifyTrackUpdated(track models.Track) error
 	NotifyTrackAdded(track models.Track) error
 }
 
+type FirebaseController interface {
+	Notifier
+	RegisterToTopic(username string, token string, topic string) error
+	UnregisterFromTopic(username string, token string, topic string) error
+	GetDevices(username string) ([]models.DeviceToken, error)
+}
+
 type DiscordBotController interface {
-	NotifyCarAdded(car models.Car) error
-	NotifyCarUpdated(car models.Car) error
-	NotifyTrackUpdated(track models.Track) error
-	NotifyTrackAdded(track models.Track) error
+	Notifier
+	PreviewEmbed(event models.LiveEventType, tmpl *models.EmbedTemplate, mod interface{}) (*discordgo.MessageEmbed, error)
+}
+
+type FeedController interface {
+	GetFeed(modType models.ModType, filter models.FeedFilter, limit int) ([]models.FeedEntry, error)
+}
+
+type LiveController interface {
+	Publish(eventType models.LiveEventType, payload interface{})
+	Subscribe(role models.Role, cursor uint64) *LiveSubscription
+	Unsubscribe(subscription *LiveSubscription)
+}
+
+type NotificationController interface {
+	GetNotifications(status models.NotificationStatus, limit int) ([]models.Notification, error)
+	ReplayNotification(id uint) (models.Notification, error)
+}
+
+type WebhookController interface {
+	GetWebhooks() ([]models.WebhookEndpoint, error)
+	GetWebhook(id uint) (models.WebhookEndpoint, error)
+	AddWebhook(endpoint models.WebhookEndpoint) (models.WebhookEndpoint, error)
+	UpdateWebhook(endpoint models.WebhookEndpoint) (models.WebhookEndpoint, error)
+	DeleteWebhook(id uint) error
+	GetDeliveries(id uint, limit int) ([]models.WebhookDelivery, error)
+	Dispatch(event models.LiveEvent)
+}
+
+type ModUpdateController interface {
+	SubmitUpdate(update models.ModUpdate, username string, role models.Role) (models.ModUpdate, error)
+	GetPendingUpdates() ([]models.ModUpdate, error)
+	ApproveUpdate(id uint) (models.ModUpdate, error)
+	RejectUpdate(id uint) error
+}
+
+type ImageController interface {
+	UploadImage(modType models.ModType, modId uint, content []byte, image models.Image) (models.Image, error)
+	UploadSkinImage(skinId uint, content []byte) (models.Image, error)
+	UploadBrandLogo(name string, content []byte) (models.Image, error)
+	GetImages(modType models.ModType, modId uint) ([]models.Image, error)
+	AddImage(modType models.ModType, modId uint, image models.Image) (models.Image, error)
+	UpdateImage(modType models.ModType, modId uint, image models.Image) error
+	RemoveImage(modType models.ModType, modId uint, imageId uint) error
+	ReorderImages(modType models.ModType, modId uint, imageIds []uint) error
 }
@@ -3,25 +3,17 @@ module github.com/davide/ModRepository
 go 1.16
 
 require (
-	firebase.google.com/go v3.13.0+incompatible // indirect
-	firebase.google.com/go/v4 v4.6.1 // indirect
-	github.com/bwmarrin/discordgo v0.23.2 // indirect
-	github.com/golang-jwt/jwt v3.2.2+incompatible // indirect
+	firebase.google.com/go/v4 v4.6.1
+	github.com/bwmarrin/discordgo v0.27.1
+	github.com/go-sql-driver/mysql v1.6.0
+	github.com/golang-jwt/jwt v3.2.2+incompatible
 	github.com/gorilla/mux v1.8.0
-	github.com/gorilla/websocket v1.4.1
-	github.com/jackc/pgproto3/v2 v2.0.7 // indirect
-	github.com/jackc/pgx/v4 v4.11.0 // indirect
-	github.com/kr/text v0.2.0 // indirect
-	github.com/lib/pq v1.10.0
-	github.com/niemeyer/pretty v0.0.0-20200227124842-a10e7caefd8e // indirect
-	github.com/pkg/errors v0.9.1 // indirect
+	github.com/gorilla/websocket v1.4.2
+	github.com/graph-gophers/graphql-go v1.5.0
 	github.com/rs/cors v1.8.2
-	golang.org/x/crypto v0.0.0-20210322153248-0c34fe9e7dc2 // indirect
+	golang.org/x/crypto v0.0.0-20210421170649-83a5a9bb288b
 	golang.org/x/text v0.3.6 // indirect
-	google.golang.org/api v0.40.0 // indirect
-	gopkg.in/check.v1 v1.0.0-20200227125254-8fa46927fb4f // indirect
-	gopkg.in/yaml.v3 v3.0.0-20210107192922-496545a6307b // indirect
-	gorm.io/driver/mysql v1.1.3 // indirect
-	gorm.io/driver/postgres v1.0.8
+	google.golang.org/api v0.40.0
+	gorm.io/driver/mysql v1.1.3
 	gorm.io/gorm v1.21.12
 )
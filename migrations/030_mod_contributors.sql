@@ -0,0 +1,21 @@
+-- user-030: the contributors of every mod with their role, the main author stays in cars.id_author and tracks.id_author
+
+CREATE TABLE car_authors (
+    id        BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    car_id    BIGINT UNSIGNED NOT NULL,
+    author_id BIGINT UNSIGNED NOT NULL,
+    role      VARCHAR(32)     NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE KEY car_authors_car_author (car_id, author_id),
+    KEY car_authors_author (author_id)
+);
+
+CREATE TABLE track_authors (
+    id        BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    track_id  BIGINT UNSIGNED NOT NULL,
+    author_id BIGINT UNSIGNED NOT NULL,
+    role      VARCHAR(32)     NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE KEY track_authors_track_author (track_id, author_id),
+    KEY track_authors_author (author_id)
+);
@@ -0,0 +1,43 @@
+-- user-031: author accounts, the updates they submit and the changelog of the mods
+
+ALTER TABLE users ADD COLUMN id_author BIGINT UNSIGNED NULL;
+
+ALTER TABLE cars ADD COLUMN changelog TEXT NOT NULL DEFAULT ('');
+ALTER TABLE tracks ADD COLUMN changelog TEXT NOT NULL DEFAULT ('');
+
+CREATE TABLE mod_updates (
+    id            BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    mod_type      VARCHAR(16)     NOT NULL,
+    mod_id        BIGINT UNSIGNED NOT NULL,
+    version       VARCHAR(64)     NOT NULL DEFAULT '',
+    download_link VARCHAR(2048)   NOT NULL DEFAULT '',
+    changelog     TEXT            NOT NULL,
+    images        TEXT            NOT NULL,
+    submitted_by  VARCHAR(255)    NOT NULL,
+    status        VARCHAR(16)     NOT NULL,
+    created_at    DATETIME(3)     NOT NULL,
+    PRIMARY KEY (id),
+    KEY mod_updates_status (status, created_at)
+);
+
+-- the views the catalog is read from expose the changelog too
+
+CREATE OR REPLACE VIEW car_mods AS
+SELECT cars.id, cars.created_at, cars.updated_at, cars.rating, cars.version, cars.download_link, cars.source,
+       cars.premium, cars.personal, cars.id_author, cars.official, cars.changelog,
+       cars.model, cars.year, manufacturers.name AS brand, cars.transmission, cars.drivetrain, cars.bhp, cars.torque,
+       cars.weight, cars.top_speed, authors.name AS author, authors.link AS author_link,
+       nations.name AS nation, nations.code AS nation_code, nations.flag AS nation_flag, manufacturers.logo AS brand_logo
+FROM cars
+         JOIN manufacturers ON manufacturers.id = cars.id_brand
+         JOIN nations ON nations.id = manufacturers.id_nation
+         JOIN authors ON authors.id = cars.id_author;
+
+CREATE OR REPLACE VIEW track_mods AS
+SELECT tracks.id, tracks.created_at, tracks.updated_at, tracks.rating, tracks.version, tracks.download_link,
+       tracks.source, tracks.premium, tracks.personal, tracks.id_author, tracks.official, tracks.changelog,
+       tracks.name, tracks.location, tracks.year, nations.name AS nation, nations.code AS nation_code,
+       nations.flag AS nation_flag, authors.name AS author, authors.link AS author_link
+FROM tracks
+         JOIN nations ON nations.id = tracks.id_nation
+         JOIN authors ON authors.id = tracks.id_author;
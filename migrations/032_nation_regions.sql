@@ -0,0 +1,6 @@
+-- user-032: the continent and the region of the nations, filled by an admin with POST /nation/seed once deployed
+
+ALTER TABLE nations ADD COLUMN continent VARCHAR(16) NOT NULL DEFAULT '';
+ALTER TABLE nations ADD COLUMN region VARCHAR(64) NOT NULL DEFAULT '';
+CREATE INDEX nations_code ON nations (code);
+CREATE INDEX nations_continent ON nations (continent);
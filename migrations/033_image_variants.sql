@@ -0,0 +1,4 @@
+-- user-033: the resized variants of the uploaded images, a JSON object from the size to the url stored as text
+
+ALTER TABLE car_images ADD COLUMN variants TEXT NOT NULL DEFAULT ('');
+ALTER TABLE track_images ADD COLUMN variants TEXT NOT NULL DEFAULT ('');
@@ -0,0 +1,22 @@
+-- user-034: the order, the caption and the alternative text of the gallery images
+
+ALTER TABLE car_images ADD COLUMN position INT NOT NULL DEFAULT 0;
+ALTER TABLE car_images ADD COLUMN caption VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE car_images ADD COLUMN alt_text VARCHAR(255) NOT NULL DEFAULT '';
+CREATE INDEX car_images_position ON car_images (car_id, position);
+
+ALTER TABLE track_images ADD COLUMN position INT NOT NULL DEFAULT 0;
+ALTER TABLE track_images ADD COLUMN caption VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE track_images ADD COLUMN alt_text VARCHAR(255) NOT NULL DEFAULT '';
+CREATE INDEX track_images_position ON track_images (track_id, position);
+
+-- the existing galleries keep the order of insertion
+UPDATE car_images
+    JOIN (SELECT id, ROW_NUMBER() OVER (PARTITION BY car_id ORDER BY id) - 1 AS ordinal FROM car_images) ordered
+    ON ordered.id = car_images.id
+SET car_images.position = ordered.ordinal;
+
+UPDATE track_images
+    JOIN (SELECT id, ROW_NUMBER() OVER (PARTITION BY track_id ORDER BY id) - 1 AS ordinal FROM track_images) ordered
+    ON ordered.id = track_images.id
+SET track_images.position = ordered.ordinal;
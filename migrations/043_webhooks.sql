@@ -0,0 +1,27 @@
+-- user-043: the webhook subscriptions and the log of their deliveries
+
+CREATE TABLE webhook_endpoints (
+    id          BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    url         VARCHAR(2048)   NOT NULL,
+    description VARCHAR(255)    NOT NULL DEFAULT '',
+    events      VARCHAR(512)    NOT NULL,
+    active      BOOLEAN         NOT NULL DEFAULT TRUE,
+    secret      VARCHAR(128)    NOT NULL,
+    created_at  DATETIME(3)     NOT NULL,
+    PRIMARY KEY (id)
+);
+
+CREATE TABLE webhook_deliveries (
+    id          BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    endpoint_id BIGINT UNSIGNED NOT NULL,
+    event_id    CHAR(36)        NOT NULL,
+    event_type  VARCHAR(32)     NOT NULL,
+    attempt     INT             NOT NULL,
+    status_code INT             NOT NULL DEFAULT 0,
+    error       VARCHAR(1024)   NOT NULL DEFAULT '',
+    succeeded   BOOLEAN         NOT NULL,
+    duration_ms BIGINT          NOT NULL,
+    created_at  DATETIME(3)     NOT NULL,
+    PRIMARY KEY (id),
+    KEY webhook_deliveries_endpoint (endpoint_id, id)
+);
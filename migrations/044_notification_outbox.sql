@@ -0,0 +1,18 @@
+-- user-044: the outbox of the Discord and Firebase announcements, written in the transaction of the mod change
+
+CREATE TABLE notifications (
+    id              BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    channel         VARCHAR(32)     NOT NULL,
+    event           VARCHAR(32)     NOT NULL,
+    mod_type        VARCHAR(16)     NOT NULL,
+    mod_id          BIGINT UNSIGNED NOT NULL,
+    payload         MEDIUMTEXT      NOT NULL,
+    status          VARCHAR(16)     NOT NULL,
+    attempts        INT             NOT NULL DEFAULT 0,
+    last_error      VARCHAR(255)    NOT NULL DEFAULT '',
+    next_attempt_at DATETIME(3)     NOT NULL,
+    created_at      DATETIME(3)     NOT NULL,
+    delivered_at    DATETIME(3)     NULL,
+    PRIMARY KEY (id),
+    KEY notifications_due (status, next_attempt_at)
+);
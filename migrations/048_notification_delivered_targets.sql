@@ -0,0 +1,4 @@
+-- user-048: the targets an outbox notification already reached, the retries skip them. The targets are Discord
+-- channels and Firebase topic conditions, a comma separated list that can outgrow a varchar
+
+ALTER TABLE notifications ADD COLUMN delivered TEXT NOT NULL DEFAULT ('');
@@ -0,0 +1,12 @@
+-- user-050: the Firebase topics followed by every device of every user
+
+CREATE TABLE device_topics (
+    id         BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    username   VARCHAR(255)    NOT NULL,
+    token      VARCHAR(255)    NOT NULL,
+    topic      VARCHAR(255)    NOT NULL,
+    created_at DATETIME(3)     NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE KEY device_topics_subscription (username, token, topic),
+    KEY device_topics_token (token)
+);
@@ -5,3 +5,8 @@ type CarBrand struct {
 	Nation Nation `json:"nation"`
 	Logo   string `json:"logo"`
 }
+
+type BrandDetails struct {
+	CarBrand
+	Cars []Car `json:"cars"`
+}
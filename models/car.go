@@ -28,6 +28,12 @@ const (
 	Manual                  = "Manual"
 )
 
+var CarTypes = []CarType{EnduranceCar, OpenWheel, GT, Prototype, RallyCar, Street, Tuned, Touring, Vintage, StockCar}
+
+var Drivetrains = []Drivetrain{RearWheelDrive, FrontWheelDrive, AllWheelDrive}
+
+var Transmissions = []Transmission{Sequential, Manual}
+
 type Car struct {
 	Mod
 	Brand        CarBrand      `json:"brand"`
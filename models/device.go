@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+// DeviceToken is a device registered for the push notifications with the topics it follows
+type DeviceToken struct {
+	Token     string    `json:"token"`
+	Topics    []string  `json:"topics"`
+	CreatedAt time.Time `json:"createdAt"`
+}
@@ -0,0 +1,33 @@
+package models
+
+// EmbedTemplate is the Discord embed announcing an event, the strings are Go templates executed over the car
+// or the track
+type EmbedTemplate struct {
+	Event         LiveEventType        `json:"event"`
+	Title         string               `json:"title"`
+	Description   string               `json:"description"`
+	Color         int                  `json:"color"`
+	ImageUrl      string               `json:"imageUrl"`
+	AuthorName    string               `json:"authorName"`
+	AuthorIconUrl string               `json:"authorIconUrl"`
+	Fields        []EmbedFieldTemplate `json:"fields"`
+}
+
+// EmbedFieldTemplate is left out of the embed when Value renders empty
+type EmbedFieldTemplate struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"`
+}
+
+// DiscordRoute sends the announcements matching every filter set to a channel, a route without filters
+// receives them all
+type DiscordRoute struct {
+	Channel string `json:"channel"`
+	// Categories match the cars in any of them
+	Categories []CarType `json:"categories"`
+	// Tags match the tracks with any of them
+	Tags    []TrackTag      `json:"tags"`
+	Premium *bool           `json:"premium"`
+	Events  []LiveEventType `json:"events"`
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Domain errors returned by repositories and controllers, the handlers map them to the http status
+var (
+	ErrNotFound   = errors.New("not found")
+	ErrConflict   = errors.New("conflict")
+	ErrValidation = errors.New("validation failed")
+	ErrForbidden  = errors.New("forbidden")
+)
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError collects every invalid field of a model, it matches ErrValidation
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func NewValidationError(field string, message string) ValidationError {
+	return ValidationError{Fields: []FieldError{{Field: field, Message: message}}}
+}
+
+func (v ValidationError) Error() string {
+	messages := make([]string, 0, len(v.Fields))
+	for _, field := range v.Fields {
+		messages = append(messages, fmt.Sprintf("%v: %v", field.Field, field.Message))
+	}
+	return "invalid fields: " + strings.Join(messages, ", ")
+}
+
+func (v ValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// FeedEntry is a release or an update of a mod, as published in the feeds
+type FeedEntry struct {
+	Id         string    `json:"id"`
+	ModType    ModType   `json:"modType"`
+	ModId      uint      `json:"modId"`
+	Title      string    `json:"title"`
+	Action     Action    `json:"action"`
+	Version    string    `json:"version"`
+	Author     Author    `json:"author"`
+	ImageUrl   string    `json:"imageUrl"`
+	Link       string    `json:"link"`
+	Premium    bool      `json:"premium"`
+	Categories []string  `json:"categories"`
+	HappenedAt time.Time `json:"happenedAt"`
+}
+
+// FeedFilter keeps the entries matching every field set, Category and Brand only match cars, Tag only tracks
+type FeedFilter struct {
+	Category string
+	Tag      string
+	Brand    string
+	Author   string
+}
@@ -1,7 +1,11 @@
 package models
 
 type Image struct {
-	Id       uint   `json:"id"`
-	Url      string `json:"url"`
-	Favorite bool   `json:"favorite"`
+	Id       uint              `json:"id"`
+	Url      string            `json:"url"`
+	Favorite bool              `json:"favorite"`
+	Position int               `json:"position"`
+	Caption  string            `json:"caption"`
+	AltText  string            `json:"altText"`
+	Variants map[string]string `json:"variants,omitempty"`
 }
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+type LiveEventType string
+
+const (
+	CarAddedEvent            LiveEventType = "car_added"
+	CarUpdatedEvent          LiveEventType = "car_updated"
+	TrackAddedEvent          LiveEventType = "track_added"
+	TrackUpdatedEvent        LiveEventType = "track_updated"
+	SkinAddedEvent           LiveEventType = "skin_added"
+	ServerOnlineEvent        LiveEventType = "server_online"
+	ServerOfflineEvent       LiveEventType = "server_offline"
+	ServerLineupChangedEvent LiveEventType = "server_lineup_changed"
+	// ResyncEvent tells the client its cursor is too old, it should reload the catalog
+	ResyncEvent LiveEventType = "resync"
+)
+
+var LiveEventTypes = []LiveEventType{CarAddedEvent, CarUpdatedEvent, TrackAddedEvent, TrackUpdatedEvent, SkinAddedEvent, ServerOnlineEvent, ServerOfflineEvent, ServerLineupChangedEvent, ResyncEvent}
+
+// LiveEvent is a catalog change, Payload is the car, track, skin or server involved
+type LiveEvent struct {
+	Id        uint64        `json:"id"`
+	Type      LiveEventType `json:"type"`
+	CreatedAt time.Time     `json:"createdAt"`
+	Payload   interface{}   `json:"payload,omitempty"`
+}
@@ -3,21 +3,51 @@ package models
 import "time"
 
 type Mod struct {
-	Id           uint      `json:"id"`
-	DownloadLink string    `json:"downloadLink"`
-	Source       string    `json:"source"`
-	Premium      bool      `json:"premium"`
-	Personal     bool      `json:"personal"`
-	Images       []Image   `json:"images"`
-	Author       Author    `json:"author"`
-	CreatedAt    time.Time `json:"createdAt"`
-	UpdatedAt    time.Time `json:"updatedAt"`
-	Rating       uint      `json:"rating"`
-	Version      string    `json:"version"`
-	Official     bool      `json:"official"`
+	Id           uint          `json:"id"`
+	DownloadLink string        `json:"downloadLink"`
+	Source       string        `json:"source"`
+	Premium      bool          `json:"premium"`
+	Personal     bool          `json:"personal"`
+	Images       []Image       `json:"images"`
+	Author       Author        `json:"author"`
+	CreatedAt    time.Time     `json:"createdAt"`
+	UpdatedAt    time.Time     `json:"updatedAt"`
+	Rating       uint          `json:"rating"`
+	Version      string        `json:"version"`
+	Official     bool          `json:"official"`
+	Changelog    string        `json:"changelog"`
+	Contributors []Contributor `json:"contributors"`
 }
 
 type Author struct {
 	Name string `json:"name"`
 	Link string `json:"link"`
 }
+
+type ContributionRole string
+
+const (
+	ModelContribution      ContributionRole = "Model"
+	PhysicsContribution    ContributionRole = "Physics"
+	SoundsContribution     ContributionRole = "Sounds"
+	TexturesContribution   ContributionRole = "Textures"
+	ConversionContribution ContributionRole = "Conversion"
+	OtherContribution      ContributionRole = "Other"
+)
+
+var ContributionRoles = []ContributionRole{ModelContribution, PhysicsContribution, SoundsContribution, TexturesContribution, ConversionContribution, OtherContribution}
+
+type Contributor struct {
+	Author
+	Role ContributionRole `json:"role"`
+}
+
+type AuthorDetails struct {
+	Author
+	Cars          []Car      `json:"cars"`
+	Tracks        []Track    `json:"tracks"`
+	CarsCount     int        `json:"carsCount"`
+	TracksCount   int        `json:"tracksCount"`
+	LatestRelease *time.Time `json:"latestRelease"`
+	AverageRating float64    `json:"averageRating"`
+}
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+type ModType string
+
+const (
+	CarModType   ModType = "car"
+	TrackModType ModType = "track"
+)
+
+type UpdateStatus string
+
+const (
+	PendingUpdate  UpdateStatus = "Pending"
+	ApprovedUpdate UpdateStatus = "Approved"
+	RejectedUpdate UpdateStatus = "Rejected"
+)
+
+// ModUpdate is a release submitted by the mod author, images replace the current ones only when not empty so an
+// update can't wipe the gallery
+type ModUpdate struct {
+	Id           uint         `json:"id"`
+	ModType      ModType      `json:"modType"`
+	ModId        uint         `json:"modId"`
+	Version      string       `json:"version"`
+	DownloadLink string       `json:"downloadLink"`
+	Changelog    string       `json:"changelog"`
+	Images       []Image      `json:"images"`
+	SubmittedBy  string       `json:"submittedBy"`
+	Status       UpdateStatus `json:"status"`
+	CreatedAt    time.Time    `json:"createdAt"`
+}
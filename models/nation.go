@@ -1,7 +1,23 @@
 package models
 
+type Continent string
+
+const (
+	Europe       Continent = "Europe"
+	Africa       Continent = "Africa"
+	NorthAmerica Continent = "North America"
+	SouthAmerica Continent = "South America"
+	Asia         Continent = "Asia"
+	Oceania      Continent = "Oceania"
+	Antarctica   Continent = "Antarctica"
+)
+
+var Continents = []Continent{Europe, Africa, NorthAmerica, SouthAmerica, Asia, Oceania, Antarctica}
+
 type Nation struct {
-	Name string `json:"name"`
-	Code string `json:"code"`
-	Flag string `json:"flag"`
+	Name      string    `json:"name"`
+	Code      string    `json:"code"`
+	Flag      string    `json:"flag"`
+	Continent Continent `json:"continent"`
+	Region    string    `json:"region"`
 }
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type NotificationChannel string
+
+const (
+	DiscordChannel  NotificationChannel = "discord"
+	FirebaseChannel NotificationChannel = "firebase"
+)
+
+// NotifierRule enables a channel and picks what it announces
+type NotifierRule struct {
+	Channel NotificationChannel `json:"channel"`
+	Enabled bool                `json:"enabled"`
+	// Events are the mod events announced, all of them when empty
+	Events []LiveEventType `json:"events"`
+	// Official limits the channel to the official mods when true and to the community ones when false
+	Official *bool `json:"official"`
+}
+
+type NotificationStatus string
+
+const (
+	PendingNotification   NotificationStatus = "pending"
+	DeliveredNotification NotificationStatus = "delivered"
+	// DeadNotification gave up after the last attempt, an admin can replay it
+	DeadNotification NotificationStatus = "dead"
+)
+
+var NotificationStatuses = []NotificationStatus{PendingNotification, DeliveredNotification, DeadNotification}
+
+// Notification is an announcement waiting in the outbox, Payload is the car or track as it was when the change
+// was saved
+type Notification struct {
+	Id            uint                `json:"id"`
+	Channel       NotificationChannel `json:"channel"`
+	Event         LiveEventType       `json:"event"`
+	ModType       ModType             `json:"modType"`
+	ModId         uint                `json:"modId"`
+	Payload       json.RawMessage     `json:"payload"`
+	Status        NotificationStatus  `json:"status"`
+	Attempts      int                 `json:"attempts"`
+	LastError     string              `json:"lastError"`
+	NextAttemptAt time.Time           `json:"nextAttemptAt"`
+	CreatedAt     time.Time           `json:"createdAt"`
+	DeliveredAt   *time.Time          `json:"deliveredAt"`
+	// Delivered are the targets of the channel already reached, the retries skip them
+	Delivered []string `json:"delivered"`
+}
@@ -0,0 +1,18 @@
+package models
+
+type RelatedCars struct {
+	SameAuthor   []Car `json:"sameAuthor"`
+	SameBrand    []Car `json:"sameBrand"`
+	SameCategory []Car `json:"sameCategory"`
+}
+
+type RelatedTracks struct {
+	SameAuthor  []Track `json:"sameAuthor"`
+	SameNation  []Track `json:"sameNation"`
+	SimilarTags []Track `json:"similarTags"`
+}
+
+type SimilarCar struct {
+	Car   Car     `json:"car"`
+	Score float64 `json:"score"`
+}
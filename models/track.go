@@ -21,6 +21,10 @@ const (
 	AToB       LayoutType = "A to B"
 )
 
+var TrackTags = []TrackTag{RallyTrack, StreetCircuit, Fictional, Drift, Historic, Freeroam, Kart, LaserScan}
+
+var LayoutTypes = []LayoutType{RoadCourse, Oval, AToB}
+
 type Track struct {
 	Mod
 	Name     string     `json:"name"`
@@ -7,12 +7,15 @@ const (
 	Premium Role = "premium"
 	Base    Role = "base"
 	FSRTeam Role = "fsrteam"
+	// ModAuthor is bound to an author and can release updates of the mods credited to it
+	ModAuthor Role = "author"
 )
 
 type User struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
 	Role     Role   `json:"role"`
+	Author   string `json:"author"`
 }
 
 type Authentication struct {
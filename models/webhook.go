@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+type WebhookEventType string
+
+const (
+	CarAddedWebhook      WebhookEventType = "car.added"
+	CarUpdatedWebhook    WebhookEventType = "car.updated"
+	TrackAddedWebhook    WebhookEventType = "track.added"
+	TrackUpdatedWebhook  WebhookEventType = "track.updated"
+	SkinAddedWebhook     WebhookEventType = "skin.added"
+	ServerUpdatedWebhook WebhookEventType = "server.updated"
+)
+
+var WebhookEventTypes = []WebhookEventType{CarAddedWebhook, CarUpdatedWebhook, TrackAddedWebhook, TrackUpdatedWebhook, SkinAddedWebhook, ServerUpdatedWebhook}
+
+// WebhookEndpoint receives the events it subscribed to, signed with its secret
+type WebhookEndpoint struct {
+	Id          uint               `json:"id"`
+	Url         string             `json:"url"`
+	Description string             `json:"description"`
+	Events      []WebhookEventType `json:"events"`
+	Active      bool               `json:"active"`
+	// Secret is only returned when the endpoint is created
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// WebhookDelivery is an attempt to deliver an event to an endpoint
+type WebhookDelivery struct {
+	Id         uint             `json:"id"`
+	EndpointId uint             `json:"endpointId"`
+	EventId    string           `json:"eventId"`
+	EventType  WebhookEventType `json:"eventType"`
+	Attempt    int              `json:"attempt"`
+	StatusCode int              `json:"statusCode"`
+	Error      string           `json:"error"`
+	Succeeded  bool             `json:"succeeded"`
+	DurationMs int64            `json:"durationMs"`
+	CreatedAt  time.Time        `json:"createdAt"`
+}
+
+// WebhookPayload is the signed body sent to the endpoints, Id is a random uuid kept across the retries
+type WebhookPayload struct {
+	Id        string           `json:"id"`
+	Type      WebhookEventType `json:"type"`
+	CreatedAt time.Time        `json:"createdAt"`
+	Data      interface{}      `json:"data"`
+}
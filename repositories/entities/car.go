@@ -22,7 +22,8 @@ type CarMods struct {
 	NationCode   string
 	NationFlag   string
 	BrandLogo    string
-	Images       []CarImage `gorm:"foreignKey:CarId"`
+	Images       []CarImage       `gorm:"foreignKey:CarId"`
+	Contributors []CarContributor `gorm:"foreignKey:CarId"`
 }
 
 type Car struct {
@@ -37,7 +38,8 @@ type Car struct {
 	Torque       uint
 	Weight       uint
 	TopSpeed     uint
-	Images       []CarImage `gorm:"foreignKey:CarId"`
+	Images       []CarImage       `gorm:"foreignKey:CarId"`
+	Contributors []CarContributor `gorm:"foreignKey:CarId"`
 }
 
 type CarCategory struct {
@@ -80,11 +82,13 @@ func (c CarMods) ToEntity(premium bool, admin bool) models.Car {
 				Name: c.Author,
 				Link: c.AuthorLink,
 			},
-			CreatedAt: c.CreatedAt,
-			UpdatedAt: c.UpdatedAt,
-			Rating:    c.Rating,
-			Version:   c.Version,
-			Official:  c.Official,
+			CreatedAt:    c.CreatedAt,
+			UpdatedAt:    c.UpdatedAt,
+			Rating:       c.Rating,
+			Version:      c.Version,
+			Official:     c.Official,
+			Changelog:    c.Changelog,
+			Contributors: allCarContributorsToEntity(c.Contributors),
 		},
 		Brand: models.CarBrand{
 			Name: c.Brand,
@@ -116,7 +120,7 @@ func mapCategories(vs []CarCategory, f func(category CarCategory) models.CarCate
 	return vsm
 }
 
-func CarFromEntity(car models.Car, idBrand uint, idAuthor uint) Car {
+func CarFromEntity(car models.Car, idBrand uint, idAuthor uint, contributors []Contributor) Car {
 	return Car{
 		ModModel: ModModel{
 			Id:           car.Id,
@@ -128,6 +132,7 @@ func CarFromEntity(car models.Car, idBrand uint, idAuthor uint) Car {
 			Personal:     car.Personal,
 			IdAuthor:     idAuthor,
 			Official:     car.Official,
+			Changelog:    car.Changelog,
 		},
 
 		ModelName:    car.ModelName,
@@ -141,6 +146,7 @@ func CarFromEntity(car models.Car, idBrand uint, idAuthor uint) Car {
 		Weight:       car.Weight,
 		TopSpeed:     car.TopSpeed,
 		Images:       allCarImagesFromEntity(car.Images, car.Id),
+		Contributors: allCarContributorsFromEntity(contributors, car.Id),
 	}
 }
 
@@ -160,7 +166,7 @@ func allCarCategoryFromEntity(categories []models.CarCategory, id uint) []CarCat
 }
 
 func (i CarImage) toEntity() models.Image {
-	return i.Image.toEntity()
+	return i.Image.ToEntity()
 }
 func allCarImagesToEntity(dbImages []CarImage) []models.Image {
 	var images []models.Image
@@ -170,9 +176,9 @@ func allCarImagesToEntity(dbImages []CarImage) []models.Image {
 	return images
 }
 
-func carImageFromEntity(image models.Image, id uint) CarImage {
+func CarImageFromEntity(image models.Image, id uint) CarImage {
 	return CarImage{
-		Image: imageFromEntity(image),
+		Image: ImageFromEntity(image),
 		CarId: id,
 	}
 }
@@ -180,7 +186,7 @@ func carImageFromEntity(image models.Image, id uint) CarImage {
 func allCarImagesFromEntity(images []models.Image, id uint) []CarImage {
 	var dbImages []CarImage
 	for _, image := range images {
-		dbImages = append(dbImages, carImageFromEntity(image, id))
+		dbImages = append(dbImages, CarImageFromEntity(image, id))
 	}
 	return dbImages
 }
@@ -0,0 +1,79 @@
+package entities
+
+import (
+	"github.com/davide/ModRepository/models"
+)
+
+type Contributor struct {
+	Id       uint `gorm:"primaryKey"`
+	AuthorId uint
+	Role     string
+	Author   Author `gorm:"foreignKey:AuthorId"`
+}
+
+type CarContributor struct {
+	Contributor
+	CarId uint
+}
+
+type TrackContributor struct {
+	Contributor
+	TrackId uint
+}
+
+func (CarContributor) TableName() string {
+	return "car_authors"
+}
+
+func (TrackContributor) TableName() string {
+	return "track_authors"
+}
+
+func (c Contributor) toEntity() models.Contributor {
+	return models.Contributor{
+		Author: models.Author{
+			Name: c.Author.Name,
+			Link: c.Author.Link,
+		},
+		Role: models.ContributionRole(c.Role),
+	}
+}
+
+func ContributorFromEntity(contributor models.Contributor, authorId uint) Contributor {
+	return Contributor{
+		AuthorId: authorId,
+		Role:     string(contributor.Role),
+	}
+}
+
+func allCarContributorsToEntity(dbContributors []CarContributor) []models.Contributor {
+	var contributors []models.Contributor
+	for _, dbContributor := range dbContributors {
+		contributors = append(contributors, dbContributor.toEntity())
+	}
+	return contributors
+}
+
+func allTrackContributorsToEntity(dbContributors []TrackContributor) []models.Contributor {
+	var contributors []models.Contributor
+	for _, dbContributor := range dbContributors {
+		contributors = append(contributors, dbContributor.toEntity())
+	}
+	return contributors
+}
+
+func allCarContributorsFromEntity(contributors []Contributor, carId uint) []CarContributor {
+	var dbContributors []CarContributor
+	for _, contributor := range contributors {
+		dbContributors = append(dbContributors, CarContributor{Contributor: contributor, CarId: carId})
+	}
+	return dbContributors
+}
+
+func allTrackContributorsFromEntity(contributors []Contributor, trackId uint) []TrackContributor {
+	var dbContributors []TrackContributor
+	for _, contributor := range contributors {
+		dbContributors = append(dbContributors, TrackContributor{Contributor: contributor, TrackId: trackId})
+	}
+	return dbContributors
+}
@@ -0,0 +1,12 @@
+package entities
+
+import "time"
+
+// DeviceTopic is a topic followed by a device token of a user, the anonymous devices have no username
+type DeviceTopic struct {
+	Id        uint `gorm:"primaryKey"`
+	Username  string
+	Token     string
+	Topic     string
+	CreatedAt time.Time
+}
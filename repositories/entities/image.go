@@ -1,6 +1,7 @@
 package entities
 
 import (
+	"encoding/json"
 	"github.com/davide/ModRepository/models"
 )
 
@@ -8,20 +9,40 @@ type Image struct {
 	Id       uint `gorm:"primaryKey"`
 	Url      string
 	Favorite bool
+	Position int
+	Caption  string
+	AltText  string
+	Variants string
 }
 
-func (i Image) toEntity() models.Image {
+func (i Image) ToEntity() models.Image {
+	var variants map[string]string
+	if i.Variants != "" {
+		_ = json.Unmarshal([]byte(i.Variants), &variants)
+	}
 	return models.Image{
 		Id:       i.Id,
 		Url:      i.Url,
 		Favorite: i.Favorite,
+		Position: i.Position,
+		Caption:  i.Caption,
+		AltText:  i.AltText,
+		Variants: variants,
 	}
 }
 
-func imageFromEntity(img models.Image) Image {
+func ImageFromEntity(img models.Image) Image {
+	var variants []byte
+	if len(img.Variants) > 0 {
+		variants, _ = json.Marshal(img.Variants)
+	}
 	return Image{
 		Id:       img.Id,
 		Url:      img.Url,
 		Favorite: img.Favorite,
+		Position: img.Position,
+		Caption:  img.Caption,
+		AltText:  img.AltText,
+		Variants: string(variants),
 	}
 }
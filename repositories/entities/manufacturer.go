@@ -16,7 +16,7 @@ func (m Manufacturer) ToEntity(nation Nation) models.CarBrand {
 	return models.CarBrand{
 		Name:   m.Name,
 		Logo:   m.Logo,
-		Nation: models.Nation{Name: nation.Name, Code: nation.Code},
+		Nation: nation.ToEntity(),
 	}
 }
 
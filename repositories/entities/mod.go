@@ -14,4 +14,5 @@ type ModModel struct {
 	Personal     bool
 	IdAuthor     uint
 	Official     bool
+	Changelog    string
 }
@@ -0,0 +1,60 @@
+package entities
+
+import (
+	"encoding/json"
+	"github.com/davide/ModRepository/models"
+	"time"
+)
+
+type ModUpdate struct {
+	Id           uint `gorm:"primaryKey"`
+	ModType      string
+	ModId        uint
+	Version      string
+	DownloadLink string
+	Changelog    string
+	Images       string
+	SubmittedBy  string
+	Status       string
+	CreatedAt    time.Time
+}
+
+func (u ModUpdate) ToEntity() models.ModUpdate {
+	var images []models.Image
+	if u.Images != "" {
+		_ = json.Unmarshal([]byte(u.Images), &images)
+	}
+	return models.ModUpdate{
+		Id:           u.Id,
+		ModType:      models.ModType(u.ModType),
+		ModId:        u.ModId,
+		Version:      u.Version,
+		DownloadLink: u.DownloadLink,
+		Changelog:    u.Changelog,
+		Images:       images,
+		SubmittedBy:  u.SubmittedBy,
+		Status:       models.UpdateStatus(u.Status),
+		CreatedAt:    u.CreatedAt,
+	}
+}
+
+func ModUpdateFromEntity(update models.ModUpdate) (ModUpdate, error) {
+	var images []byte
+	if update.Images != nil {
+		var err error
+		if images, err = json.Marshal(update.Images); err != nil {
+			return ModUpdate{}, err
+		}
+	}
+	return ModUpdate{
+		Id:           update.Id,
+		ModType:      string(update.ModType),
+		ModId:        update.ModId,
+		Version:      update.Version,
+		DownloadLink: update.DownloadLink,
+		Changelog:    update.Changelog,
+		Images:       string(images),
+		SubmittedBy:  update.SubmittedBy,
+		Status:       string(update.Status),
+	}, nil
+}
@@ -5,18 +5,32 @@ import (
 )
 
 type Nation struct {
-	Id     uint `gorm:"primaryKey"`
-	Name   string
-	Code   string `gorm:"type:varchar(6)"`
-	Flag   string
-	Brands []Manufacturer `gorm:"foreignKey:IdNation"`
-	Tracks []Track        `gorm:"foreignKey:IdNation"`
+	Id        uint `gorm:"primaryKey"`
+	Name      string
+	Code      string `gorm:"type:varchar(6)"`
+	Flag      string
+	Continent string
+	Region    string
+	Brands    []Manufacturer `gorm:"foreignKey:IdNation"`
+	Tracks    []Track        `gorm:"foreignKey:IdNation"`
 }
 
 func NationFromEntity(nation models.Nation) Nation {
 	return Nation{
-		Name: nation.Name,
-		Code: nation.Code,
-		Flag: nation.Flag,
+		Name:      nation.Name,
+		Code:      nation.Code,
+		Flag:      nation.Flag,
+		Continent: string(nation.Continent),
+		Region:    nation.Region,
+	}
+}
+
+func (n Nation) ToEntity() models.Nation {
+	return models.Nation{
+		Name:      n.Name,
+		Code:      n.Code,
+		Flag:      n.Flag,
+		Continent: models.Continent(n.Continent),
+		Region:    n.Region,
 	}
 }
@@ -0,0 +1,63 @@
+package entities
+
+import (
+	"encoding/json"
+	"github.com/davide/ModRepository/models"
+	"strings"
+	"time"
+)
+
+type Notification struct {
+	Id            uint `gorm:"primaryKey"`
+	Channel       string
+	Event         string
+	ModType       string
+	ModId         uint
+	Payload       string
+	Status        string
+	Attempts      int
+	LastError     string
+	NextAttemptAt time.Time
+	CreatedAt     time.Time
+	DeliveredAt   *time.Time
+	Delivered     string
+}
+
+func (n Notification) ToEntity() models.Notification {
+	var delivered []string
+	if n.Delivered != "" {
+		delivered = strings.Split(n.Delivered, ",")
+	}
+	return models.Notification{
+		Id:            n.Id,
+		Channel:       models.NotificationChannel(n.Channel),
+		Event:         models.LiveEventType(n.Event),
+		ModType:       models.ModType(n.ModType),
+		ModId:         n.ModId,
+		Payload:       json.RawMessage(n.Payload),
+		Status:        models.NotificationStatus(n.Status),
+		Attempts:      n.Attempts,
+		LastError:     n.LastError,
+		NextAttemptAt: n.NextAttemptAt,
+		CreatedAt:     n.CreatedAt,
+		DeliveredAt:   n.DeliveredAt,
+		Delivered:     delivered,
+	}
+}
+
+func NotificationFromEntity(notification models.Notification) Notification {
+	return Notification{
+		Id:            notification.Id,
+		Channel:       string(notification.Channel),
+		Event:         string(notification.Event),
+		ModType:       string(notification.ModType),
+		ModId:         notification.ModId,
+		Payload:       string(notification.Payload),
+		Status:        string(notification.Status),
+		Attempts:      notification.Attempts,
+		LastError:     notification.LastError,
+		NextAttemptAt: notification.NextAttemptAt,
+		DeliveredAt:   notification.DeliveredAt,
+		Delivered:     strings.Join(notification.Delivered, ","),
+	}
+}
@@ -6,28 +6,30 @@ import (
 
 type Track struct {
 	ModModel
-	Name     string
-	Layouts  []Layout `gorm:"foreignKey:IdTrack"`
-	Location string
-	IdNation uint
-	Tags     []TrackTag `gorm:"foreignKey:IdTrack"`
-	Year     uint
-	Images   []TrackImage `gorm:"foreignKey:TrackId"`
+	Name         string
+	Layouts      []Layout `gorm:"foreignKey:IdTrack"`
+	Location     string
+	IdNation     uint
+	Tags         []TrackTag `gorm:"foreignKey:IdTrack"`
+	Year         uint
+	Images       []TrackImage       `gorm:"foreignKey:TrackId"`
+	Contributors []TrackContributor `gorm:"foreignKey:TrackId"`
 }
 
 type TrackMod struct {
 	ModModel
-	Name       string
-	Layouts    []Layout   `gorm:"foreignKey:IdTrack"`
-	Tags       []TrackTag `gorm:"foreignKey:IdTrack"`
-	Location   string
-	Nation     string
-	NationCode string
-	NationFlag string
-	Year       uint
-	Author     string
-	AuthorLink string
-	Images     []TrackImage `gorm:"foreignKey:TrackId"`
+	Name         string
+	Layouts      []Layout   `gorm:"foreignKey:IdTrack"`
+	Tags         []TrackTag `gorm:"foreignKey:IdTrack"`
+	Location     string
+	Nation       string
+	NationCode   string
+	NationFlag   string
+	Year         uint
+	Author       string
+	AuthorLink   string
+	Images       []TrackImage       `gorm:"foreignKey:TrackId"`
+	Contributors []TrackContributor `gorm:"foreignKey:TrackId"`
 }
 
 type TrackImage struct {
@@ -52,11 +54,13 @@ func (t TrackMod) ToEntity(premium bool, admin bool) models.Track {
 				Name: t.Author,
 				Link: t.AuthorLink,
 			},
-			Rating:    t.Rating,
-			CreatedAt: t.CreatedAt,
-			UpdatedAt: t.UpdatedAt,
-			Version:   t.Version,
-			Official:  t.Official,
+			Rating:       t.Rating,
+			CreatedAt:    t.CreatedAt,
+			UpdatedAt:    t.UpdatedAt,
+			Version:      t.Version,
+			Official:     t.Official,
+			Changelog:    t.Changelog,
+			Contributors: allTrackContributorsToEntity(t.Contributors),
 		},
 		Name: t.Name,
 		Layouts: mapLayouts(t.Layouts, func(layout Layout) models.Layout {
@@ -113,7 +117,7 @@ func mapTags(vs []TrackTag, f func(tag TrackTag) models.TrackTag) []models.Track
 	return vsm
 }
 
-func TrackFromEntity(track models.Track, idNation uint, idAuthor uint) Track {
+func TrackFromEntity(track models.Track, idNation uint, idAuthor uint, contributors []Contributor) Track {
 	var tags []TrackTag
 	for _, tag := range track.Tags {
 		tags = append(tags, TrackTag{Tag: string(tag)})
@@ -129,14 +133,16 @@ func TrackFromEntity(track models.Track, idNation uint, idAuthor uint) Track {
 			Personal:     track.Personal,
 			IdAuthor:     idAuthor,
 			Official:     track.Official,
+			Changelog:    track.Changelog,
 		},
-		Name:     track.Name,
-		Layouts:  allLayoutFromEntity(track.Layouts, idAuthor),
-		Location: track.Location,
-		IdNation: idNation,
-		Tags:     tags,
-		Year:     track.Year,
-		Images:   allTrackImagesFromEntity(track.Images, track.Id),
+		Name:         track.Name,
+		Layouts:      allLayoutFromEntity(track.Layouts, idAuthor),
+		Location:     track.Location,
+		IdNation:     idNation,
+		Tags:         tags,
+		Year:         track.Year,
+		Images:       allTrackImagesFromEntity(track.Images, track.Id),
+		Contributors: allTrackContributorsFromEntity(contributors, track.Id),
 	}
 }
 
@@ -158,7 +164,7 @@ func allLayoutFromEntity(layouts []models.Layout, track uint) []Layout {
 }
 
 func (i TrackImage) toEntity() models.Image {
-	return i.Image.toEntity()
+	return i.Image.ToEntity()
 }
 func allTrackImagesToEntity(dbImages []TrackImage) []models.Image {
 	var images []models.Image
@@ -168,9 +174,9 @@ func allTrackImagesToEntity(dbImages []TrackImage) []models.Image {
 	return images
 }
 
-func trackImageFromEntity(image models.Image, id uint) TrackImage {
+func TrackImageFromEntity(image models.Image, id uint) TrackImage {
 	return TrackImage{
-		Image:   imageFromEntity(image),
+		Image:   ImageFromEntity(image),
 		TrackId: id,
 	}
 }
@@ -178,7 +184,7 @@ func trackImageFromEntity(image models.Image, id uint) TrackImage {
 func allTrackImagesFromEntity(images []models.Image, id uint) []TrackImage {
 	var dbImages []TrackImage
 	for _, image := range images {
-		dbImages = append(dbImages, trackImageFromEntity(image, id))
+		dbImages = append(dbImages, TrackImageFromEntity(image, id))
 	}
 	return dbImages
 }
@@ -5,4 +5,5 @@ type User struct {
 	Password string
 	Role     string
 	Salt     string
+	IdAuthor *uint
 }
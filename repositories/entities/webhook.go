@@ -0,0 +1,91 @@
+package entities
+
+import (
+	"github.com/davide/ModRepository/models"
+	"strings"
+	"time"
+)
+
+type WebhookEndpoint struct {
+	Id          uint `gorm:"primaryKey"`
+	Url         string
+	Description string
+	Events      string
+	Active      bool
+	Secret      string
+	CreatedAt   time.Time
+}
+
+type WebhookDelivery struct {
+	Id         uint `gorm:"primaryKey"`
+	EndpointId uint
+	EventId    string
+	EventType  string
+	Attempt    int
+	StatusCode int
+	Error      string
+	Succeeded  bool
+	DurationMs int64
+	CreatedAt  time.Time
+}
+
+func (w WebhookEndpoint) ToEntity() models.WebhookEndpoint {
+	events := []models.WebhookEventType{}
+	for _, event := range strings.Split(w.Events, ",") {
+		if event != "" {
+			events = append(events, models.WebhookEventType(event))
+		}
+	}
+	return models.WebhookEndpoint{
+		Id:          w.Id,
+		Url:         w.Url,
+		Description: w.Description,
+		Events:      events,
+		Active:      w.Active,
+		Secret:      w.Secret,
+		CreatedAt:   w.CreatedAt,
+	}
+}
+
+func WebhookEndpointFromEntity(endpoint models.WebhookEndpoint) WebhookEndpoint {
+	var events []string
+	for _, event := range endpoint.Events {
+		events = append(events, string(event))
+	}
+	return WebhookEndpoint{
+		Id:          endpoint.Id,
+		Url:         endpoint.Url,
+		Description: endpoint.Description,
+		Events:      strings.Join(events, ","),
+		Active:      endpoint.Active,
+		Secret:      endpoint.Secret,
+	}
+}
+
+func (d WebhookDelivery) ToEntity() models.WebhookDelivery {
+	return models.WebhookDelivery{
+		Id:         d.Id,
+		EndpointId: d.EndpointId,
+		EventId:    d.EventId,
+		EventType:  models.WebhookEventType(d.EventType),
+		Attempt:    d.Attempt,
+		StatusCode: d.StatusCode,
+		Error:      d.Error,
+		Succeeded:  d.Succeeded,
+		DurationMs: d.DurationMs,
+		CreatedAt:  d.CreatedAt,
+	}
+}
+
+func WebhookDeliveryFromEntity(delivery models.WebhookDelivery) WebhookDelivery {
+	return WebhookDelivery{
+		EndpointId: delivery.EndpointId,
+		EventId:    delivery.EventId,
+		EventType:  string(delivery.EventType),
+		Attempt:    delivery.Attempt,
+		StatusCode: delivery.StatusCode,
+		Error:      delivery.Error,
+		Succeeded:  delivery.Succeeded,
+		DurationMs: delivery.DurationMs,
+	}
+}
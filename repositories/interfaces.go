@@ -2,33 +2,58 @@ package repositories
 
 import (
 	"github.com/davide/ModRepository/models"
+	"time"
 )
 
 type CarRepository interface {
-	InsertCar(car *models.Car) error
+	// InsertCar writes the notifications in the same transaction as the car
+	InsertCar(car *models.Car, notifications ...models.Notification) error
 	SelectAllCars(premium bool, admin bool) ([]models.Car, error)
 	SelectAllCarCategories() ([]models.CarCategory, error)
-	UpdateCar(car models.Car) (bool, error)
+	// UpdateCar writes the notifications with the car only when the version changed
+	UpdateCar(car models.Car, notifications ...models.Notification) (bool, error)
+	SelectCarById(id uint, premium bool, admin bool) (models.Car, error)
+	SelectCarsByIds(ids []uint, premium bool, admin bool) ([]models.Car, error)
+	SelectCarsByAuthor(author string, excludeId uint, limit int, premium bool, admin bool) ([]models.Car, error)
+	SearchCars(query string, limit int, premium bool, admin bool) ([]models.Car, error)
+	SelectCarsByBrand(brand string, excludeId uint, limit int, premium bool, admin bool) ([]models.Car, error)
+	SelectCarsByCategories(categories []models.CarCategory, year uint, excludeId uint, limit int, premium bool, admin bool) ([]models.Car, error)
+	DeleteCar(id uint) error
 }
 
 type LogRepository interface {
 	SelectAllTrackLogs() ([]models.TrackLog, error)
 	SelectAllCarLogs() ([]models.CarLog, error)
+	SelectCarLogs(filter models.FeedFilter, limit int) ([]models.CarLog, error)
+	SelectTrackLogs(filter models.FeedFilter, limit int) ([]models.TrackLog, error)
 }
 
 type TrackRepository interface {
 	SelectAllTracks(premium bool, admin bool) ([]models.Track, error)
-	InsertTrack(track *models.Track) error
-	UpdateTrack(track models.Track) (bool, error)
+	InsertTrack(track *models.Track, notifications ...models.Notification) error
+	UpdateTrack(track models.Track, notifications ...models.Notification) (bool, error)
+	SelectTrackById(id uint, premium bool, admin bool) (models.Track, error)
+	SelectTracksByIds(ids []uint, premium bool, admin bool) ([]models.Track, error)
+	SelectTracksByAuthor(author string, excludeId uint, limit int, premium bool, admin bool) ([]models.Track, error)
+	SearchTracks(query string, limit int, premium bool, admin bool) ([]models.Track, error)
+	SelectTracksByNation(nation string, excludeId uint, limit int, premium bool, admin bool) ([]models.Track, error)
+	SelectTracksByTags(tags []models.TrackTag, excludeId uint, limit int, premium bool, admin bool) ([]models.Track, error)
+	DeleteTrack(id uint) error
 }
 
 type NationRepository interface {
-	SelectAllBrandsNations() ([]models.Nation, error)
-	SelectAllTrackNations() ([]models.Nation, error)
+	SelectAllNations(continent models.Continent) ([]models.Nation, error)
+	SelectAllBrandsNations(continent models.Continent) ([]models.Nation, error)
+	SelectAllTrackNations(continent models.Continent) ([]models.Nation, error)
+	SeedNations(nations []models.Nation) error
+	MergeNations(source string, target string) error
 }
 
 type BrandRepository interface {
 	SelectAllBrands() ([]models.CarBrand, error)
+	SelectBrandByName(name string) (models.CarBrand, error)
+	UpdateBrand(name string, brand models.CarBrand) error
+	MergeBrands(source string, target string) error
 }
 
 type UserRepository interface {
@@ -41,18 +66,73 @@ type AuthorRepository interface {
 	SelectAllAuthors() ([]models.Author, error)
 	SelectAllCarAuthors() ([]models.Author, error)
 	SelectAllTrackAuthors() ([]models.Author, error)
+	SelectAuthorByName(name string) (models.Author, error)
+	UpdateAuthor(name string, author models.Author) error
+	MergeAuthors(source string, target string) error
 }
 
 type ServersRepository interface {
 	GetAllServers() ([]models.Server, error)
+	GetServerById(id uint) (models.Server, error)
 	UpdateServer(server models.Server) error
-	AddServer(server models.Server) error
+	AddServer(server *models.Server) error
 	DeleteServer(server models.Server) error
 }
 
 type SkinRepository interface {
 	SelectCarSkins(carId uint) ([]models.Skin, error)
 	GetAllSkins() ([]models.Skin, error)
-	AddSkin(skin models.Skin) error
+	SelectSkinById(id uint) (models.Skin, error)
+	AddSkin(skin *models.Skin) error
 	UpdateSkin(skin models.Skin) error
+	DeleteSkin(id uint) error
+}
+
+type ModUpdateRepository interface {
+	InsertModUpdate(update *models.ModUpdate) error
+	SelectModUpdateById(id uint) (models.ModUpdate, error)
+	SelectModUpdatesByStatus(status models.UpdateStatus) ([]models.ModUpdate, error)
+	UpdateModUpdateStatus(id uint, status models.UpdateStatus) error
+	ApplyModUpdate(update models.ModUpdate, notifications ...models.Notification) error
+	IsModCreditedTo(modType models.ModType, modId uint, username string) (bool, error)
+}
+
+type ImageStorage interface {
+	Save(key string, content []byte) (string, error)
+	Delete(url string) error
+}
+
+type ImageRepository interface {
+	SelectImages(modType models.ModType, modId uint) ([]models.Image, error)
+	InsertImage(modType models.ModType, modId uint, image *models.Image) error
+	UpdateImage(modType models.ModType, modId uint, image models.Image) error
+	DeleteImage(modType models.ModType, modId uint, imageId uint) (models.Image, error)
+	ReorderImages(modType models.ModType, modId uint, imageIds []uint) error
+	UpdateSkinImage(skinId uint, url string) error
+	UpdateBrandLogo(name string, url string) error
+}
+
+type WebhookRepository interface {
+	SelectAllWebhooks() ([]models.WebhookEndpoint, error)
+	SelectWebhookById(id uint) (models.WebhookEndpoint, error)
+	InsertWebhook(endpoint *models.WebhookEndpoint) error
+	UpdateWebhook(endpoint models.WebhookEndpoint) error
+	DeleteWebhook(id uint) error
+	InsertWebhookDelivery(delivery *models.WebhookDelivery) error
+	SelectWebhookDeliveries(endpointId uint, limit int) ([]models.WebhookDelivery, error)
+}
+
+type NotificationRepository interface {
+	SelectDueNotifications(now time.Time, limit int) ([]models.Notification, error)
+	SelectNotificationsByStatus(status models.NotificationStatus, limit int) ([]models.Notification, error)
+	SelectNotificationById(id uint) (models.Notification, error)
+	UpdateNotification(notification models.Notification) error
+}
+
+type DeviceTokenRepository interface {
+	InsertDeviceTopic(username string, token string, topic string) error
+	DeleteDeviceTopic(username string, token string, topic string) error
+	SelectDeviceTokensByUser(username string) ([]models.DeviceToken, error)
+	SelectAllDeviceTokens() ([]string, error)
+	DeleteDeviceTokens(tokens []string) error
 }
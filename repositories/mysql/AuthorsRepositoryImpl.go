@@ -1,7 +1,9 @@
 package mysql
 
 import (
+	"fmt"
 	"github.com/davide/ModRepository/models"
+	"github.com/davide/ModRepository/repositories/entities"
 	"gorm.io/gorm"
 )
 
@@ -20,7 +22,7 @@ func (a AuthorsRepositoryImpl) SelectAllAuthors() ([]models.Author, error) {
 
 func (a AuthorsRepositoryImpl) SelectAllCarAuthors() ([]models.Author, error) {
 	var authors []models.Author
-	if result := a.Db.Order("authors.name ASC").Distinct().Joins("join cars on id_author = authors.id").Find(&authors); result.Error != nil {
+	if result := a.Db.Order("authors.name ASC").Where("authors.id IN (?) OR authors.id IN (?)", a.Db.Table("cars").Select("id_author"), a.Db.Table("car_authors").Select("author_id")).Find(&authors); result.Error != nil {
 		return authors, result.Error
 	} else {
 		return authors, nil
@@ -29,9 +31,92 @@ func (a AuthorsRepositoryImpl) SelectAllCarAuthors() ([]models.Author, error) {
 
 func (a AuthorsRepositoryImpl) SelectAllTrackAuthors() ([]models.Author, error) {
 	var authors []models.Author
-	if result := a.Db.Order("authors.name ASC").Distinct().Joins("join tracks on id_author = authors.id").Find(&authors); result.Error != nil {
+	if result := a.Db.Order("authors.name ASC").Where("authors.id IN (?) OR authors.id IN (?)", a.Db.Table("tracks").Select("id_author"), a.Db.Table("track_authors").Select("author_id")).Find(&authors); result.Error != nil {
 		return authors, result.Error
 	} else {
 		return authors, nil
 	}
 }
+
+func (a AuthorsRepositoryImpl) SelectAuthorByName(name string) (models.Author, error) {
+	var author models.Author
+	if result := a.Db.Model(&entities.Author{}).Where("name = ?", name).Limit(1).Find(&author); result.Error != nil {
+		return models.Author{}, result.Error
+	} else if result.RowsAffected == 0 {
+		return models.Author{}, models.ErrNotFound
+	}
+	return author, nil
+}
+
+func (a AuthorsRepositoryImpl) UpdateAuthor(name string, author models.Author) error {
+	return a.Db.Transaction(func(tx *gorm.DB) error {
+		var dbAuthor entities.Author
+		if res := tx.Where("name = ?", name).Limit(1).Find(&dbAuthor); res.Error != nil {
+			return res.Error
+		} else if res.RowsAffected == 0 {
+			return models.ErrNotFound
+		}
+
+		if author.Name != "" && author.Name != dbAuthor.Name {
+			var count int64
+			if res := tx.Model(&entities.Author{}).Where("name = ?", author.Name).Count(&count); res.Error != nil {
+				return res.Error
+			} else if count > 0 {
+				return fmt.Errorf("%w: an author with this name already exists, merge them instead", models.ErrConflict)
+			}
+			dbAuthor.Name = author.Name
+		}
+
+		if author.Link != "" {
+			dbAuthor.Link = author.Link
+		}
+
+		if res := tx.Model(&dbAuthor).Select("Name", "Link").Updates(&dbAuthor); res.Error != nil {
+			return res.Error
+		}
+		return nil
+	})
+}
+
+func (a AuthorsRepositoryImpl) MergeAuthors(source string, target string) error {
+	if source == target {
+		return models.NewValidationError("target", "cannot merge an author into itself")
+	}
+	return a.Db.Transaction(func(tx *gorm.DB) error {
+		var sourceAuthor, targetAuthor entities.Author
+		if res := tx.Where("name = ?", source).Limit(1).Find(&sourceAuthor); res.Error != nil {
+			return res.Error
+		} else if res.RowsAffected == 0 {
+			return models.ErrNotFound
+		}
+		if res := tx.Where("name = ?", target).Limit(1).Find(&targetAuthor); res.Error != nil {
+			return res.Error
+		} else if res.RowsAffected == 0 {
+			return models.ErrNotFound
+		}
+
+		if res := tx.Model(&entities.Car{}).Where("id_author = ?", sourceAuthor.Id).Update("id_author", targetAuthor.Id); res.Error != nil {
+			return res.Error
+		}
+		if res := tx.Model(&entities.Track{}).Where("id_author = ?", sourceAuthor.Id).Update("id_author", targetAuthor.Id); res.Error != nil {
+			return res.Error
+		}
+		if res := tx.Model(&entities.CarContributor{}).Where("author_id = ?", sourceAuthor.Id).Update("author_id", targetAuthor.Id); res.Error != nil {
+			return res.Error
+		}
+		if res := tx.Model(&entities.TrackContributor{}).Where("author_id = ?", sourceAuthor.Id).Update("author_id", targetAuthor.Id); res.Error != nil {
+			return res.Error
+		}
+
+		if targetAuthor.Link == "" && sourceAuthor.Link != "" {
+			if res := tx.Model(&targetAuthor).Update("link", sourceAuthor.Link); res.Error != nil {
+				return res.Error
+			}
+		}
+
+		if res := tx.Delete(&sourceAuthor); res.Error != nil {
+			return res.Error
+		}
+		return nil
+	})
+}
@@ -1,6 +1,7 @@
 package mysql
 
 import (
+	"fmt"
 	models2 "github.com/davide/ModRepository/models"
 	"github.com/davide/ModRepository/repositories/entities"
 	"gorm.io/gorm"
@@ -16,19 +17,119 @@ func (b BrandRepositoryImpl) SelectAllBrands() ([]models2.CarBrand, error) {
 	})
 }
 
+func (b BrandRepositoryImpl) SelectBrandByName(name string) (models2.CarBrand, error) {
+	if brands, err := b.selectBrandsWithQuery(func(brands *[]entities.Manufacturer) *gorm.DB {
+		return b.Db.Where("name = ?", name).Find(&brands)
+	}); err != nil {
+		return models2.CarBrand{}, err
+	} else if len(brands) == 0 {
+		return models2.CarBrand{}, models2.ErrNotFound
+	} else {
+		return brands[0], nil
+	}
+}
+
+func (b BrandRepositoryImpl) UpdateBrand(name string, brand models2.CarBrand) error {
+	return b.Db.Transaction(func(tx *gorm.DB) error {
+		var dbBrand entities.Manufacturer
+		if res := tx.Where("name = ?", name).Find(&dbBrand); res.Error != nil {
+			return res.Error
+		} else if res.RowsAffected == 0 {
+			return models2.ErrNotFound
+		}
+
+		if brand.Name != "" && brand.Name != dbBrand.Name {
+			var count int64
+			if res := tx.Model(&entities.Manufacturer{}).Where("name = ?", brand.Name).Count(&count); res.Error != nil {
+				return res.Error
+			} else if count > 0 {
+				return fmt.Errorf("%w: a brand with this name already exists, merge them instead", models2.ErrConflict)
+			}
+			dbBrand.Name = brand.Name
+		}
+
+		if brand.Nation.Name != "" {
+			dbNation, err := firstOrCreateNation(tx, brand.Nation)
+			if err != nil {
+				return err
+			}
+			dbBrand.IdNation = dbNation.Id
+		}
+
+		if brand.Logo != "" {
+			dbBrand.Logo = brand.Logo
+		}
+
+		if res := tx.Model(&dbBrand).Select("Name", "Logo", "IdNation").Updates(&dbBrand); res.Error != nil {
+			return res.Error
+		}
+		return nil
+	})
+}
+
+func (b BrandRepositoryImpl) MergeBrands(source string, target string) error {
+	if source == target {
+		return models2.NewValidationError("target", "cannot merge a brand into itself")
+	}
+	return b.Db.Transaction(func(tx *gorm.DB) error {
+		var sourceBrand, targetBrand entities.Manufacturer
+		if res := tx.Where("name = ?", source).Find(&sourceBrand); res.Error != nil {
+			return res.Error
+		} else if res.RowsAffected == 0 {
+			return models2.ErrNotFound
+		}
+		if res := tx.Where("name = ?", target).Find(&targetBrand); res.Error != nil {
+			return res.Error
+		} else if res.RowsAffected == 0 {
+			return models2.ErrNotFound
+		}
+
+		if res := tx.Model(&entities.Car{}).Where("id_brand = ?", sourceBrand.Id).Update("id_brand", targetBrand.Id); res.Error != nil {
+			return res.Error
+		}
+
+		if targetBrand.Logo == "" && sourceBrand.Logo != "" {
+			if res := tx.Model(&targetBrand).Update("logo", sourceBrand.Logo); res.Error != nil {
+				return res.Error
+			}
+		}
+
+		if res := tx.Delete(&sourceBrand); res.Error != nil {
+			return res.Error
+		}
+		return nil
+	})
+}
+
 func (b BrandRepositoryImpl) selectBrandsWithQuery(query selectFromBrandsQuery) ([]models2.CarBrand, error) {
 	var dbBrands []entities.Manufacturer
 	var brands []models2.CarBrand
 	if result := query(&dbBrands); result.Error != nil {
 		return nil, result.Error
 	}
+
+	// the nations are loaded with a single query instead of one per brand
+	nationIds := make([]uint, 0, len(dbBrands))
+	for _, dbBrand := range dbBrands {
+		nationIds = append(nationIds, dbBrand.IdNation)
+	}
+	nations := map[uint]entities.Nation{}
+	if len(nationIds) > 0 {
+		var dbNations []entities.Nation
+		if res := b.Db.Where("id IN ?", nationIds).Find(&dbNations); res.Error != nil {
+			return nil, res.Error
+		}
+		for _, dbNation := range dbNations {
+			nations[dbNation.Id] = dbNation
+		}
+	}
+
 	for _, dbBrand := range dbBrands {
-		nation := entities.Nation{Id: dbBrand.IdNation}
-		if res2 := b.Db.Find(&nation); res2.Error != nil {
-			return nil, res2.Error
-		} else {
-			brands = append(brands, dbBrand.ToEntity(nation))
+		nation, ok := nations[dbBrand.IdNation]
+		if !ok {
+			nation = entities.Nation{Id: dbBrand.IdNation}
 		}
+		brands = append(brands, dbBrand.ToEntity(nation))
 	}
 	return brands, nil
 }
@@ -1,7 +1,6 @@
 package mysql
 
 import (
-	"errors"
 	models2 "github.com/davide/ModRepository/models"
 	"github.com/davide/ModRepository/repositories/entities"
 	"gorm.io/gorm"
@@ -16,15 +15,13 @@ type carsQuery func() *gorm.DB
 type selectFromBrandsQuery func(*[]entities.Manufacturer) *gorm.DB
 
 func (c CarRepositoryImpl) selectCarsWithQuery(carsQuery carsQuery, premium bool, admin bool) ([]models2.Car, error) {
-	var cars []models2.Car
 	var dbCars []entities.CarMods
 
 	if result := carsQuery().Find(&dbCars); result.Error != nil {
 		return nil, result.Error
-	} else if result.RowsAffected == 0 {
-		return nil, errors.New("not found")
 	}
 
+	cars := make([]models2.Car, 0, len(dbCars))
 	for _, dbCar := range dbCars {
 		cars = append(cars, dbCar.ToEntity(premium, admin))
 	}
@@ -32,14 +29,9 @@ func (c CarRepositoryImpl) selectCarsWithQuery(carsQuery carsQuery, premium bool
 }
 
 func (c CarRepositoryImpl) preInsertionQueries(car models2.Car) (entities.Car, error) {
-	dbNation := entities.NationFromEntity(car.Brand.Nation)
-
-	if res := c.Db.Clauses(clause.OnConflict{DoNothing: true}).Create(&dbNation); res.Error != nil {
-		return entities.Car{}, res.Error
-	}
-
-	if res := c.Db.Where("name = ?", dbNation.Name).First(&dbNation); res.Error != nil {
-		return entities.Car{}, res.Error
+	dbNation, err := firstOrCreateNation(c.Db, car.Brand.Nation)
+	if err != nil {
+		return entities.Car{}, err
 	}
 
 	dbBrand := entities.ManufacturerFromEntity(car.Brand, dbNation.Id)
@@ -54,17 +46,17 @@ func (c CarRepositoryImpl) preInsertionQueries(car models2.Car) (entities.Car, e
 
 	println(dbBrand.Id)
 
-	dbAuthor := entities.AuthorFromEntity(car.Author)
-
-	if res := c.Db.Clauses(clause.OnConflict{DoNothing: true}).Create(&dbAuthor); res.Error != nil {
-		return entities.Car{}, res.Error
+	dbAuthor, err := firstOrCreateAuthor(c.Db, car.Author)
+	if err != nil {
+		return entities.Car{}, err
 	}
 
-	if res := c.Db.Where("name = ?", dbAuthor.Name).First(&dbAuthor); res.Error != nil {
-		return entities.Car{}, res.Error
+	contributors, err := contributorsFromEntity(c.Db, car.Contributors)
+	if err != nil {
+		return entities.Car{}, err
 	}
 
-	return entities.CarFromEntity(car, dbBrand.Id, dbAuthor.Id), nil
+	return entities.CarFromEntity(car, dbBrand.Id, dbAuthor.Id, contributors), nil
 }
 
 func (c CarRepositoryImpl) SelectAllCarCategories() ([]models2.CarCategory, error) {
@@ -82,19 +74,40 @@ func (c CarRepositoryImpl) SelectAllCarCategories() ([]models2.CarCategory, erro
 	}, nil
 }
 
-func (c CarRepositoryImpl) InsertCar(car *models2.Car) error {
+func (c CarRepositoryImpl) InsertCar(car *models2.Car, notifications ...models2.Notification) error {
+	return c.Db.Transaction(func(tx *gorm.DB) error {
+		if err := (CarRepositoryImpl{Db: tx}).insertCar(car); err != nil {
+			return err
+		}
+		return insertNotifications(tx, models2.CarModType, car.Id, *car, notifications)
+	})
+}
+
+func (c CarRepositoryImpl) UpdateCar(car models2.Car, notifications ...models2.Notification) (bool, error) {
+	var versionChange bool
+	err := c.Db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if versionChange, err = (CarRepositoryImpl{Db: tx}).updateCar(car); err != nil || !versionChange {
+			return err
+		}
+		return insertNotifications(tx, models2.CarModType, car.Id, car, notifications)
+	})
+	return versionChange, err
+}
+
+func (c CarRepositoryImpl) insertCar(car *models2.Car) error {
 	if dbCar, err := c.preInsertionQueries(*car); err != nil {
 		return err
 	} else {
 		if res := c.Db.Create(&dbCar); res.Error != nil {
-			return res.Error
+			return translateError(res.Error)
 		}
 		car.Id = dbCar.Id
 	}
 	return nil
 }
 
-func (c CarRepositoryImpl) UpdateCar(car models2.Car) (bool, error) {
+func (c CarRepositoryImpl) updateCar(car models2.Car) (bool, error) {
 	if dbCar, err := c.preInsertionQueries(car); err != nil {
 		return false, err
 	} else {
@@ -102,11 +115,11 @@ func (c CarRepositoryImpl) UpdateCar(car models2.Car) (bool, error) {
 		actualCar := dbCar
 
 		if res := c.Db.First(&actualCar, car.Id); res.Error != nil {
-			return false, res.Error
+			return false, translateError(res.Error)
 		}
 
 		if res := c.Db.Model(&dbCar).Clauses(clause.Returning{Columns: []clause.Column{{Name: "version"}}}).Select("*").Omit("UpdatedAt", "CreatedAt").Updates(&dbCar); res.Error != nil {
-			return false, res.Error
+			return false, translateError(res.Error)
 		}
 
 		if res := c.Db.Where("car_id = ?", dbCar.Id).Delete(&entities.CarCategory{}); res.Error != nil {
@@ -117,11 +130,20 @@ func (c CarRepositoryImpl) UpdateCar(car models2.Car) (bool, error) {
 			return false, res
 		}
 
-		if res := c.Db.Where("car_id = ?", dbCar.Id).Delete(&entities.CarImage{}); res.Error != nil {
+		var images []entities.Image
+		for _, image := range dbCar.Images {
+			images = append(images, image.Image)
+		}
+
+		if err := syncImages(c.Db, models2.CarModType, dbCar.Id, images); err != nil {
+			return false, err
+		}
+
+		if res := c.Db.Where("car_id = ?", dbCar.Id).Delete(&entities.CarContributor{}); res.Error != nil {
 			return false, res.Error
 		}
 
-		if res := c.Db.Model(&dbCar).Association("Images").Append(dbCar.Images); res != nil {
+		if res := c.Db.Model(&dbCar).Association("Contributors").Append(dbCar.Contributors); res != nil {
 			return false, res
 		}
 
@@ -131,8 +153,87 @@ func (c CarRepositoryImpl) UpdateCar(car models2.Car) (bool, error) {
 
 }
 
+// DeleteCar removes the car with its categories, images, credits, skins and server lineup entries
+func (c CarRepositoryImpl) DeleteCar(id uint) error {
+	return c.Db.Transaction(func(tx *gorm.DB) error {
+		for _, child := range []interface{}{&entities.CarCategory{}, &entities.CarImage{}, &entities.CarContributor{}, &models2.Skin{}} {
+			if res := tx.Where("car_id = ?", id).Delete(child); res.Error != nil {
+				return res.Error
+			}
+		}
+		if res := tx.Exec("DELETE FROM server_cars WHERE car_id = ?", id); res.Error != nil {
+			return res.Error
+		}
+		if res := tx.Delete(&entities.Car{}, id); res.Error != nil {
+			return translateError(res.Error)
+		} else if res.RowsAffected == 0 {
+			return models2.ErrNotFound
+		}
+		return nil
+	})
+}
+
 func (c CarRepositoryImpl) SelectAllCars(premium bool, admin bool) ([]models2.Car, error) {
 	return c.selectCarsWithQuery(func() *gorm.DB {
-		return c.Db.Order("concat(brand,' ',model) ASC").Preload("Categories").Preload("Images")
+		return c.Db.Order("concat(brand,' ',model) ASC").Preload("Categories").Preload("Images", orderedImages).Preload("Contributors.Author")
+	}, premium, admin)
+}
+
+func (c CarRepositoryImpl) SelectCarById(id uint, premium bool, admin bool) (models2.Car, error) {
+	if cars, err := c.selectCarsWithQuery(func() *gorm.DB {
+		return c.Db.Where("id = ?", id).Preload("Categories").Preload("Images", orderedImages).Preload("Contributors.Author")
+	}, premium, admin); err != nil {
+		return models2.Car{}, err
+	} else if len(cars) == 0 {
+		return models2.Car{}, models2.ErrNotFound
+	} else {
+		return cars[0], nil
+	}
+}
+
+func (c CarRepositoryImpl) SelectCarsByIds(ids []uint, premium bool, admin bool) ([]models2.Car, error) {
+	if len(ids) == 0 {
+		return []models2.Car{}, nil
+	}
+	return c.selectCarsWithQuery(func() *gorm.DB {
+		return c.Db.Where("id IN ?", ids).Preload("Categories").Preload("Images", orderedImages).Preload("Contributors.Author")
+	}, premium, admin)
+}
+
+func (c CarRepositoryImpl) SelectCarsByAuthor(author string, excludeId uint, limit int, premium bool, admin bool) ([]models2.Car, error) {
+	return c.selectCarsWithQuery(func() *gorm.DB {
+		return c.Db.Where("(author = ? OR id IN (?)) AND id <> ?", author, c.Db.Table("car_authors").Select("car_id").Joins("join authors on authors.id = car_authors.author_id").Where("authors.name = ?", author), excludeId).Order("created_at DESC").Limit(limit).Preload("Categories").Preload("Images", orderedImages).Preload("Contributors.Author")
+	}, premium, admin)
+}
+
+// SearchCars returns the first cars by name whose brand, model and year contain every word of the query
+func (c CarRepositoryImpl) SearchCars(query string, limit int, premium bool, admin bool) ([]models2.Car, error) {
+	return c.selectCarsWithQuery(func() *gorm.DB {
+		return matchingWords(c.Db, "concat(brand,' ',model,' ',year)", query).Order("concat(brand,' ',model) ASC").Limit(limit).Preload("Categories").Preload("Images", orderedImages).Preload("Contributors.Author")
+	}, premium, admin)
+}
+
+func (c CarRepositoryImpl) SelectCarsByBrand(brand string, excludeId uint, limit int, premium bool, admin bool) ([]models2.Car, error) {
+	return c.selectCarsWithQuery(func() *gorm.DB {
+		return c.Db.Where("brand = ? AND id <> ?", brand, excludeId).Order("year DESC").Limit(limit).Preload("Categories").Preload("Images", orderedImages).Preload("Contributors.Author")
+	}, premium, admin)
+}
+
+func (c CarRepositoryImpl) SelectCarsByCategories(categories []models2.CarCategory, year uint, excludeId uint, limit int, premium bool, admin bool) ([]models2.Car, error) {
+	var names []string
+	for _, category := range categories {
+		names = append(names, string(category.Name))
+	}
+	if len(names) == 0 {
+		return []models2.Car{}, nil
+	}
+	return c.selectCarsWithQuery(func() *gorm.DB {
+		return c.Db.Where("id <> ?", excludeId).
+			Where("id IN (?)", c.Db.Table("car_categories").Select("car_id").Where("category IN ?", names)).
+			Clauses(clause.OrderBy{Expression: clause.Expr{
+				SQL:  "(SELECT COUNT(*) FROM car_categories WHERE car_categories.car_id = car_mods.id AND car_categories.category IN ?) DESC, ABS(year - ?) ASC",
+				Vars: []interface{}{names, year},
+			}}).
+			Limit(limit).Preload("Categories").Preload("Images", orderedImages).Preload("Contributors.Author")
 	}, premium, admin)
 }
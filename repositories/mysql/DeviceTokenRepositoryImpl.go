@@ -0,0 +1,61 @@
+package mysql
+
+import (
+	models2 "github.com/davide/ModRepository/models"
+	"github.com/davide/ModRepository/repositories/entities"
+	"gorm.io/gorm"
+)
+
+type DeviceTokenRepositoryImpl struct {
+	Db *gorm.DB
+}
+
+// InsertDeviceTopic records the subscription, subscribing twice to the same topic keeps the first record
+func (d DeviceTokenRepositoryImpl) InsertDeviceTopic(username string, token string, topic string) error {
+	dbTopic := entities.DeviceTopic{Username: username, Token: token, Topic: topic}
+	return d.Db.Where(entities.DeviceTopic{Username: username, Token: token, Topic: topic}).FirstOrCreate(&dbTopic).Error
+}
+
+func (d DeviceTokenRepositoryImpl) DeleteDeviceTopic(username string, token string, topic string) error {
+	if res := d.Db.Where("username = ? AND token = ? AND topic = ?", username, token, topic).Delete(&entities.DeviceTopic{}); res.Error != nil {
+		return res.Error
+	} else if res.RowsAffected == 0 {
+		return models2.ErrNotFound
+	}
+	return nil
+}
+
+// SelectDeviceTokensByUser groups the topics by device, the devices registered first come first
+func (d DeviceTokenRepositoryImpl) SelectDeviceTokensByUser(username string) ([]models2.DeviceToken, error) {
+	var dbTopics []entities.DeviceTopic
+	if res := d.Db.Where("username = ?", username).Order("id ASC").Find(&dbTopics); res.Error != nil {
+		return nil, res.Error
+	}
+	devices := make([]models2.DeviceToken, 0)
+	indexes := map[string]int{}
+	for _, dbTopic := range dbTopics {
+		index, ok := indexes[dbTopic.Token]
+		if !ok {
+			index = len(devices)
+			indexes[dbTopic.Token] = index
+			devices = append(devices, models2.DeviceToken{Token: dbTopic.Token, CreatedAt: dbTopic.CreatedAt})
+		}
+		devices[index].Topics = append(devices[index].Topics, dbTopic.Topic)
+	}
+	return devices, nil
+}
+
+func (d DeviceTokenRepositoryImpl) SelectAllDeviceTokens() ([]string, error) {
+	var tokens []string
+	if res := d.Db.Model(&entities.DeviceTopic{}).Distinct().Pluck("token", &tokens); res.Error != nil {
+		return nil, res.Error
+	}
+	return tokens, nil
+}
+
+func (d DeviceTokenRepositoryImpl) DeleteDeviceTokens(tokens []string) error {
+	if len(tokens) == 0 {
+		return nil
+	}
+	return d.Db.Where("token IN ?", tokens).Delete(&entities.DeviceTopic{}).Error
+}
@@ -0,0 +1,254 @@
+package mysql
+
+import (
+	"github.com/davide/ModRepository/models"
+	"github.com/davide/ModRepository/repositories/entities"
+	"gorm.io/gorm"
+)
+
+type ImageRepositoryImpl struct {
+	Db *gorm.DB
+}
+
+// imagesTable returns the table holding the images of the mod type and its mod column
+func imagesTable(modType models.ModType) (string, string, error) {
+	switch modType {
+	case models.CarModType:
+		return "car_images", "car_id", nil
+	case models.TrackModType:
+		return "track_images", "track_id", nil
+	}
+	return "", "", models.NewValidationError("modType", "unknown mod type")
+}
+
+func orderedImages(db *gorm.DB) *gorm.DB {
+	return db.Order("position ASC, id ASC")
+}
+
+// syncImages updates the images that are still there, inserts the new ones and deletes the missing ones,
+// so the ids are kept across updates
+func syncImages(tx *gorm.DB, modType models.ModType, modId uint, images []entities.Image) error {
+	table, column, err := imagesTable(modType)
+	if err != nil {
+		return err
+	}
+
+	keep := []uint{0}
+	for _, image := range images {
+		if image.Id != 0 {
+			keep = append(keep, image.Id)
+		}
+	}
+	if res := tx.Table(table).Where(column+" = ? AND id NOT IN ?", modId, keep).Delete(&entities.Image{}); res.Error != nil {
+		return res.Error
+	}
+
+	for _, image := range images {
+		if image.Id != 0 {
+			if res := tx.Table(table).Where("id = ? AND "+column+" = ?", image.Id, modId).Select("Url", "Favorite", "Position", "Caption", "AltText", "Variants").Updates(&image); res.Error != nil {
+				return res.Error
+			}
+			continue
+		}
+		if err := insertImage(tx, modType, modId, &image); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func insertImage(tx *gorm.DB, modType models.ModType, modId uint, image *entities.Image) error {
+	switch modType {
+	case models.CarModType:
+		dbImage := entities.CarImage{Image: *image, CarId: modId}
+		if res := tx.Create(&dbImage); res.Error != nil {
+			return res.Error
+		}
+		image.Id = dbImage.Id
+	case models.TrackModType:
+		dbImage := entities.TrackImage{Image: *image, TrackId: modId}
+		if res := tx.Create(&dbImage); res.Error != nil {
+			return res.Error
+		}
+		image.Id = dbImage.Id
+	default:
+		return models.NewValidationError("modType", "unknown mod type")
+	}
+	return nil
+}
+
+// enforceSingleFavorite makes favoriteId the only favorite, with 0 it only fixes galleries with none or many
+func enforceSingleFavorite(tx *gorm.DB, table string, column string, modId uint, favoriteId uint) error {
+	if favoriteId == 0 {
+		var favorites []entities.Image
+		if res := tx.Table(table).Where(column+" = ? AND favorite = ?", modId, true).Scopes(orderedImages).Find(&favorites); res.Error != nil {
+			return res.Error
+		}
+		if len(favorites) == 1 {
+			return nil
+		}
+		if len(favorites) > 1 {
+			favoriteId = favorites[0].Id
+		} else {
+			var first entities.Image
+			if res := tx.Table(table).Where(column+" = ?", modId).Scopes(orderedImages).Limit(1).Find(&first); res.Error != nil {
+				return res.Error
+			} else if res.RowsAffected == 0 {
+				return nil
+			}
+			favoriteId = first.Id
+		}
+	}
+	if res := tx.Table(table).Where(column+" = ?", modId).Update("favorite", gorm.Expr("id = ?", favoriteId)); res.Error != nil {
+		return res.Error
+	}
+	return nil
+}
+
+func (i ImageRepositoryImpl) SelectImages(modType models.ModType, modId uint) ([]models.Image, error) {
+	table, column, err := imagesTable(modType)
+	if err != nil {
+		return nil, err
+	}
+	var dbImages []entities.Image
+	if res := i.Db.Table(table).Where(column+" = ?", modId).Scopes(orderedImages).Find(&dbImages); res.Error != nil {
+		return nil, res.Error
+	}
+	images := make([]models.Image, 0, len(dbImages))
+	for _, dbImage := range dbImages {
+		images = append(images, dbImage.ToEntity())
+	}
+	return images, nil
+}
+
+func (i ImageRepositoryImpl) InsertImage(modType models.ModType, modId uint, image *models.Image) error {
+	table, column, err := imagesTable(modType)
+	if err != nil {
+		return err
+	}
+	return i.Db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if res := tx.Table(table).Where(column+" = ?", modId).Count(&count); res.Error != nil {
+			return res.Error
+		}
+		image.Position = int(count)
+
+		dbImage := entities.ImageFromEntity(*image)
+		dbImage.Id = 0
+		if err := insertImage(tx, modType, modId, &dbImage); err != nil {
+			return err
+		}
+		image.Id = dbImage.Id
+
+		var favoriteId uint
+		if image.Favorite {
+			favoriteId = image.Id
+		}
+		if err := enforceSingleFavorite(tx, table, column, modId, favoriteId); err != nil {
+			return err
+		}
+		image.Favorite = image.Favorite || count == 0
+		return nil
+	})
+}
+
+func (i ImageRepositoryImpl) UpdateImage(modType models.ModType, modId uint, image models.Image) error {
+	table, column, err := imagesTable(modType)
+	if err != nil {
+		return err
+	}
+	return i.Db.Transaction(func(tx *gorm.DB) error {
+		if res := tx.Table(table).Where("id = ? AND "+column+" = ?", image.Id, modId).Updates(map[string]interface{}{
+			"caption":  image.Caption,
+			"alt_text": image.AltText,
+		}); res.Error != nil {
+			return res.Error
+		}
+
+		var found int64
+		if res := tx.Table(table).Where("id = ? AND "+column+" = ?", image.Id, modId).Count(&found); res.Error != nil {
+			return res.Error
+		} else if found == 0 {
+			return models.ErrNotFound
+		}
+
+		if image.Favorite {
+			return enforceSingleFavorite(tx, table, column, modId, image.Id)
+		}
+		return nil
+	})
+}
+
+func (i ImageRepositoryImpl) DeleteImage(modType models.ModType, modId uint, imageId uint) (models.Image, error) {
+	table, column, err := imagesTable(modType)
+	if err != nil {
+		return models.Image{}, err
+	}
+	var deleted entities.Image
+	err = i.Db.Transaction(func(tx *gorm.DB) error {
+		if res := tx.Table(table).Where("id = ? AND "+column+" = ?", imageId, modId).Limit(1).Find(&deleted); res.Error != nil {
+			return res.Error
+		} else if res.RowsAffected == 0 {
+			return models.ErrNotFound
+		}
+		if res := tx.Table(table).Where("id = ?", imageId).Delete(&entities.Image{}); res.Error != nil {
+			return res.Error
+		}
+		if res := tx.Table(table).Where(column+" = ? AND position > ?", modId, deleted.Position).Update("position", gorm.Expr("position - 1")); res.Error != nil {
+			return res.Error
+		}
+		return enforceSingleFavorite(tx, table, column, modId, 0)
+	})
+	if err != nil {
+		return models.Image{}, err
+	}
+	return deleted.ToEntity(), nil
+}
+
+func (i ImageRepositoryImpl) ReorderImages(modType models.ModType, modId uint, imageIds []uint) error {
+	table, column, err := imagesTable(modType)
+	if err != nil {
+		return err
+	}
+	return i.Db.Transaction(func(tx *gorm.DB) error {
+		var ids []uint
+		if res := tx.Table(table).Where(column+" = ?", modId).Pluck("id", &ids); res.Error != nil {
+			return res.Error
+		}
+		if len(ids) != len(imageIds) {
+			return models.NewValidationError("order", "the new order must contain every image of the mod exactly once")
+		}
+		current := make(map[uint]bool, len(ids))
+		for _, id := range ids {
+			current[id] = true
+		}
+		for position, id := range imageIds {
+			if !current[id] {
+				return models.NewValidationError("order", "the new order must contain every image of the mod exactly once")
+			}
+			delete(current, id)
+			if res := tx.Table(table).Where("id = ?", id).Update("position", position); res.Error != nil {
+				return res.Error
+			}
+		}
+		return nil
+	})
+}
+
+func (i ImageRepositoryImpl) UpdateSkinImage(skinId uint, url string) error {
+	if res := i.Db.Model(&entities.Skin{}).Where("id = ?", skinId).Update("image_url", url); res.Error != nil {
+		return res.Error
+	} else if res.RowsAffected == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
+func (i ImageRepositoryImpl) UpdateBrandLogo(name string, url string) error {
+	if res := i.Db.Model(&entities.Manufacturer{}).Where("name = ?", name).Update("logo", url); res.Error != nil {
+		return res.Error
+	} else if res.RowsAffected == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
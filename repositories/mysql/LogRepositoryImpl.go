@@ -28,3 +28,59 @@ func (l LogRepositoryImpl) SelectAllCarLogs() ([]models.CarLog, error) {
 
 	return logs, nil
 }
+
+// SelectCarLogs returns the latest car logs matching the filter, newest first. A tag filter matches no car
+func (l LogRepositoryImpl) SelectCarLogs(filter models.FeedFilter, limit int) ([]models.CarLog, error) {
+	logs := []models.CarLog{}
+	if filter.Tag != "" {
+		return logs, nil
+	}
+	query := l.latestLogs("car_logs_view", limit)
+	if filter.Brand != "" {
+		query = query.Where("brand = ?", filter.Brand)
+	}
+	if filter.Category != "" {
+		query = query.Where("car_id IN (?)", l.Db.Table("car_categories").Select("car_id").Where("category = ?", filter.Category))
+	}
+	if filter.Author != "" {
+		query = query.Where("(car_id IN (?) OR car_id IN (?))",
+			l.Db.Table("car_mods").Select("id").Where("author = ?", filter.Author),
+			l.Db.Table("car_authors").Select("car_id").Joins("join authors on authors.id = car_authors.author_id").Where("authors.name = ?", filter.Author))
+	}
+
+	if res := query.Find(&logs); res.Error != nil {
+		return nil, res.Error
+	}
+	return logs, nil
+}
+
+// SelectTrackLogs returns the latest track logs matching the filter, newest first. A category or brand filter
+// matches no track
+func (l LogRepositoryImpl) SelectTrackLogs(filter models.FeedFilter, limit int) ([]models.TrackLog, error) {
+	logs := []models.TrackLog{}
+	if filter.Category != "" || filter.Brand != "" {
+		return logs, nil
+	}
+	query := l.latestLogs("track_logs_view", limit)
+	if filter.Tag != "" {
+		query = query.Where("track_id IN (?)", l.Db.Table("track_tags").Select("id_track").Where("tag = ?", filter.Tag))
+	}
+	if filter.Author != "" {
+		query = query.Where("(track_id IN (?) OR track_id IN (?))",
+			l.Db.Table("track_mods").Select("id").Where("author = ?", filter.Author),
+			l.Db.Table("track_authors").Select("track_id").Joins("join authors on authors.id = track_authors.author_id").Where("authors.name = ?", filter.Author))
+	}
+
+	if res := query.Find(&logs); res.Error != nil {
+		return nil, res.Error
+	}
+	return logs, nil
+}
+
+func (l LogRepositoryImpl) latestLogs(view string, limit int) *gorm.DB {
+	query := l.Db.Table(view).Order("happened_at DESC, log_id DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	return query
+}
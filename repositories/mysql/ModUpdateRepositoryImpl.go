@@ -0,0 +1,154 @@
+package mysql
+
+import (
+	models2 "github.com/davide/ModRepository/models"
+	"github.com/davide/ModRepository/repositories/entities"
+	"gorm.io/gorm"
+)
+
+type ModUpdateRepositoryImpl struct {
+	Db *gorm.DB
+}
+
+func (m ModUpdateRepositoryImpl) InsertModUpdate(update *models2.ModUpdate) error {
+	dbUpdate, err := entities.ModUpdateFromEntity(*update)
+	if err != nil {
+		return err
+	}
+	if res := m.Db.Create(&dbUpdate); res.Error != nil {
+		return res.Error
+	}
+	update.Id = dbUpdate.Id
+	update.CreatedAt = dbUpdate.CreatedAt
+	return nil
+}
+
+func (m ModUpdateRepositoryImpl) SelectModUpdateById(id uint) (models2.ModUpdate, error) {
+	var dbUpdate entities.ModUpdate
+	if res := m.Db.Where("id = ?", id).Limit(1).Find(&dbUpdate); res.Error != nil {
+		return models2.ModUpdate{}, res.Error
+	} else if res.RowsAffected == 0 {
+		return models2.ModUpdate{}, models2.ErrNotFound
+	}
+	return dbUpdate.ToEntity(), nil
+}
+
+func (m ModUpdateRepositoryImpl) SelectModUpdatesByStatus(status models2.UpdateStatus) ([]models2.ModUpdate, error) {
+	var dbUpdates []entities.ModUpdate
+	updates := make([]models2.ModUpdate, 0)
+	if res := m.Db.Where("status = ?", string(status)).Order("created_at ASC").Find(&dbUpdates); res.Error != nil {
+		return nil, res.Error
+	}
+	for _, dbUpdate := range dbUpdates {
+		updates = append(updates, dbUpdate.ToEntity())
+	}
+	return updates, nil
+}
+
+func (m ModUpdateRepositoryImpl) UpdateModUpdateStatus(id uint, status models2.UpdateStatus) error {
+	if res := m.Db.Model(&entities.ModUpdate{}).Where("id = ?", id).Update("status", string(status)); res.Error != nil {
+		return res.Error
+	} else if res.RowsAffected == 0 {
+		return models2.ErrNotFound
+	}
+	return nil
+}
+
+// ApplyModUpdate writes the notifications in the same transaction, with the mod as updated for payload. The images
+// must be normalized, an empty gallery leaves the current one untouched
+func (m ModUpdateRepositoryImpl) ApplyModUpdate(update models2.ModUpdate, notifications ...models2.Notification) error {
+	return m.Db.Transaction(func(tx *gorm.DB) error {
+		fields := map[string]interface{}{}
+		if update.Version != "" {
+			fields["version"] = update.Version
+		}
+		if update.DownloadLink != "" {
+			fields["download_link"] = update.DownloadLink
+		}
+		if update.Changelog != "" {
+			fields["changelog"] = update.Changelog
+		}
+
+		switch update.ModType {
+		case models2.CarModType:
+			if len(fields) > 0 {
+				if res := tx.Model(&entities.Car{}).Where("id = ?", update.ModId).Updates(fields); res.Error != nil {
+					return res.Error
+				}
+			}
+			if len(update.Images) > 0 {
+				if res := tx.Where("car_id = ?", update.ModId).Delete(&entities.CarImage{}); res.Error != nil {
+					return res.Error
+				}
+				for _, image := range update.Images {
+					dbImage := entities.CarImageFromEntity(image, update.ModId)
+					dbImage.Id = 0
+					if res := tx.Create(&dbImage); res.Error != nil {
+						return res.Error
+					}
+				}
+			}
+			if len(notifications) > 0 {
+				car, err := CarRepositoryImpl{Db: tx}.SelectCarById(update.ModId, true, true)
+				if err != nil {
+					return err
+				}
+				return insertNotifications(tx, models2.CarModType, update.ModId, car, notifications)
+			}
+		case models2.TrackModType:
+			if len(fields) > 0 {
+				if res := tx.Model(&entities.Track{}).Where("id = ?", update.ModId).Updates(fields); res.Error != nil {
+					return res.Error
+				}
+			}
+			if len(update.Images) > 0 {
+				if res := tx.Where("track_id = ?", update.ModId).Delete(&entities.TrackImage{}); res.Error != nil {
+					return res.Error
+				}
+				for _, image := range update.Images {
+					dbImage := entities.TrackImageFromEntity(image, update.ModId)
+					dbImage.Id = 0
+					if res := tx.Create(&dbImage); res.Error != nil {
+						return res.Error
+					}
+				}
+			}
+			if len(notifications) > 0 {
+				track, err := TrackRepositoryImpl{Db: tx}.SelectTrackById(update.ModId, true, true)
+				if err != nil {
+					return err
+				}
+				return insertNotifications(tx, models2.TrackModType, update.ModId, track, notifications)
+			}
+		default:
+			return models2.NewValidationError("modType", "unknown mod type")
+		}
+		return nil
+	})
+}
+
+func (m ModUpdateRepositoryImpl) IsModCreditedTo(modType models2.ModType, modId uint, username string) (bool, error) {
+	var dbUser entities.User
+	if res := m.Db.Where("username = ?", username).Limit(1).Find(&dbUser); res.Error != nil {
+		return false, res.Error
+	} else if res.RowsAffected == 0 || dbUser.IdAuthor == nil {
+		return false, nil
+	}
+
+	var count int64
+	var res *gorm.DB
+	switch modType {
+	case models2.CarModType:
+		res = m.Db.Model(&entities.Car{}).Where("id = ? AND (id_author = ? OR id IN (?))", modId, *dbUser.IdAuthor,
+			m.Db.Model(&entities.CarContributor{}).Select("car_id").Where("author_id = ?", *dbUser.IdAuthor)).Count(&count)
+	case models2.TrackModType:
+		res = m.Db.Model(&entities.Track{}).Where("id = ? AND (id_author = ? OR id IN (?))", modId, *dbUser.IdAuthor,
+			m.Db.Model(&entities.TrackContributor{}).Select("track_id").Where("author_id = ?", *dbUser.IdAuthor)).Count(&count)
+	default:
+		return false, models2.NewValidationError("modType", "unknown mod type")
+	}
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return count > 0, nil
+}
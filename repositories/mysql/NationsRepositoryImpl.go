@@ -2,6 +2,7 @@ package mysql
 
 import (
 	"github.com/davide/ModRepository/models"
+	"github.com/davide/ModRepository/repositories/entities"
 	"gorm.io/gorm"
 )
 
@@ -9,18 +10,112 @@ type NationsRepositoryImpl struct {
 	Db *gorm.DB
 }
 
-func (n NationsRepositoryImpl) SelectAllBrandsNations() ([]models.Nation, error) {
+func (n NationsRepositoryImpl) withContinent(query *gorm.DB, continent models.Continent) *gorm.DB {
+	if continent != "" {
+		return query.Where("nations.continent = ?", string(continent))
+	}
+	return query
+}
+
+func (n NationsRepositoryImpl) SelectAllNations(continent models.Continent) ([]models.Nation, error) {
+	var nations []models.Nation
+	if result := n.withContinent(n.Db.Model(&entities.Nation{}), continent).Order("nations.name ASC").Find(&nations); result.Error != nil {
+		return nil, result.Error
+	}
+	return nations, nil
+}
+
+func (n NationsRepositoryImpl) SelectAllBrandsNations(continent models.Continent) ([]models.Nation, error) {
 	var nations []models.Nation
-	if result := n.Db.Order("nations.name ASC").Distinct("nations.*").Joins("inner join manufacturers on manufacturers.id_nation = nations.id").Find(&nations); result.Error != nil {
+	if result := n.withContinent(n.Db, continent).Order("nations.name ASC").Distinct("nations.*").Joins("inner join manufacturers on manufacturers.id_nation = nations.id").Find(&nations); result.Error != nil {
 		return nil, result.Error
 	}
 	return nations, nil
 }
 
-func (n NationsRepositoryImpl) SelectAllTrackNations() ([]models.Nation, error) {
+func (n NationsRepositoryImpl) SelectAllTrackNations(continent models.Continent) ([]models.Nation, error) {
 	var nations []models.Nation
-	if result := n.Db.Distinct("nations.*").Joins("inner join tracks on tracks.id_nation = nations.id").Order("nations.name asc").Find(&nations); result.Error != nil {
+	if result := n.withContinent(n.Db, continent).Distinct("nations.*").Joins("inner join tracks on tracks.id_nation = nations.id").Order("nations.name asc").Find(&nations); result.Error != nil {
 		return nil, result.Error
 	}
 	return nations, nil
 }
+
+// SeedNations adds the missing nations and fills the empty fields of the existing ones, running it again changes
+// nothing. Legacy names like "UK" are renamed only when it doesn't clash with another row, those are left to a merge
+func (n NationsRepositoryImpl) SeedNations(nations []models.Nation) error {
+	return n.Db.Transaction(func(tx *gorm.DB) error {
+		for _, nation := range nations {
+			dbNation, found, err := findNation(tx, nation.Code, nation.Name)
+			if err != nil {
+				return err
+			} else if !found {
+				dbNation = entities.NationFromEntity(nation)
+				if res := tx.Create(&dbNation); res.Error != nil {
+					return res.Error
+				}
+				continue
+			}
+
+			fields := map[string]interface{}{}
+			if dbNation.Code == "" {
+				fields["code"] = nation.Code
+			}
+			if dbNation.Flag == "" {
+				fields["flag"] = nation.Flag
+			}
+			if dbNation.Continent == "" {
+				fields["continent"] = string(nation.Continent)
+			}
+			if dbNation.Region == "" {
+				fields["region"] = nation.Region
+			}
+			if dbNation.Name != nation.Name {
+				var clashes int64
+				if res := tx.Model(&entities.Nation{}).Where("name = ? AND id <> ?", nation.Name, dbNation.Id).Count(&clashes); res.Error != nil {
+					return res.Error
+				} else if clashes == 0 {
+					fields["name"] = nation.Name
+				}
+			}
+
+			if len(fields) > 0 {
+				if res := tx.Model(&dbNation).Updates(fields); res.Error != nil {
+					return res.Error
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func (n NationsRepositoryImpl) MergeNations(source string, target string) error {
+	if source == target {
+		return models.NewValidationError("target", "cannot merge a nation into itself")
+	}
+	return n.Db.Transaction(func(tx *gorm.DB) error {
+		var sourceNation, targetNation entities.Nation
+		if res := tx.Where("name = ?", source).Limit(1).Find(&sourceNation); res.Error != nil {
+			return res.Error
+		} else if res.RowsAffected == 0 {
+			return models.ErrNotFound
+		}
+		if res := tx.Where("name = ?", target).Limit(1).Find(&targetNation); res.Error != nil {
+			return res.Error
+		} else if res.RowsAffected == 0 {
+			return models.ErrNotFound
+		}
+
+		if res := tx.Model(&entities.Manufacturer{}).Where("id_nation = ?", sourceNation.Id).Update("id_nation", targetNation.Id); res.Error != nil {
+			return res.Error
+		}
+		if res := tx.Model(&entities.Track{}).Where("id_nation = ?", sourceNation.Id).Update("id_nation", targetNation.Id); res.Error != nil {
+			return res.Error
+		}
+
+		if res := tx.Delete(&sourceNation); res.Error != nil {
+			return res.Error
+		}
+		return nil
+	})
+}
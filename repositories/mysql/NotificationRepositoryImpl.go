@@ -0,0 +1,88 @@
+package mysql
+
+import (
+	"encoding/json"
+	models2 "github.com/davide/ModRepository/models"
+	"github.com/davide/ModRepository/repositories/entities"
+	"gorm.io/gorm"
+	"strings"
+	"time"
+)
+
+type NotificationRepositoryImpl struct {
+	Db *gorm.DB
+}
+
+// insertNotifications adds the notifications of a mod to the outbox, it runs in the transaction of the mod change
+func insertNotifications(tx *gorm.DB, modType models2.ModType, modId uint, mod interface{}, notifications []models2.Notification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+	payload, err := json.Marshal(mod)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, notification := range notifications {
+		dbNotification := entities.NotificationFromEntity(notification)
+		dbNotification.Id = 0
+		dbNotification.ModType = string(modType)
+		dbNotification.ModId = modId
+		dbNotification.Payload = string(payload)
+		dbNotification.Status = string(models2.PendingNotification)
+		dbNotification.NextAttemptAt = now
+		if res := tx.Create(&dbNotification); res.Error != nil {
+			return res.Error
+		}
+	}
+	return nil
+}
+
+func (n NotificationRepositoryImpl) SelectDueNotifications(now time.Time, limit int) ([]models2.Notification, error) {
+	return n.selectNotifications(n.Db.Where("status = ? AND next_attempt_at <= ?", string(models2.PendingNotification), now).Order("next_attempt_at ASC").Limit(limit))
+}
+
+// SelectNotificationsByStatus returns the latest notifications first
+func (n NotificationRepositoryImpl) SelectNotificationsByStatus(status models2.NotificationStatus, limit int) ([]models2.Notification, error) {
+	return n.selectNotifications(n.Db.Where("status = ?", string(status)).Order("id DESC").Limit(limit))
+}
+
+func (n NotificationRepositoryImpl) selectNotifications(query *gorm.DB) ([]models2.Notification, error) {
+	var dbNotifications []entities.Notification
+	notifications := make([]models2.Notification, 0)
+	if res := query.Find(&dbNotifications); res.Error != nil {
+		return nil, res.Error
+	}
+	for _, dbNotification := range dbNotifications {
+		notifications = append(notifications, dbNotification.ToEntity())
+	}
+	return notifications, nil
+}
+
+func (n NotificationRepositoryImpl) SelectNotificationById(id uint) (models2.Notification, error) {
+	var dbNotification entities.Notification
+	if res := n.Db.Where("id = ?", id).Limit(1).Find(&dbNotification); res.Error != nil {
+		return models2.Notification{}, res.Error
+	} else if res.RowsAffected == 0 {
+		return models2.Notification{}, models2.ErrNotFound
+	}
+	return dbNotification.ToEntity(), nil
+}
+
+// UpdateNotification saves the outcome of a delivery attempt
+func (n NotificationRepositoryImpl) UpdateNotification(notification models2.Notification) error {
+	res := n.Db.Model(&entities.Notification{}).Where("id = ?", notification.Id).Updates(map[string]interface{}{
+		"status":          string(notification.Status),
+		"attempts":        notification.Attempts,
+		"last_error":      notification.LastError,
+		"next_attempt_at": notification.NextAttemptAt,
+		"delivered_at":    notification.DeliveredAt,
+		"delivered":       strings.Join(notification.Delivered, ","),
+	})
+	if res.Error != nil {
+		return res.Error
+	} else if res.RowsAffected == 0 {
+		return models2.ErrNotFound
+	}
+	return nil
+}
@@ -15,59 +15,69 @@ type serverCarsAssoc struct {
 	ServerId uint
 }
 
+// UpdateServer replaces the server with its cars, a server that doesn't exist is not found and nothing is written
 func (s ServersRepositoryImpl) UpdateServer(server models2.Server) error {
+	return s.Db.Transaction(func(tx *gorm.DB) error {
+		dbServer := entities.ServerFromEntity(server)
 
-	dbServer := entities.ServerFromEntity(server)
-
-	if result := s.Db.Model(entities.Server{}).Where("id = ?", dbServer.Id).Select("*").Omit("OutsideCars").Updates(&dbServer); result.Error != nil {
-		return result.Error
-	}
+		var count int64
+		if result := tx.Model(&entities.Server{}).Where("id = ?", dbServer.Id).Count(&count); result.Error != nil {
+			return result.Error
+		} else if count == 0 {
+			return models2.ErrNotFound
+		}
 
-	var serverCars []serverCarsAssoc
+		if result := tx.Model(entities.Server{}).Where("id = ?", dbServer.Id).Select("*").Omit("OutsideCars").Updates(&dbServer); result.Error != nil {
+			return translateError(result.Error)
+		}
 
-	for _, carId := range server.Cars {
-		serverCars = append(serverCars, serverCarsAssoc{
-			CarId:    carId,
-			ServerId: server.Id,
-		})
-	}
+		var serverCars []serverCarsAssoc
 
-	if result := s.Db.Model(&entities.Server{Id: dbServer.Id}).Association("Cars").Clear(); result != nil {
-		return result
-	}
+		for _, carId := range server.Cars {
+			serverCars = append(serverCars, serverCarsAssoc{
+				CarId:    carId,
+				ServerId: server.Id,
+			})
+		}
 
-	if len(serverCars) > 0 {
-		if result := s.Db.Table("server_cars").Create(&serverCars); result.Error != nil {
-			return result.Error
+		if result := tx.Model(&entities.Server{Id: dbServer.Id}).Association("Cars").Clear(); result != nil {
+			return result
 		}
-	}
 
-	var outsideCars []entities.OutsideMod
+		if len(serverCars) > 0 {
+			if result := tx.Table("server_cars").Create(&serverCars); result.Error != nil {
+				return translateError(result.Error)
+			}
+		}
 
-	for _, outsideCar := range server.OutsideCars {
-		outsideCars = append(outsideCars, entities.OutsideModFromEntity(outsideCar, dbServer.Id))
-	}
+		var outsideCars []entities.OutsideMod
 
-	if result := s.Db.Where("server_id = ?", dbServer.Id).Delete(&entities.OutsideMod{}); result.Error != nil {
-		return result.Error
-	}
+		for _, outsideCar := range server.OutsideCars {
+			outsideCars = append(outsideCars, entities.OutsideModFromEntity(outsideCar, dbServer.Id))
+		}
 
-	if len(outsideCars) > 0 {
-		if result := s.Db.Debug().Model(&entities.OutsideMod{}).Omit("Id").Create(&outsideCars); result.Error != nil {
+		if result := tx.Where("server_id = ?", dbServer.Id).Delete(&entities.OutsideMod{}); result.Error != nil {
 			return result.Error
 		}
-	}
 
-	return nil
+		if len(outsideCars) > 0 {
+			if result := tx.Model(&entities.OutsideMod{}).Omit("Id").Create(&outsideCars); result.Error != nil {
+				return result.Error
+			}
+		}
+
+		return nil
+	})
 }
 
-func (s ServersRepositoryImpl) AddServer(server models2.Server) error {
+func (s ServersRepositoryImpl) AddServer(server *models2.Server) error {
 
-	dbServer := entities.ServerFromEntity(server)
+	dbServer := entities.ServerFromEntity(*server)
 
 	if result := s.Db.Model(entities.Server{}).Omit("Cars", "OutsideCars").Create(&dbServer); result.Error != nil {
-		return result.Error
+		return translateError(result.Error)
 	}
+	server.Id = dbServer.Id
 
 	var serverCars []serverCarsAssoc
 	serverCars = make([]serverCarsAssoc, 0)
@@ -103,13 +113,25 @@ func (s ServersRepositoryImpl) AddServer(server models2.Server) error {
 
 func (s ServersRepositoryImpl) DeleteServer(server models2.Server) error {
 	if result := s.Db.Where("id = ?", server.Id).Delete(&entities.Server{}); result.Error != nil {
-		return result.Error
+		return translateError(result.Error)
+	} else if result.RowsAffected == 0 {
+		return models2.ErrNotFound
 	}
 	return nil
 }
 
+func (s ServersRepositoryImpl) GetServerById(id uint) (models2.Server, error) {
+	var dbServer entities.Server
+	if result := s.Db.Model(entities.Server{}).Where("id = ?", id).Preload("Cars").Preload("OutsideCars").Limit(1).Find(&dbServer); result.Error != nil {
+		return models2.Server{}, result.Error
+	} else if result.RowsAffected == 0 {
+		return models2.Server{}, models2.ErrNotFound
+	}
+	return dbServer.ToEntity(), nil
+}
+
 func (s ServersRepositoryImpl) GetAllServers() ([]models2.Server, error) {
-	var servers []models2.Server
+	servers := []models2.Server{}
 	var dbServers []entities.Server
 	if result := s.Db.Model(entities.Server{}).Preload("Cars").Preload("OutsideCars").Find(&dbServers); result.Error != nil {
 		return nil, result.Error
@@ -1,7 +1,6 @@
 package mysql
 
 import (
-	"errors"
 	"github.com/davide/ModRepository/models"
 	"gorm.io/gorm"
 )
@@ -11,35 +10,59 @@ type SkinRepositoryImpl struct {
 }
 
 func (s SkinRepositoryImpl) GetAllSkins() ([]models.Skin, error) {
-	var skins []models.Skin
+	skins := []models.Skin{}
 	if result := s.Db.Model(&models.Skin{}).Find(&skins); result.Error != nil {
 		return nil, result.Error
-	} else if result.RowsAffected == 0 {
-		return nil, errors.New("not found")
 	}
 	return skins, nil
 }
 
 func (s SkinRepositoryImpl) SelectCarSkins(carId uint) ([]models.Skin, error) {
-	var skins []models.Skin
+	skins := []models.Skin{}
 	if result := s.Db.Model(&models.Skin{}).Where("car_id = ?", carId).Find(&skins); result.Error != nil {
 		return nil, result.Error
-	} else if result.RowsAffected == 0 {
-		return nil, errors.New("not found")
 	}
 	return skins, nil
 }
 
-func (s SkinRepositoryImpl) AddSkin(skin models.Skin) error {
-	if result := s.Db.Create(&skin); result.Error != nil {
-		return result.Error
+func (s SkinRepositoryImpl) SelectSkinById(id uint) (models.Skin, error) {
+	var skin models.Skin
+	if result := s.Db.Model(&models.Skin{}).Where("id = ?", id).Limit(1).Find(&skin); result.Error != nil {
+		return models.Skin{}, result.Error
+	} else if result.RowsAffected == 0 {
+		return models.Skin{}, models.ErrNotFound
+	}
+	return skin, nil
+}
+
+func (s SkinRepositoryImpl) AddSkin(skin *models.Skin) error {
+	if result := s.Db.Create(skin); result.Error != nil {
+		return translateError(result.Error)
 	}
 	return nil
 }
 
+// UpdateSkin replaces the skin, a skin that doesn't exist or belongs to another car is not found
 func (s SkinRepositoryImpl) UpdateSkin(skin models.Skin) error {
-	if result := s.Db.Model(&skin).Where("car_id = ?", skin.CarId).Select("*").Updates(&skin); result.Error != nil {
-		return result.Error
+	return s.Db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if result := tx.Model(&models.Skin{}).Where("id = ? AND car_id = ?", skin.Id, skin.CarId).Count(&count); result.Error != nil {
+			return result.Error
+		} else if count == 0 {
+			return models.ErrNotFound
+		}
+		if result := tx.Model(&skin).Where("car_id = ?", skin.CarId).Select("*").Updates(&skin); result.Error != nil {
+			return translateError(result.Error)
+		}
+		return nil
+	})
+}
+
+func (s SkinRepositoryImpl) DeleteSkin(id uint) error {
+	if result := s.Db.Delete(&models.Skin{}, id); result.Error != nil {
+		return translateError(result.Error)
+	} else if result.RowsAffected == 0 {
+		return models.ErrNotFound
 	}
 	return nil
 }
@@ -1,7 +1,6 @@
 package mysql
 
 import (
-	"errors"
 	models2 "github.com/davide/ModRepository/models"
 	"github.com/davide/ModRepository/repositories/entities"
 	"gorm.io/gorm"
@@ -16,14 +15,12 @@ type selectFromTrackQuery func() *gorm.DB
 
 func selectTracksWithQuery(query selectFromTrackQuery, premium bool, admin bool) ([]models2.Track, error) {
 	var dbTracks []entities.TrackMod
-	var tracks []models2.Track
 
 	if result := query().Find(&dbTracks); result.Error != nil {
 		return nil, result.Error
-	} else if result.RowsAffected == 0 {
-		return nil, errors.New("not found")
 	}
 
+	tracks := make([]models2.Track, 0, len(dbTracks))
 	for _, dbTrack := range dbTracks {
 		tracks = append(tracks, dbTrack.ToEntity(premium, admin))
 	}
@@ -31,49 +28,65 @@ func selectTracksWithQuery(query selectFromTrackQuery, premium bool, admin bool)
 }
 
 func (t TrackRepositoryImpl) preInsertionQueries(track models2.Track) (entities.Track, error) {
-	dbNation := entities.NationFromEntity(track.Nation)
-
-	if res := t.Db.Clauses(clause.OnConflict{DoNothing: true}).Create(&dbNation); res.Error != nil {
-		return entities.Track{}, res.Error
-	}
-
-	if res := t.Db.Where("name = ?", dbNation.Name).First(&dbNation); res.Error != nil {
-		return entities.Track{}, res.Error
+	dbNation, err := firstOrCreateNation(t.Db, track.Nation)
+	if err != nil {
+		return entities.Track{}, err
 	}
 
-	dbAuthor := entities.AuthorFromEntity(track.Author)
-
-	if res := t.Db.Clauses(clause.OnConflict{DoNothing: true}).Create(&dbAuthor); res.Error != nil {
-		return entities.Track{}, res.Error
+	dbAuthor, err := firstOrCreateAuthor(t.Db, track.Author)
+	if err != nil {
+		return entities.Track{}, err
 	}
 
-	if res := t.Db.Where("name = ?", dbAuthor.Name).First(&dbAuthor); res.Error != nil {
-		return entities.Track{}, res.Error
+	contributors, err := contributorsFromEntity(t.Db, track.Contributors)
+	if err != nil {
+		return entities.Track{}, err
 	}
 
-	return entities.TrackFromEntity(track, dbNation.Id, dbAuthor.Id), nil
+	return entities.TrackFromEntity(track, dbNation.Id, dbAuthor.Id, contributors), nil
 }
 
 func (t TrackRepositoryImpl) SelectAllTracks(premium bool, admin bool) ([]models2.Track, error) {
 	return selectTracksWithQuery(func() *gorm.DB {
-		return t.Db.Order("name ASC").Preload("Layouts").Preload("Tags").Preload("Images")
+		return t.Db.Order("name ASC").Preload("Layouts").Preload("Tags").Preload("Images", orderedImages).Preload("Contributors.Author")
 	}, premium, admin)
 }
 
-func (t TrackRepositoryImpl) InsertTrack(track *models2.Track) error {
+func (t TrackRepositoryImpl) InsertTrack(track *models2.Track, notifications ...models2.Notification) error {
+	return t.Db.Transaction(func(tx *gorm.DB) error {
+		if err := (TrackRepositoryImpl{Db: tx}).insertTrack(track); err != nil {
+			return err
+		}
+		return insertNotifications(tx, models2.TrackModType, track.Id, *track, notifications)
+	})
+}
+
+func (t TrackRepositoryImpl) UpdateTrack(track models2.Track, notifications ...models2.Notification) (bool, error) {
+	var versionChange bool
+	err := t.Db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if versionChange, err = (TrackRepositoryImpl{Db: tx}).updateTrack(track); err != nil || !versionChange {
+			return err
+		}
+		return insertNotifications(tx, models2.TrackModType, track.Id, track, notifications)
+	})
+	return versionChange, err
+}
+
+func (t TrackRepositoryImpl) insertTrack(track *models2.Track) error {
 
 	if dbTrack, err := t.preInsertionQueries(*track); err != nil {
 		return err
 	} else {
 		if res := t.Db.Create(&dbTrack); res.Error != nil {
-			return res.Error
+			return translateError(res.Error)
 		}
 		track.Id = dbTrack.Id
 	}
 	return nil
 }
 
-func (t TrackRepositoryImpl) UpdateTrack(track models2.Track) (bool, error) {
+func (t TrackRepositoryImpl) updateTrack(track models2.Track) (bool, error) {
 
 	if dbTrack, err := t.preInsertionQueries(track); err != nil {
 		return false, err
@@ -81,11 +94,11 @@ func (t TrackRepositoryImpl) UpdateTrack(track models2.Track) (bool, error) {
 		oldTrack := dbTrack
 
 		if res := t.Db.First(&oldTrack, track.Id); res.Error != nil {
-			return false, res.Error
+			return false, translateError(res.Error)
 		}
 
 		if res := t.Db.Model(&dbTrack).Select("*").Omit("UpdatedAt", "CreatedAt").Updates(&dbTrack); res.Error != nil {
-			return false, res.Error
+			return false, translateError(res.Error)
 		}
 
 		if res := t.Db.Where("id_track = ?", dbTrack.Id).Delete(&entities.Layout{}); res.Error != nil {
@@ -104,11 +117,20 @@ func (t TrackRepositoryImpl) UpdateTrack(track models2.Track) (bool, error) {
 			return false, res
 		}
 
-		if res := t.Db.Where("track_id = ?", dbTrack.Id).Delete(&entities.TrackImage{}); res.Error != nil {
+		var images []entities.Image
+		for _, image := range dbTrack.Images {
+			images = append(images, image.Image)
+		}
+
+		if err := syncImages(t.Db, models2.TrackModType, dbTrack.Id, images); err != nil {
+			return false, err
+		}
+
+		if res := t.Db.Where("track_id = ?", dbTrack.Id).Delete(&entities.TrackContributor{}); res.Error != nil {
 			return false, res.Error
 		}
 
-		if res := t.Db.Model(&dbTrack).Association("Images").Append(dbTrack.Images); res != nil {
+		if res := t.Db.Model(&dbTrack).Association("Contributors").Append(dbTrack.Contributors); res != nil {
 			return false, res
 		}
 
@@ -116,3 +138,84 @@ func (t TrackRepositoryImpl) UpdateTrack(track models2.Track) (bool, error) {
 	}
 
 }
+
+// DeleteTrack removes the track with its layouts, tags, images and credits, tracks used by a server are a conflict
+func (t TrackRepositoryImpl) DeleteTrack(id uint) error {
+	return t.Db.Transaction(func(tx *gorm.DB) error {
+		for _, child := range []interface{}{&entities.Layout{}, &entities.TrackTag{}} {
+			if res := tx.Where("id_track = ?", id).Delete(child); res.Error != nil {
+				return res.Error
+			}
+		}
+		for _, child := range []interface{}{&entities.TrackImage{}, &entities.TrackContributor{}} {
+			if res := tx.Where("track_id = ?", id).Delete(child); res.Error != nil {
+				return res.Error
+			}
+		}
+		if res := tx.Delete(&entities.Track{}, id); res.Error != nil {
+			return translateError(res.Error)
+		} else if res.RowsAffected == 0 {
+			return models2.ErrNotFound
+		}
+		return nil
+	})
+}
+
+func (t TrackRepositoryImpl) SelectTrackById(id uint, premium bool, admin bool) (models2.Track, error) {
+	if tracks, err := selectTracksWithQuery(func() *gorm.DB {
+		return t.Db.Where("id = ?", id).Preload("Layouts").Preload("Tags").Preload("Images", orderedImages).Preload("Contributors.Author")
+	}, premium, admin); err != nil {
+		return models2.Track{}, err
+	} else if len(tracks) == 0 {
+		return models2.Track{}, models2.ErrNotFound
+	} else {
+		return tracks[0], nil
+	}
+}
+
+func (t TrackRepositoryImpl) SelectTracksByIds(ids []uint, premium bool, admin bool) ([]models2.Track, error) {
+	if len(ids) == 0 {
+		return []models2.Track{}, nil
+	}
+	return selectTracksWithQuery(func() *gorm.DB {
+		return t.Db.Where("id IN ?", ids).Preload("Layouts").Preload("Tags").Preload("Images", orderedImages).Preload("Contributors.Author")
+	}, premium, admin)
+}
+
+func (t TrackRepositoryImpl) SelectTracksByAuthor(author string, excludeId uint, limit int, premium bool, admin bool) ([]models2.Track, error) {
+	return selectTracksWithQuery(func() *gorm.DB {
+		return t.Db.Where("(author = ? OR id IN (?)) AND id <> ?", author, t.Db.Table("track_authors").Select("track_id").Joins("join authors on authors.id = track_authors.author_id").Where("authors.name = ?", author), excludeId).Order("created_at DESC").Limit(limit).Preload("Layouts").Preload("Tags").Preload("Images", orderedImages).Preload("Contributors.Author")
+	}, premium, admin)
+}
+
+// SearchTracks returns the first tracks by name whose name, year and location contain every word of the query
+func (t TrackRepositoryImpl) SearchTracks(query string, limit int, premium bool, admin bool) ([]models2.Track, error) {
+	return selectTracksWithQuery(func() *gorm.DB {
+		return matchingWords(t.Db, "concat(name,' ',year,' ',location)", query).Order("name ASC").Limit(limit).Preload("Layouts").Preload("Tags").Preload("Images", orderedImages).Preload("Contributors.Author")
+	}, premium, admin)
+}
+
+func (t TrackRepositoryImpl) SelectTracksByNation(nation string, excludeId uint, limit int, premium bool, admin bool) ([]models2.Track, error) {
+	return selectTracksWithQuery(func() *gorm.DB {
+		return t.Db.Where("nation = ? AND id <> ?", nation, excludeId).Order("name ASC").Limit(limit).Preload("Layouts").Preload("Tags").Preload("Images", orderedImages).Preload("Contributors.Author")
+	}, premium, admin)
+}
+
+func (t TrackRepositoryImpl) SelectTracksByTags(tags []models2.TrackTag, excludeId uint, limit int, premium bool, admin bool) ([]models2.Track, error) {
+	var names []string
+	for _, tag := range tags {
+		names = append(names, string(tag))
+	}
+	if len(names) == 0 {
+		return []models2.Track{}, nil
+	}
+	return selectTracksWithQuery(func() *gorm.DB {
+		return t.Db.Where("id <> ?", excludeId).
+			Where("id IN (?)", t.Db.Table("track_tags").Select("id_track").Where("tag IN ?", names)).
+			Clauses(clause.OrderBy{Expression: clause.Expr{
+				SQL:  "(SELECT COUNT(*) FROM track_tags WHERE track_tags.id_track = track_mods.id AND track_tags.tag IN ?) DESC, name ASC",
+				Vars: []interface{}{names},
+			}}).
+			Limit(limit).Preload("Layouts").Preload("Tags").Preload("Images", orderedImages).Preload("Contributors.Author")
+	}, premium, admin)
+}
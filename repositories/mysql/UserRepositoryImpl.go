@@ -33,10 +33,19 @@ func (u UserRepositoryImpl) SignIn(user models2.User) (models2.User, error) {
 		"Role":     string(user.Role),
 		"Salt":     salt,
 	}
+	if user.Role == models2.ModAuthor {
+		var dbAuthor entities.Author
+		if res := u.Db.Where("name = ?", user.Author).Limit(1).Find(&dbAuthor); res.Error != nil {
+			return models2.User{}, res.Error
+		} else if res.RowsAffected == 0 {
+			return models2.User{}, models2.NewValidationError("author", "an author account must be linked to an existing author")
+		}
+		dbUser["IdAuthor"] = dbAuthor.Id
+	}
 	if res := u.Db.Model(entities.User{}).Create(&dbUser); res.Error != nil {
-		return models2.User{}, res.Error
+		return models2.User{}, translateError(res.Error)
 	}
-	return models2.User{Username: user.Username, Role: user.Role}, nil
+	return models2.User{Username: user.Username, Role: user.Role, Author: user.Author}, nil
 }
 
 func (u UserRepositoryImpl) UpdatePassword(username string, password string) error {
@@ -0,0 +1,100 @@
+package mysql
+
+import (
+	models2 "github.com/davide/ModRepository/models"
+	"github.com/davide/ModRepository/repositories/entities"
+	"gorm.io/gorm"
+)
+
+type WebhookRepositoryImpl struct {
+	Db *gorm.DB
+}
+
+func (w WebhookRepositoryImpl) SelectAllWebhooks() ([]models2.WebhookEndpoint, error) {
+	var dbEndpoints []entities.WebhookEndpoint
+	endpoints := make([]models2.WebhookEndpoint, 0)
+	if res := w.Db.Order("id ASC").Find(&dbEndpoints); res.Error != nil {
+		return nil, res.Error
+	}
+	for _, dbEndpoint := range dbEndpoints {
+		endpoints = append(endpoints, dbEndpoint.ToEntity())
+	}
+	return endpoints, nil
+}
+
+func (w WebhookRepositoryImpl) SelectWebhookById(id uint) (models2.WebhookEndpoint, error) {
+	var dbEndpoint entities.WebhookEndpoint
+	if res := w.Db.Where("id = ?", id).Limit(1).Find(&dbEndpoint); res.Error != nil {
+		return models2.WebhookEndpoint{}, res.Error
+	} else if res.RowsAffected == 0 {
+		return models2.WebhookEndpoint{}, models2.ErrNotFound
+	}
+	return dbEndpoint.ToEntity(), nil
+}
+
+func (w WebhookRepositoryImpl) InsertWebhook(endpoint *models2.WebhookEndpoint) error {
+	dbEndpoint := entities.WebhookEndpointFromEntity(*endpoint)
+	dbEndpoint.Id = 0
+	if res := w.Db.Create(&dbEndpoint); res.Error != nil {
+		return res.Error
+	}
+	endpoint.Id = dbEndpoint.Id
+	endpoint.CreatedAt = dbEndpoint.CreatedAt
+	return nil
+}
+
+// UpdateWebhook changes the url, the description, the events and the active flag, the secret is never updated
+func (w WebhookRepositoryImpl) UpdateWebhook(endpoint models2.WebhookEndpoint) error {
+	dbEndpoint := entities.WebhookEndpointFromEntity(endpoint)
+	res := w.Db.Model(&entities.WebhookEndpoint{}).Where("id = ?", endpoint.Id).Updates(map[string]interface{}{
+		"url":         dbEndpoint.Url,
+		"description": dbEndpoint.Description,
+		"events":      dbEndpoint.Events,
+		"active":      dbEndpoint.Active,
+	})
+	if res.Error != nil {
+		return res.Error
+	} else if res.RowsAffected == 0 {
+		if _, err := w.SelectWebhookById(endpoint.Id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w WebhookRepositoryImpl) DeleteWebhook(id uint) error {
+	return w.Db.Transaction(func(tx *gorm.DB) error {
+		if res := tx.Where("endpoint_id = ?", id).Delete(&entities.WebhookDelivery{}); res.Error != nil {
+			return res.Error
+		}
+		if res := tx.Where("id = ?", id).Delete(&entities.WebhookEndpoint{}); res.Error != nil {
+			return res.Error
+		} else if res.RowsAffected == 0 {
+			return models2.ErrNotFound
+		}
+		return nil
+	})
+}
+
+func (w WebhookRepositoryImpl) InsertWebhookDelivery(delivery *models2.WebhookDelivery) error {
+	dbDelivery := entities.WebhookDeliveryFromEntity(*delivery)
+	if res := w.Db.Create(&dbDelivery); res.Error != nil {
+		return res.Error
+	}
+	delivery.Id = dbDelivery.Id
+	delivery.CreatedAt = dbDelivery.CreatedAt
+	return nil
+}
+
+// SelectWebhookDeliveries returns the latest deliveries of an endpoint, newest first
+func (w WebhookRepositoryImpl) SelectWebhookDeliveries(endpointId uint, limit int) ([]models2.WebhookDelivery, error) {
+	var dbDeliveries []entities.WebhookDelivery
+	deliveries := make([]models2.WebhookDelivery, 0)
+	if res := w.Db.Where("endpoint_id = ?", endpointId).Order("id DESC").Limit(limit).Find(&dbDeliveries); res.Error != nil {
+		return nil, res.Error
+	}
+	for _, dbDelivery := range dbDeliveries {
+		deliveries = append(deliveries, dbDelivery.ToEntity())
+	}
+	return deliveries, nil
+}
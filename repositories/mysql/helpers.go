@@ -0,0 +1,99 @@
+package mysql
+
+import (
+	"errors"
+	"fmt"
+	models2 "github.com/davide/ModRepository/models"
+	"github.com/davide/ModRepository/repositories/entities"
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+	"strings"
+)
+
+const (
+	duplicateEntryError = 1062
+	rowReferencedError  = 1451
+)
+
+// translateError turns the driver errors into domain errors, duplicate keys and rows still referenced become conflicts
+func translateError(err error) error {
+	var mysqlErr *mysql.MySQLError
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return models2.ErrNotFound
+	case errors.As(err, &mysqlErr) && mysqlErr.Number == duplicateEntryError:
+		return fmt.Errorf("%w: %v", models2.ErrConflict, mysqlErr.Message)
+	case errors.As(err, &mysqlErr) && mysqlErr.Number == rowReferencedError:
+		return fmt.Errorf("%w: the entity is still in use", models2.ErrConflict)
+	}
+	return err
+}
+
+// firstOrCreateAuthor looks the author up ignoring surrounding spaces and case, so "Author " and "author"
+// don't end up as two different rows
+func firstOrCreateAuthor(db *gorm.DB, author models2.Author) (entities.Author, error) {
+	dbAuthor := entities.AuthorFromEntity(author)
+	dbAuthor.Name = strings.TrimSpace(dbAuthor.Name)
+
+	if res := db.Where("LOWER(TRIM(name)) = LOWER(?)", dbAuthor.Name).Limit(1).Find(&dbAuthor); res.Error != nil {
+		return entities.Author{}, res.Error
+	} else if res.RowsAffected > 0 {
+		return dbAuthor, nil
+	}
+
+	if res := db.Create(&dbAuthor); res.Error != nil {
+		return entities.Author{}, res.Error
+	}
+	return dbAuthor, nil
+}
+
+func contributorsFromEntity(db *gorm.DB, contributors []models2.Contributor) ([]entities.Contributor, error) {
+	var dbContributors []entities.Contributor
+	for _, contributor := range contributors {
+		dbAuthor, err := firstOrCreateAuthor(db, contributor.Author)
+		if err != nil {
+			return nil, err
+		}
+		dbContributors = append(dbContributors, entities.ContributorFromEntity(contributor, dbAuthor.Id))
+	}
+	return dbContributors, nil
+}
+
+// findNation looks the nation up by code and then by name, nations created before the ISO 3166 seeding may have a
+// different name, and a name matching another row must not win over the code
+func findNation(db *gorm.DB, code string, name string) (entities.Nation, bool, error) {
+	var dbNation entities.Nation
+	if code != "" {
+		if res := db.Where("UPPER(code) = UPPER(?)", code).Order("id").Limit(1).Find(&dbNation); res.Error != nil {
+			return entities.Nation{}, false, res.Error
+		} else if res.RowsAffected > 0 {
+			return dbNation, true, nil
+		}
+	}
+	res := db.Where("name = ?", name).Order("id").Limit(1).Find(&dbNation)
+	return dbNation, res.RowsAffected > 0, res.Error
+}
+
+func firstOrCreateNation(db *gorm.DB, nation models2.Nation) (entities.Nation, error) {
+	if dbNation, found, err := findNation(db, nation.Code, nation.Name); err != nil || found {
+		return dbNation, err
+	}
+
+	dbNation := entities.NationFromEntity(nation)
+	if res := db.Create(&dbNation); res.Error != nil {
+		return entities.Nation{}, res.Error
+	}
+	return dbNation, nil
+}
+
+// matchingWords keeps the rows whose column expression contains every word of the query, the LIKE wildcards in the
+// words are matched literally
+func matchingWords(db *gorm.DB, expression string, query string) *gorm.DB {
+	escaper := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+	for _, word := range strings.Fields(query) {
+		db = db.Where(expression+" LIKE ?", "%"+escaper.Replace(word)+"%")
+	}
+	return db
+}
@@ -0,0 +1,53 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// LocalStorageImpl keeps the images on the local filesystem, they are served by the api under BaseUrl
+type LocalStorageImpl struct {
+	Dir     string
+	BaseUrl string
+}
+
+func (l LocalStorageImpl) filePath(key string) (string, error) {
+	cleaned := path.Clean("/" + key)
+	if cleaned == "/" || strings.Contains(key, "..") {
+		return "", errors.New("invalid image key")
+	}
+	return filepath.Join(l.Dir, filepath.FromSlash(cleaned)), nil
+}
+
+func (l LocalStorageImpl) Save(key string, content []byte) (string, error) {
+	filePath, err := l.filePath(key)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(filePath, content, 0644); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(l.BaseUrl, "/") + path.Clean("/"+key), nil
+}
+
+// Delete removes a stored image by its url, urls not served by this storage are ignored
+func (l LocalStorageImpl) Delete(url string) error {
+	prefix := strings.TrimSuffix(l.BaseUrl, "/") + "/"
+	if !strings.HasPrefix(url, prefix) {
+		return nil
+	}
+	filePath, err := l.filePath(strings.TrimPrefix(url, prefix))
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(filePath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
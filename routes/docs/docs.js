@@ -0,0 +1,125 @@
+// docs.js renders the operations of /openapi.json, it has no dependencies so the page loads nothing from other hosts
+(function () {
+    var methods = ["get", "post", "put", "patch", "delete"];
+
+    function element(tag, className, text) {
+        var node = document.createElement(tag);
+        if (className) {
+            node.className = className;
+        }
+        if (text !== undefined) {
+            node.textContent = text;
+        }
+        return node;
+    }
+
+    // describe writes the schema as an indented type, the components are expanded once per branch against recursion
+    function describe(spec, schema, indent, seen) {
+        if (!schema) {
+            return "any";
+        }
+        if (schema.$ref) {
+            var name = schema.$ref.split("/").pop();
+            if (seen.indexOf(name) >= 0) {
+                return name;
+            }
+            return describe(spec, spec.components.schemas[name], indent, seen.concat([name]));
+        }
+        if (schema.enum) {
+            return schema.enum.map(JSON.stringify).join(" | ");
+        }
+        if (schema.type === "array") {
+            return describe(spec, schema.items, indent, seen) + "[]";
+        }
+        if (schema.type === "object" && schema.properties) {
+            var lines = Object.keys(schema.properties).sort().map(function (property) {
+                return indent + "  " + property + ": " + describe(spec, schema.properties[property], indent + "  ", seen);
+            });
+            return lines.length ? "{\n" + lines.join("\n") + "\n" + indent + "}" : "{}";
+        }
+        if (schema.type === "object" && schema.additionalProperties) {
+            return "{ [key: string]: " + describe(spec, schema.additionalProperties, indent, seen) + " }";
+        }
+        return schema.format ? schema.type + " (" + schema.format + ")" : (schema.type || "any");
+    }
+
+    function bodySection(spec, title, content) {
+        var section = element("div");
+        Object.keys(content || {}).forEach(function (type) {
+            section.appendChild(element("h4", "", title + " " + type));
+            section.appendChild(element("pre", "", describe(spec, content[type].schema, "", [])));
+        });
+        return section;
+    }
+
+    function renderOperation(spec, path, method, operation) {
+        var details = element("details");
+        var summary = element("summary");
+        summary.appendChild(element("span", "method " + method, method.toUpperCase()));
+        summary.appendChild(element("span", "path", path));
+        summary.appendChild(document.createTextNode(" " + (operation.summary || "")));
+        summary.appendChild(element("span", "roles", operation.description || ""));
+        details.appendChild(summary);
+
+        var body = element("div");
+        if (operation.parameters) {
+            var table = element("table");
+            var header = element("tr");
+            ["Parameter", "In", "Type", "Description"].forEach(function (title) {
+                header.appendChild(element("th", "", title));
+            });
+            table.appendChild(header);
+            operation.parameters.forEach(function (parameter) {
+                var row = element("tr");
+                row.appendChild(element("td", "path", parameter.name + (parameter.required ? " *" : "")));
+                row.appendChild(element("td", "", parameter.in));
+                row.appendChild(element("td", "", describe(spec, parameter.schema, "", [])));
+                row.appendChild(element("td", "", parameter.description || ""));
+                table.appendChild(row);
+            });
+            body.appendChild(table);
+        }
+        if (operation.requestBody) {
+            body.appendChild(bodySection(spec, "Request", operation.requestBody.content));
+        }
+        Object.keys(operation.responses || {}).forEach(function (status) {
+            var response = operation.responses[status];
+            body.appendChild(element("h4", "", "Response " + status + ": " + response.description));
+            body.appendChild(bodySection(spec, "", response.content));
+        });
+        details.appendChild(body);
+        return details;
+    }
+
+    function render(spec) {
+        document.getElementById("description").textContent = spec.info.description;
+        var byTag = {};
+        Object.keys(spec.paths).sort().forEach(function (path) {
+            methods.forEach(function (method) {
+                var operation = spec.paths[path][method];
+                if (operation) {
+                    var tag = (operation.tags || ["other"])[0];
+                    (byTag[tag] = byTag[tag] || []).push(renderOperation(spec, path, method, operation));
+                }
+            });
+        });
+
+        var container = document.getElementById("operations");
+        container.textContent = "";
+        Object.keys(byTag).sort().forEach(function (tag) {
+            container.appendChild(element("h2", "", tag));
+            byTag[tag].forEach(function (operation) {
+                container.appendChild(operation);
+            });
+        });
+    }
+
+    fetch("/openapi.json")
+        .then(function (response) {
+            return response.json();
+        })
+        .then(render)
+        .catch(function (error) {
+            document.getElementById("operations").textContent = "Error loading the spec: " + error;
+        });
+})();
@@ -1,7 +1,11 @@
 package handlers
 
 import (
+	"encoding/json"
+	"fmt"
 	"github.com/davide/ModRepository/controllers"
+	"github.com/davide/ModRepository/models"
+	"github.com/gorilla/mux"
 	"net/http"
 )
 
@@ -9,6 +13,11 @@ type AuthorHandlerImpl struct {
 	AuthorsCtrl controllers.AuthorController
 }
 
+type AuthorUpdateRequest struct {
+	Name   string        `json:"name"`
+	Author models.Author `json:"author"`
+}
+
 func (a AuthorHandlerImpl) GETAllAuthors(writer http.ResponseWriter, request *http.Request) {
 	if authors, err := a.AuthorsCtrl.GetAllAuthors(); err != nil {
 		respondError(writer, http.StatusInternalServerError, err)
@@ -32,3 +41,46 @@ func (a AuthorHandlerImpl) GETCarAuthors(writer http.ResponseWriter, request *ht
 		respondJSON(writer, http.StatusOK, authors)
 	}
 }
+
+func (a AuthorHandlerImpl) GETAuthor(writer http.ResponseWriter, request *http.Request) {
+	name := mux.Vars(request)["name"]
+
+	if author, err := a.AuthorsCtrl.GetAuthor(name, models.Role(request.Header.Get("Role"))); err != nil {
+		respondError(writer, http.StatusInternalServerError, err)
+	} else {
+		respondJSON(writer, http.StatusOK, author)
+	}
+}
+
+func (a AuthorHandlerImpl) UPDATEAuthor(writer http.ResponseWriter, request *http.Request) {
+	updateReq := AuthorUpdateRequest{}
+
+	if err := json.NewDecoder(request.Body).Decode(&updateReq); err != nil {
+		respondError(writer, http.StatusBadRequest, fmt.Errorf("error converting post form to entiy: %v ", err))
+		return
+	}
+
+	author, err := a.AuthorsCtrl.UpdateAuthor(updateReq.Name, updateReq.Author)
+	if err != nil {
+		respondError(writer, http.StatusInternalServerError, fmt.Errorf("error updating the author: %w", err))
+		return
+	}
+
+	respondJSON(writer, http.StatusOK, author)
+}
+
+func (a AuthorHandlerImpl) MERGEAuthors(writer http.ResponseWriter, request *http.Request) {
+	mergeReq := MergeRequest{}
+
+	if err := json.NewDecoder(request.Body).Decode(&mergeReq); err != nil {
+		respondError(writer, http.StatusBadRequest, fmt.Errorf("error converting post form to entiy: %v ", err))
+		return
+	}
+
+	if err := a.AuthorsCtrl.MergeAuthors(mergeReq.Source, mergeReq.Target); err != nil {
+		respondError(writer, http.StatusInternalServerError, fmt.Errorf("error merging the authors: %w", err))
+		return
+	}
+
+	respondJSON(writer, http.StatusOK, "authors merged successfully")
+}
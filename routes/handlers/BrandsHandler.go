@@ -1,7 +1,11 @@
 package handlers
 
 import (
+	"encoding/json"
+	"fmt"
 	"github.com/davide/ModRepository/controllers"
+	"github.com/davide/ModRepository/models"
+	"github.com/gorilla/mux"
 	"net/http"
 )
 
@@ -9,6 +13,16 @@ type BrandsHandlerImpl struct {
 	BrandCtrl controllers.BrandController
 }
 
+type BrandUpdateRequest struct {
+	Name  string          `json:"name"`
+	Brand models.CarBrand `json:"brand"`
+}
+
+type MergeRequest struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+}
+
 func (b BrandsHandlerImpl) GETAllBrands(writer http.ResponseWriter, _ *http.Request) {
 	if brands, err := b.BrandCtrl.GetAllBrands(); err != nil {
 		respondError(writer, http.StatusInternalServerError, err)
@@ -16,3 +30,46 @@ func (b BrandsHandlerImpl) GETAllBrands(writer http.ResponseWriter, _ *http.Requ
 		respondJSON(writer, http.StatusOK, brands)
 	}
 }
+
+func (b BrandsHandlerImpl) GETBrand(writer http.ResponseWriter, request *http.Request) {
+	name := mux.Vars(request)["name"]
+
+	if brand, err := b.BrandCtrl.GetBrand(name, models.Role(request.Header.Get("Role"))); err != nil {
+		respondError(writer, http.StatusInternalServerError, err)
+	} else {
+		respondJSON(writer, http.StatusOK, brand)
+	}
+}
+
+func (b BrandsHandlerImpl) UPDATEBrand(writer http.ResponseWriter, request *http.Request) {
+	updateReq := BrandUpdateRequest{}
+
+	if err := json.NewDecoder(request.Body).Decode(&updateReq); err != nil {
+		respondError(writer, http.StatusBadRequest, fmt.Errorf("error converting post form to entiy: %v ", err))
+		return
+	}
+
+	brand, err := b.BrandCtrl.UpdateBrand(updateReq.Name, updateReq.Brand)
+	if err != nil {
+		respondError(writer, http.StatusInternalServerError, fmt.Errorf("error updating the brand: %w", err))
+		return
+	}
+
+	respondJSON(writer, http.StatusOK, brand)
+}
+
+func (b BrandsHandlerImpl) MERGEBrands(writer http.ResponseWriter, request *http.Request) {
+	mergeReq := MergeRequest{}
+
+	if err := json.NewDecoder(request.Body).Decode(&mergeReq); err != nil {
+		respondError(writer, http.StatusBadRequest, fmt.Errorf("error converting post form to entiy: %v ", err))
+		return
+	}
+
+	if err := b.BrandCtrl.MergeBrands(mergeReq.Source, mergeReq.Target); err != nil {
+		respondError(writer, http.StatusInternalServerError, fmt.Errorf("error merging the brands: %w", err))
+		return
+	}
+
+	respondJSON(writer, http.StatusOK, "brands merged successfully")
+}
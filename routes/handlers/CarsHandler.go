@@ -10,9 +10,8 @@ import (
 )
 
 type CarsHandlerImpl struct {
-	CarCtrl        controllers.CarController
-	FirebaseCtrl   controllers.FirebaseController
-	DiscordBotCtrl controllers.DiscordBotController
+	CarCtrl  controllers.CarController
+	LiveCtrl controllers.LiveController
 }
 
 func (c CarsHandlerImpl) GETAllCarCategories(writer http.ResponseWriter, _ *http.Request) {
@@ -44,16 +43,13 @@ func (c CarsHandlerImpl) POSTNewCar(writer http.ResponseWriter, request *http.Re
 	}
 
 	if err := c.CarCtrl.AddCar(&car); err != nil {
-		respondError(writer, http.StatusInternalServerError, fmt.Errorf("cannot insert new entity: %v ", err))
+		respondError(writer, http.StatusInternalServerError, fmt.Errorf("cannot insert new entity: %w ", err))
 		return
 	}
 
-	//c.FirebaseCtrl.NotifyCarAdded(car)
-	if !car.Official {
-		go c.DiscordBotCtrl.NotifyCarAdded(car)
-	}
+	c.LiveCtrl.Publish(models.CarAddedEvent, car)
 
-	respondJSON(writer, http.StatusCreated, car)
+	respondCreated(writer, fmt.Sprintf("/v2/cars/%v", car.Id), car)
 }
 
 func (c CarsHandlerImpl) UPDATECar(writer http.ResponseWriter, request *http.Request) {
@@ -66,12 +62,15 @@ func (c CarsHandlerImpl) UPDATECar(writer http.ResponseWriter, request *http.Req
 		return
 	}
 
-	if versionChange, err := c.CarCtrl.UpdateCar(car); err != nil {
-		respondError(writer, http.StatusInternalServerError, fmt.Errorf("cannot insert new entity: %v ", err))
+	c.updateCarResponse(car, writer)
+}
+
+func (c CarsHandlerImpl) updateCarResponse(car models.Car, writer http.ResponseWriter) {
+	if _, err := c.CarCtrl.UpdateCar(car); err != nil {
+		respondError(writer, http.StatusInternalServerError, fmt.Errorf("cannot insert new entity: %w ", err))
 		return
-	} else if versionChange && !car.Official {
-		go c.DiscordBotCtrl.NotifyCarUpdated(car)
 	}
+	c.LiveCtrl.Publish(models.CarUpdatedEvent, car)
 
 	respondJSON(writer, http.StatusOK, car)
 }
@@ -86,12 +85,116 @@ func (c CarsHandlerImpl) getCarsByParamResponse(paramName string, getCars getCar
 	}
 
 	if cars, err := getCars(param); err != nil {
-		if err.Error() == "not found" {
-			respondError(writer, http.StatusNotFound, err)
-		} else {
-			respondError(writer, http.StatusInternalServerError, err)
-		}
+		respondError(writer, http.StatusInternalServerError, err)
 	} else {
 		respondJSON(writer, http.StatusOK, cars)
 	}
 }
+
+func (c CarsHandlerImpl) GETRelatedCars(writer http.ResponseWriter, request *http.Request) {
+	carId, err := idParam(request, "id")
+	if err != nil {
+		respondError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	limit, err := limitParam(request)
+	if err != nil {
+		respondError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	if related, err := c.CarCtrl.GetRelatedCars(carId, models.Role(request.Header.Get("Role")), limit); err != nil {
+		respondError(writer, http.StatusInternalServerError, err)
+	} else {
+		respondJSON(writer, http.StatusOK, related)
+	}
+}
+
+func (c CarsHandlerImpl) GETSimilarCars(writer http.ResponseWriter, request *http.Request) {
+	carId, err := idParam(request, "id")
+	if err != nil {
+		respondError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	limit, err := limitParam(request)
+	if err != nil {
+		respondError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	if similar, err := c.CarCtrl.GetSimilarCars(carId, models.Role(request.Header.Get("Role")), limit); err != nil {
+		respondError(writer, http.StatusInternalServerError, err)
+	} else {
+		respondJSON(writer, http.StatusOK, similar)
+	}
+}
+
+func (c CarsHandlerImpl) GETCar(writer http.ResponseWriter, request *http.Request) {
+	carId, err := idParam(request, "id")
+	if err != nil {
+		respondError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	if car, err := c.CarCtrl.GetCar(carId, models.Role(request.Header.Get("Role"))); err != nil {
+		respondError(writer, http.StatusInternalServerError, err)
+	} else {
+		respondJSON(writer, http.StatusOK, car)
+	}
+}
+
+// PUTCar replaces the whole car, the id in the path wins over the one in the body
+func (c CarsHandlerImpl) PUTCar(writer http.ResponseWriter, request *http.Request) {
+	carId, err := idParam(request, "id")
+	if err != nil {
+		respondError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	car := models.Car{}
+	if err := json.NewDecoder(request.Body).Decode(&car); err != nil {
+		respondError(writer, http.StatusBadRequest, fmt.Errorf("error converting post form to entiy: %v ", err))
+		return
+	}
+	car.Id = carId
+
+	c.updateCarResponse(car, writer)
+}
+
+// PATCHCar updates only the fields present in the body
+func (c CarsHandlerImpl) PATCHCar(writer http.ResponseWriter, request *http.Request) {
+	carId, err := idParam(request, "id")
+	if err != nil {
+		respondError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	car, err := c.CarCtrl.GetCar(carId, models.Admin)
+	if err != nil {
+		respondError(writer, http.StatusInternalServerError, err)
+		return
+	}
+	if err := json.NewDecoder(request.Body).Decode(&car); err != nil {
+		respondError(writer, http.StatusBadRequest, fmt.Errorf("error converting post form to entiy: %v ", err))
+		return
+	}
+	car.Id = carId
+
+	c.updateCarResponse(car, writer)
+}
+
+func (c CarsHandlerImpl) DELETECar(writer http.ResponseWriter, request *http.Request) {
+	carId, err := idParam(request, "id")
+	if err != nil {
+		respondError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := c.CarCtrl.DeleteCar(carId); err != nil {
+		respondError(writer, http.StatusInternalServerError, fmt.Errorf("error deleting the car: %w", err))
+	} else {
+		writer.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/davide/ModRepository/controllers"
+	"github.com/davide/ModRepository/models"
+	"net/http"
+)
+
+type DiscordHandlerImpl struct {
+	Ctrl      controllers.DiscordBotController
+	CarCtrl   controllers.CarController
+	TrackCtrl controllers.TrackController
+}
+
+// EmbedPreviewRequest renders the embed of Event for a mod, with Template when given or with the template in use
+type EmbedPreviewRequest struct {
+	Event    models.LiveEventType  `json:"event"`
+	ModId    uint                  `json:"modId"`
+	Template *models.EmbedTemplate `json:"template"`
+}
+
+func (d DiscordHandlerImpl) POSTEmbedPreview(writer http.ResponseWriter, request *http.Request) {
+	preview := EmbedPreviewRequest{}
+	if err := json.NewDecoder(request.Body).Decode(&preview); err != nil {
+		respondError(writer, http.StatusBadRequest, fmt.Errorf("error converting post form to entiy: %v ", err))
+		return
+	}
+
+	var mod interface{}
+	var err error
+	switch preview.Event {
+	case models.CarAddedEvent, models.CarUpdatedEvent:
+		mod, err = d.CarCtrl.GetCar(preview.ModId, models.Admin)
+	case models.TrackAddedEvent, models.TrackUpdatedEvent:
+		mod, err = d.TrackCtrl.GetTrack(preview.ModId, models.Admin)
+	default:
+		respondError(writer, http.StatusBadRequest, models.NewValidationError("event", "must be one of: car_added, car_updated, track_added, track_updated"))
+		return
+	}
+	if err != nil {
+		respondError(writer, http.StatusInternalServerError, fmt.Errorf("error loading the mod: %w", err))
+		return
+	}
+
+	if embed, err := d.Ctrl.PreviewEmbed(preview.Event, preview.Template, mod); err != nil {
+		respondError(writer, http.StatusInternalServerError, fmt.Errorf("error rendering the embed: %w", err))
+	} else {
+		respondJSON(writer, http.StatusOK, embed)
+	}
+}
@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"encoding/xml"
+	"fmt"
+	"github.com/davide/ModRepository/controllers"
+	"github.com/davide/ModRepository/controllers/helpers"
+	"github.com/davide/ModRepository/models"
+	"github.com/gorilla/mux"
+	"html"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultFeedLimit = 50
+	maxFeedLimit     = 200
+)
+
+type FeedsHandlerImpl struct {
+	Ctrl controllers.FeedController
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Id      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+	Uri  string `xml:"uri,omitempty"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomText struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+type atomEntry struct {
+	Id         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Updated    string         `xml:"updated"`
+	Links      []atomLink     `xml:"link"`
+	Author     atomAuthor     `xml:"author"`
+	Categories []atomCategory `xml:"category"`
+	Content    atomText       `xml:"content"`
+}
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	DcNs    string     `xml:"xmlns:dc,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssGuid struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssEnclosure struct {
+	Url    string `xml:"url,attr"`
+	Length int    `xml:"length,attr"`
+	Type   string `xml:"type,attr"`
+}
+
+type rssItem struct {
+	Title       string        `xml:"title"`
+	Link        string        `xml:"link"`
+	Guid        rssGuid       `xml:"guid"`
+	PubDate     string        `xml:"pubDate"`
+	Creator     string        `xml:"dc:creator"`
+	Categories  []string      `xml:"category"`
+	Enclosure   *rssEnclosure `xml:"enclosure"`
+	Description string        `xml:"description"`
+}
+
+// GETFeed serves /feed/{kind}.{format}, kind is cars, tracks or all and format atom or rss
+func (f FeedsHandlerImpl) GETFeed(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	var modType models.ModType
+	switch vars["kind"] {
+	case "cars":
+		modType = models.CarModType
+	case "tracks":
+		modType = models.TrackModType
+	}
+
+	limit := defaultFeedLimit
+	if param := r.URL.Query().Get("limit"); param != "" {
+		value, err := strconv.Atoi(param)
+		if err != nil || value < 1 {
+			respondError(w, http.StatusBadRequest, models.NewValidationError("limit", "must be a positive number"))
+			return
+		}
+		if value < maxFeedLimit {
+			limit = value
+		} else {
+			limit = maxFeedLimit
+		}
+	}
+
+	query := r.URL.Query()
+	filter := models.FeedFilter{
+		Category: query.Get("category"),
+		Tag:      query.Get("tag"),
+		Brand:    query.Get("brand"),
+		Author:   query.Get("author"),
+	}
+	entries, err := f.Ctrl.GetFeed(modType, filter, limit)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, fmt.Errorf("error building the feed: %w", err))
+		return
+	}
+
+	title := "AC Mod Repository"
+	switch modType {
+	case models.CarModType:
+		title += " - Cars"
+	case models.TrackModType:
+		title += " - Tracks"
+	}
+	self := "https://" + r.Host + r.URL.RequestURI()
+
+	var feed interface{}
+	if vars["format"] == "rss" {
+		w.Header().Set("Content-Type", "application/rss+xml; charset=utf-8")
+		feed = rssFromEntries(title, entries)
+	} else {
+		w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+		feed = atomFromEntries(title, self, entries)
+	}
+	body, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, fmt.Errorf("error encoding the feed: %w", err))
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(xml.Header))
+	w.Write(body)
+}
+
+// entryHtml is the body of an entry, feed readers show the image and the version from here
+func entryHtml(entry models.FeedEntry) string {
+	var body strings.Builder
+	if entry.ImageUrl != "" {
+		fmt.Fprintf(&body, `<p><img src="%v" alt="%v"/></p>`, html.EscapeString(entry.ImageUrl), html.EscapeString(entry.Title))
+	}
+	fmt.Fprintf(&body, "<p>Version: %v</p><p>Author: %v</p>", html.EscapeString(entry.Version), html.EscapeString(entry.Author.Name))
+	if len(entry.Categories) > 0 {
+		fmt.Fprintf(&body, "<p>%v</p>", html.EscapeString(strings.Join(entry.Categories, ", ")))
+	}
+	if entry.Premium {
+		body.WriteString("<p>Premium</p>")
+	}
+	return body.String()
+}
+
+func imageType(url string) string {
+	if contentType := mime.TypeByExtension(path.Ext(url)); strings.HasPrefix(contentType, "image/") {
+		return contentType
+	}
+	return "image/jpeg"
+}
+
+func atomFromEntries(title string, self string, entries []models.FeedEntry) atomFeed {
+	feed := atomFeed{
+		Id:      self,
+		Title:   title,
+		Updated: time.Now().UTC().Format(time.RFC3339),
+		Links: []atomLink{
+			{Href: self, Rel: "self", Type: "application/atom+xml"},
+			{Href: helpers.SiteUrl, Rel: "alternate"},
+		},
+	}
+	if len(entries) > 0 {
+		feed.Updated = entries[0].HappenedAt.UTC().Format(time.RFC3339)
+	}
+	for _, entry := range entries {
+		atom := atomEntry{
+			Id:      "tag:acmodrepository.com,2021:" + entry.Id,
+			Title:   entry.Title,
+			Updated: entry.HappenedAt.UTC().Format(time.RFC3339),
+			Links:   []atomLink{{Href: entry.Link, Rel: "alternate", Type: "text/html"}},
+			Author:  atomAuthor{Name: entry.Author.Name, Uri: entry.Author.Link},
+			Content: atomText{Type: "html", Body: entryHtml(entry)},
+		}
+		if entry.ImageUrl != "" {
+			atom.Links = append(atom.Links, atomLink{Href: entry.ImageUrl, Rel: "enclosure", Type: imageType(entry.ImageUrl)})
+		}
+		for _, category := range entry.Categories {
+			atom.Categories = append(atom.Categories, atomCategory{Term: category})
+		}
+		feed.Entries = append(feed.Entries, atom)
+	}
+	return feed
+}
+
+func rssFromEntries(title string, entries []models.FeedEntry) rssFeed {
+	feed := rssFeed{
+		Version: "2.0",
+		DcNs:    "http://purl.org/dc/elements/1.1/",
+		Channel: rssChannel{
+			Title:       title,
+			Link:        helpers.SiteUrl,
+			Description: "New and updated mods on " + title,
+		},
+	}
+	if len(entries) > 0 {
+		feed.Channel.LastBuildDate = entries[0].HappenedAt.UTC().Format(time.RFC1123Z)
+	}
+	for _, entry := range entries {
+		item := rssItem{
+			Title:       entry.Title,
+			Link:        entry.Link,
+			Guid:        rssGuid{Value: "tag:acmodrepository.com,2021:" + entry.Id},
+			PubDate:     entry.HappenedAt.UTC().Format(time.RFC1123Z),
+			Creator:     entry.Author.Name,
+			Categories:  entry.Categories,
+			Description: entryHtml(entry),
+		}
+		if entry.ImageUrl != "" {
+			item.Enclosure = &rssEnclosure{Url: entry.ImageUrl, Type: imageType(entry.ImageUrl)}
+		}
+		feed.Channel.Items = append(feed.Channel.Items, item)
+	}
+	return feed
+}
@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/davide/ModRepository/controllers"
+	"github.com/davide/ModRepository/controllers/helpers"
+	"github.com/davide/ModRepository/models"
 	"net/http"
 )
 
@@ -26,11 +28,50 @@ func (f FirebaseHandlerImpl) SubscribeToTopic(writer http.ResponseWriter, reques
 		return
 	}
 
-	if err := f.Ctrl.RegisterToTopic(regReq.RegistrationToken, regReq.Topic); err != nil {
-		respondError(writer, http.StatusBadRequest, fmt.Errorf("error registrating token to topic: %v ", err))
+	if err := f.Ctrl.RegisterToTopic(request.Header.Get("Username"), regReq.RegistrationToken, regReq.Topic); err != nil {
+		respondError(writer, http.StatusBadRequest, fmt.Errorf("error registrating token to topic: %w ", err))
 		return
 	}
 
 	respondJSON(writer, http.StatusOK, "registration successful")
 
 }
+
+// UnsubscribeFromTopic removes a subscription made with the same token, anonymous devices can only remove
+// the anonymous ones
+func (f FirebaseHandlerImpl) UnsubscribeFromTopic(writer http.ResponseWriter, request *http.Request) {
+	unsubReq := SubscribeRequest{}
+
+	if err := json.NewDecoder(request.Body).Decode(&unsubReq); err != nil {
+		respondError(writer, http.StatusBadRequest, fmt.Errorf("error converting post form to entiy: %v ", err))
+		return
+	}
+
+	if err := f.Ctrl.UnregisterFromTopic(request.Header.Get("Username"), unsubReq.RegistrationToken, unsubReq.Topic); err != nil {
+		respondError(writer, http.StatusBadRequest, fmt.Errorf("error unregistering token from topic: %w", err))
+		return
+	}
+
+	respondJSON(writer, http.StatusOK, "unregistration successful")
+}
+
+// GETSubscriptions lists the devices of the logged user and the topics they follow
+func (f FirebaseHandlerImpl) GETSubscriptions(writer http.ResponseWriter, request *http.Request) {
+	username := request.Header.Get("Username")
+	if username == "" {
+		respondError(writer, http.StatusForbidden, fmt.Errorf("%w: log in to list the subscriptions", models.ErrForbidden))
+		return
+	}
+
+	devices, err := f.Ctrl.GetDevices(username)
+	if err != nil {
+		respondError(writer, http.StatusInternalServerError, fmt.Errorf("error retrieving the subscriptions: %w", err))
+		return
+	}
+	respondJSON(writer, http.StatusOK, devices)
+}
+
+// GETTopics lists the topics a device can subscribe to
+func (f FirebaseHandlerImpl) GETTopics(writer http.ResponseWriter, _ *http.Request) {
+	respondJSON(writer, http.StatusOK, helpers.TopicScheme())
+}
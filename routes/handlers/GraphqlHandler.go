@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"github.com/davide/ModRepository/models"
+	"github.com/graph-gophers/graphql-go"
+	"net/http"
+)
+
+//go:embed schema.graphql
+var graphqlSchema string
+
+type GraphqlRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+type GraphqlHandlerImpl struct {
+	Resolver GraphqlResolver
+	schema   *graphql.Schema
+}
+
+func NewGraphqlHandler(resolver GraphqlResolver) GraphqlHandlerImpl {
+	return GraphqlHandlerImpl{
+		Resolver: resolver,
+		schema:   graphql.MustParseSchema(graphqlSchema, &resolver, graphql.MaxDepth(8)),
+	}
+}
+
+func (g GraphqlHandlerImpl) POSTQuery(w http.ResponseWriter, r *http.Request) {
+	var request GraphqlRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		respondError(w, http.StatusBadRequest, fmt.Errorf("error converting post form to entiy: %v ", err))
+		return
+	}
+
+	ctx := withGraphqlLoader(r.Context(), g.Resolver, models.Role(r.Header.Get("Role")))
+	respondJSON(w, http.StatusOK, g.schema.Exec(ctx, request.Query, request.OperationName, request.Variables))
+}
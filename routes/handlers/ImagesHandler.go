@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/davide/ModRepository/controllers"
+	"github.com/davide/ModRepository/models"
+	"github.com/gorilla/mux"
+	"io"
+	"net/http"
+)
+
+const maxImageSize = 20 << 20
+
+type ImagesHandlerImpl struct {
+	Ctrl controllers.ImageController
+}
+
+type ReorderRequest struct {
+	Order []uint `json:"order"`
+}
+
+type uploadImage func(content []byte) (models.Image, error)
+
+// readImageUpload reads the "image" file of a multipart form
+func readImageUpload(writer http.ResponseWriter, request *http.Request) ([]byte, error) {
+	request.Body = http.MaxBytesReader(writer, request.Body, maxImageSize+1<<20)
+	if err := request.ParseMultipartForm(maxImageSize); err != nil {
+		return nil, fmt.Errorf("error parsing the upload: %v", err)
+	}
+	file, _, err := request.FormFile("image")
+	if err != nil {
+		return nil, fmt.Errorf("missing 'image' file: %v", err)
+	}
+	defer file.Close()
+	return io.ReadAll(file)
+}
+
+// modParams reads the mod type and id of the gallery routes
+func modParams(request *http.Request) (models.ModType, uint, error) {
+	modId, err := idParam(request, "id")
+	if err != nil {
+		return "", 0, err
+	}
+	return models.ModType(mux.Vars(request)["modType"]), modId, nil
+}
+
+func respondImageError(writer http.ResponseWriter, err error) {
+	respondError(writer, http.StatusInternalServerError, fmt.Errorf("error processing the image: %w", err))
+}
+
+func (i ImagesHandlerImpl) uploadImageResponse(upload uploadImage, writer http.ResponseWriter, request *http.Request) {
+	content, err := readImageUpload(writer, request)
+	if err != nil {
+		respondError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	if image, err := upload(content); err != nil {
+		respondImageError(writer, err)
+	} else {
+		respondJSON(writer, http.StatusCreated, image)
+	}
+}
+
+func (i ImagesHandlerImpl) UPLOADImage(writer http.ResponseWriter, request *http.Request) {
+	modType, modId, err := modParams(request)
+	if err != nil {
+		respondError(writer, http.StatusBadRequest, err)
+		return
+	}
+	i.uploadImageResponse(func(content []byte) (models.Image, error) {
+		return i.Ctrl.UploadImage(modType, modId, content, models.Image{
+			Favorite: request.FormValue("favorite") == "true",
+			Caption:  request.FormValue("caption"),
+			AltText:  request.FormValue("altText"),
+		})
+	}, writer, request)
+}
+
+func (i ImagesHandlerImpl) UPLOADSkinImage(writer http.ResponseWriter, request *http.Request) {
+	skinId, err := idParam(request, "id")
+	if err != nil {
+		respondError(writer, http.StatusBadRequest, err)
+		return
+	}
+	i.uploadImageResponse(func(content []byte) (models.Image, error) {
+		return i.Ctrl.UploadSkinImage(skinId, content)
+	}, writer, request)
+}
+
+func (i ImagesHandlerImpl) UPLOADBrandLogo(writer http.ResponseWriter, request *http.Request) {
+	name := mux.Vars(request)["name"]
+	i.uploadImageResponse(func(content []byte) (models.Image, error) {
+		return i.Ctrl.UploadBrandLogo(name, content)
+	}, writer, request)
+}
+
+func (i ImagesHandlerImpl) GETImages(writer http.ResponseWriter, request *http.Request) {
+	modType, modId, err := modParams(request)
+	if err != nil {
+		respondError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	if images, err := i.Ctrl.GetImages(modType, modId); err != nil {
+		respondImageError(writer, err)
+	} else {
+		respondJSON(writer, http.StatusOK, images)
+	}
+}
+
+func (i ImagesHandlerImpl) ADDImage(writer http.ResponseWriter, request *http.Request) {
+	modType, modId, err := modParams(request)
+	if err != nil {
+		respondError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	image := models.Image{}
+	if err := json.NewDecoder(request.Body).Decode(&image); err != nil {
+		respondError(writer, http.StatusBadRequest, fmt.Errorf("error converting post form to entiy: %v ", err))
+		return
+	}
+
+	if image, err := i.Ctrl.AddImage(modType, modId, image); err != nil {
+		respondImageError(writer, err)
+	} else {
+		respondJSON(writer, http.StatusCreated, image)
+	}
+}
+
+func (i ImagesHandlerImpl) UPDATEImage(writer http.ResponseWriter, request *http.Request) {
+	modType, modId, err := modParams(request)
+	if err != nil {
+		respondError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	image := models.Image{}
+	if err := json.NewDecoder(request.Body).Decode(&image); err != nil {
+		respondError(writer, http.StatusBadRequest, fmt.Errorf("error converting post form to entiy: %v ", err))
+		return
+	}
+
+	if err := i.Ctrl.UpdateImage(modType, modId, image); err != nil {
+		respondImageError(writer, err)
+	} else {
+		respondJSON(writer, http.StatusOK, image)
+	}
+}
+
+func (i ImagesHandlerImpl) DELETEImage(writer http.ResponseWriter, request *http.Request) {
+	modType, modId, err := modParams(request)
+	if err != nil {
+		respondError(writer, http.StatusBadRequest, err)
+		return
+	}
+	imageId, err := idParam(request, "imageId")
+	if err != nil {
+		respondError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := i.Ctrl.RemoveImage(modType, modId, imageId); err != nil {
+		respondImageError(writer, err)
+	} else {
+		respondJSON(writer, http.StatusOK, "image deleted successfully")
+	}
+}
+
+func (i ImagesHandlerImpl) REORDERImages(writer http.ResponseWriter, request *http.Request) {
+	modType, modId, err := modParams(request)
+	if err != nil {
+		respondError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	reorderReq := ReorderRequest{}
+	if err := json.NewDecoder(request.Body).Decode(&reorderReq); err != nil {
+		respondError(writer, http.StatusBadRequest, fmt.Errorf("error converting post form to entiy: %v ", err))
+		return
+	}
+
+	if err := i.Ctrl.ReorderImages(modType, modId, reorderReq.Order); err != nil {
+		respondImageError(writer, err)
+	} else {
+		respondJSON(writer, http.StatusOK, reorderReq.Order)
+	}
+}
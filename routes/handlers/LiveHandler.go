@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/davide/ModRepository/controllers"
+	"github.com/davide/ModRepository/models"
+	"github.com/gorilla/websocket"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	liveHeartbeat = 30 * time.Second
+	// livePongWait must be longer than the heartbeat, a client that doesn't answer two pings is dropped
+	livePongWait   = 2 * liveHeartbeat
+	liveWriteWait  = 10 * time.Second
+	liveRetryAfter = 5 * time.Second
+)
+
+var liveUpgrader = websocket.Upgrader{
+	// the clients authenticate with their token, not with cookies, so any origin can connect
+	CheckOrigin: func(*http.Request) bool { return true },
+}
+
+type LiveHandlerImpl struct {
+	Ctrl controllers.LiveController
+}
+
+// liveCursor is the id of the last event received by the client, sent back on reconnection as the since
+// query param or, by EventSource, as the Last-Event-ID header
+func liveCursor(r *http.Request) (uint64, error) {
+	cursor := r.URL.Query().Get("since")
+	if cursor == "" {
+		cursor = r.Header.Get("Last-Event-ID")
+	}
+	if cursor == "" {
+		return 0, nil
+	}
+	value, err := strconv.ParseUint(cursor, 10, 64)
+	if err != nil {
+		return 0, models.NewValidationError("since", "must be the id of an event")
+	}
+	return value, nil
+}
+
+// GETLive streams the catalog changes over a WebSocket, or as Server-Sent Events when the client doesn't upgrade
+func (l LiveHandlerImpl) GETLive(w http.ResponseWriter, r *http.Request) {
+	cursor, err := liveCursor(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	role := models.Role(r.Header.Get("Role"))
+
+	if websocket.IsWebSocketUpgrade(r) {
+		l.serveWebSocket(w, r, role, cursor)
+	} else {
+		l.serveEvents(w, r, role, cursor)
+	}
+}
+
+func (l LiveHandlerImpl) serveWebSocket(w http.ResponseWriter, r *http.Request, role models.Role, cursor uint64) {
+	conn, err := liveUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("error upgrading the live connection: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	subscription := l.Ctrl.Subscribe(role, cursor)
+	defer l.Ctrl.Unsubscribe(subscription)
+
+	// the reads only process pongs and close frames, they stop when the client goes away
+	closed := make(chan struct{})
+	conn.SetReadLimit(512)
+	_ = conn.SetReadDeadline(time.Now().Add(livePongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(livePongWait))
+	})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	heartbeat := time.NewTicker(liveHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case event, ok := <-subscription.Events:
+			_ = conn.SetWriteDeadline(time.Now().Add(liveWriteWait))
+			if !ok {
+				_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "too slow, reconnect with the last event id"))
+				return
+			}
+			if err := conn.WriteJSON(event); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(liveWriteWait)); err != nil {
+				return
+			}
+		case <-closed:
+			return
+		}
+	}
+}
+
+func (l LiveHandlerImpl) serveEvents(w http.ResponseWriter, r *http.Request, role models.Role, cursor uint64) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		respondError(w, http.StatusInternalServerError, fmt.Errorf("streaming is not supported"))
+		return
+	}
+
+	subscription := l.Ctrl.Subscribe(role, cursor)
+	defer l.Ctrl.Unsubscribe(subscription)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "retry: %d\n\n", liveRetryAfter.Milliseconds())
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(liveHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case event, ok := <-subscription.Events:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				log.Printf("error encoding the live event %v: %v", event.Id, err)
+				continue
+			}
+			fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.Id, event.Type, data)
+			flusher.Flush()
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/davide/ModRepository/controllers"
+	"github.com/davide/ModRepository/models"
+	"net/http"
+)
+
+type ModUpdatesHandlerImpl struct {
+	Ctrl controllers.ModUpdateController
+}
+
+func (m ModUpdatesHandlerImpl) POSTModUpdate(writer http.ResponseWriter, request *http.Request) {
+	update := models.ModUpdate{}
+
+	if err := json.NewDecoder(request.Body).Decode(&update); err != nil {
+		respondError(writer, http.StatusBadRequest, fmt.Errorf("error converting post form to entiy: %v ", err))
+		return
+	}
+
+	if update, err := m.Ctrl.SubmitUpdate(update, request.Header.Get("Username"), models.Role(request.Header.Get("Role"))); err != nil {
+		respondError(writer, http.StatusInternalServerError, fmt.Errorf("error submitting the update: %w", err))
+	} else if update.Status == models.PendingUpdate {
+		respondJSON(writer, http.StatusAccepted, update)
+	} else {
+		respondJSON(writer, http.StatusOK, update)
+	}
+}
+
+func (m ModUpdatesHandlerImpl) GETPendingModUpdates(writer http.ResponseWriter, _ *http.Request) {
+	if updates, err := m.Ctrl.GetPendingUpdates(); err != nil {
+		respondError(writer, http.StatusInternalServerError, err)
+	} else {
+		respondJSON(writer, http.StatusOK, updates)
+	}
+}
+
+func (m ModUpdatesHandlerImpl) APPROVEModUpdate(writer http.ResponseWriter, request *http.Request) {
+	id, err := idParam(request, "id")
+	if err != nil {
+		respondError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	if update, err := m.Ctrl.ApproveUpdate(id); err != nil {
+		respondError(writer, http.StatusInternalServerError, fmt.Errorf("error approving the update: %w", err))
+	} else {
+		respondJSON(writer, http.StatusOK, update)
+	}
+}
+
+func (m ModUpdatesHandlerImpl) REJECTModUpdate(writer http.ResponseWriter, request *http.Request) {
+	id, err := idParam(request, "id")
+	if err != nil {
+		respondError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := m.Ctrl.RejectUpdate(id); err != nil {
+		respondError(writer, http.StatusInternalServerError, fmt.Errorf("error rejecting the update: %w", err))
+	} else {
+		respondJSON(writer, http.StatusOK, "update rejected")
+	}
+}
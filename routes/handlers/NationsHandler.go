@@ -1,7 +1,11 @@
 package handlers
 
 import (
+	"encoding/json"
+	"fmt"
 	"github.com/davide/ModRepository/controllers"
+	"github.com/davide/ModRepository/controllers/helpers"
+	"github.com/davide/ModRepository/models"
 	"net/http"
 )
 
@@ -9,18 +13,59 @@ type NationsHandlerImpl struct {
 	CtrlNations controllers.NationController
 }
 
-func (n NationsHandlerImpl) GETAllTracksNations(writer http.ResponseWriter, request *http.Request) {
-	if nations, err := n.CtrlNations.GetAllTracksNations(); err != nil {
+type getNationsByContinent func(models.Continent) ([]models.Nation, error)
+
+func (n NationsHandlerImpl) getNationsByContinentResponse(getNations getNationsByContinent, writer http.ResponseWriter, request *http.Request) {
+	continent := models.Continent(request.URL.Query().Get("continent"))
+
+	if continent != "" && !helpers.IsValidContinent(continent) {
+		respondError(writer, http.StatusBadRequest, fmt.Errorf("invalid param 'continent': %v", continent))
+		return
+	}
+
+	if nations, err := getNations(continent); err != nil {
 		respondError(writer, http.StatusInternalServerError, err)
 	} else {
 		respondJSON(writer, http.StatusOK, nations)
 	}
 }
 
+func (n NationsHandlerImpl) GETAllNations(writer http.ResponseWriter, request *http.Request) {
+	n.getNationsByContinentResponse(n.CtrlNations.GetAllNations, writer, request)
+}
+
+func (n NationsHandlerImpl) GETAllTracksNations(writer http.ResponseWriter, request *http.Request) {
+	n.getNationsByContinentResponse(n.CtrlNations.GetAllTracksNations, writer, request)
+}
+
 func (n NationsHandlerImpl) GETAllBrandsNations(writer http.ResponseWriter, request *http.Request) {
-	if nations, err := n.CtrlNations.GetAllBrandsNations(); err != nil {
-		respondError(writer, http.StatusInternalServerError, err)
+	n.getNationsByContinentResponse(n.CtrlNations.GetAllBrandsNations, writer, request)
+}
+
+func (n NationsHandlerImpl) GETAllContinents(writer http.ResponseWriter, _ *http.Request) {
+	respondJSON(writer, http.StatusOK, n.CtrlNations.GetAllContinents())
+}
+
+func (n NationsHandlerImpl) SEEDNations(writer http.ResponseWriter, _ *http.Request) {
+	if err := n.CtrlNations.SeedNations(); err != nil {
+		respondError(writer, http.StatusInternalServerError, fmt.Errorf("error seeding the nations: %w", err))
 	} else {
-		respondJSON(writer, http.StatusOK, nations)
+		respondJSON(writer, http.StatusOK, "nations seeded successfully")
 	}
 }
+
+func (n NationsHandlerImpl) MERGENations(writer http.ResponseWriter, request *http.Request) {
+	mergeReq := MergeRequest{}
+
+	if err := json.NewDecoder(request.Body).Decode(&mergeReq); err != nil {
+		respondError(writer, http.StatusBadRequest, fmt.Errorf("error converting post form to entiy: %v ", err))
+		return
+	}
+
+	if err := n.CtrlNations.MergeNations(mergeReq.Source, mergeReq.Target); err != nil {
+		respondError(writer, http.StatusInternalServerError, fmt.Errorf("error merging the nations: %w", err))
+		return
+	}
+
+	respondJSON(writer, http.StatusOK, "nations merged successfully")
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"fmt"
+	"github.com/davide/ModRepository/controllers"
+	"github.com/davide/ModRepository/models"
+	"net/http"
+	"strconv"
+)
+
+const (
+	defaultNotificationsLimit = 50
+	maxNotificationsLimit     = 500
+)
+
+type NotificationsHandlerImpl struct {
+	Ctrl controllers.NotificationController
+}
+
+// GETNotifications lists the outbox by status, the dead notifications by default
+func (n NotificationsHandlerImpl) GETNotifications(writer http.ResponseWriter, request *http.Request) {
+	query := request.URL.Query()
+	status := models.DeadNotification
+	if param := query.Get("status"); param != "" {
+		status = models.NotificationStatus(param)
+		valid := false
+		for _, s := range models.NotificationStatuses {
+			valid = valid || s == status
+		}
+		if !valid {
+			respondError(writer, http.StatusBadRequest, models.NewValidationError("status", "must be pending, delivered or dead"))
+			return
+		}
+	}
+
+	limit := defaultNotificationsLimit
+	if param := query.Get("limit"); param != "" {
+		value, err := strconv.Atoi(param)
+		if err != nil || value < 1 {
+			respondError(writer, http.StatusBadRequest, models.NewValidationError("limit", "must be a positive number"))
+			return
+		}
+		if value < maxNotificationsLimit {
+			limit = value
+		} else {
+			limit = maxNotificationsLimit
+		}
+	}
+
+	if notifications, err := n.Ctrl.GetNotifications(status, limit); err != nil {
+		respondError(writer, http.StatusInternalServerError, fmt.Errorf("error loading the notifications: %w", err))
+	} else {
+		respondJSON(writer, http.StatusOK, notifications)
+	}
+}
+
+func (n NotificationsHandlerImpl) REPLAYNotification(writer http.ResponseWriter, request *http.Request) {
+	id, err := idParam(request, "id")
+	if err != nil {
+		respondError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	if notification, err := n.Ctrl.ReplayNotification(id); err != nil {
+		respondError(writer, http.StatusInternalServerError, fmt.Errorf("error replaying the notification: %w", err))
+	} else {
+		respondJSON(writer, http.StatusOK, notification)
+	}
+}
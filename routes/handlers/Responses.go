@@ -2,10 +2,21 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/davide/ModRepository/models"
 	"log"
 	"net/http"
+	"strings"
 )
 
+type ErrorResponse struct {
+	Code      string              `json:"code"`
+	Message   string              `json:"message"`
+	RequestId string              `json:"requestId"`
+	Fields    []models.FieldError `json:"fields,omitempty"`
+}
+
 func respondJSON(w http.ResponseWriter, status int, payload interface{}) {
 	response, err := json.Marshal(payload)
 	if err != nil {
@@ -19,8 +30,56 @@ func respondJSON(w http.ResponseWriter, status int, payload interface{}) {
 	w.Write([]byte(response))
 }
 
-// respondError makes the error response with payload as json format
+// errorStatus maps the domain errors to their http status, any other error keeps the status chosen by the handler
+func errorStatus(err error, code int) int {
+	switch {
+	case errors.Is(err, models.ErrValidation):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, models.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, models.ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, models.ErrForbidden):
+		return http.StatusForbidden
+	}
+	return code
+}
+
+func errorCode(status int) string {
+	if status == http.StatusUnprocessableEntity {
+		return "validation_failed"
+	}
+	return strings.ReplaceAll(strings.ToLower(http.StatusText(status)), " ", "_")
+}
+
+// respondError makes the error response with the error envelope as json format
 func respondError(w http.ResponseWriter, code int, err error) {
-	respondJSON(w, code, map[string]string{"error": err.Error()})
-	log.Print(err)
+	status := errorStatus(err, code)
+	response := ErrorResponse{
+		Code:      errorCode(status),
+		Message:   err.Error(),
+		RequestId: w.Header().Get(requestIdHeader),
+	}
+	var validationErr models.ValidationError
+	if errors.As(err, &validationErr) {
+		response.Fields = validationErr.Fields
+	}
+	respondJSON(w, status, response)
+	log.Printf("%v [%v] %v", status, response.RequestId, err)
+}
+
+// NotFound answers the requests matching no route with the error envelope
+func NotFound(w http.ResponseWriter, r *http.Request) {
+	respondError(w, http.StatusNotFound, fmt.Errorf("%v %v: %w", r.Method, r.URL.Path, models.ErrNotFound))
+}
+
+// MethodNotAllowed answers the requests matching a route but none of its methods with the error envelope
+func MethodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	respondError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %v not allowed on %v", r.Method, r.URL.Path))
+}
+
+// respondCreated answers 201 with the location of the new resource
+func respondCreated(w http.ResponseWriter, location string, payload interface{}) {
+	w.Header().Set("Location", location)
+	respondJSON(w, http.StatusCreated, payload)
 }
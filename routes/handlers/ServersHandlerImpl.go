@@ -9,7 +9,8 @@ import (
 )
 
 type ServersHandlerImpl struct {
-	Ctrl controllers.ServersController
+	Ctrl     controllers.ServersController
+	LiveCtrl controllers.LiveController
 }
 
 func (s ServersHandlerImpl) ADDServer(w http.ResponseWriter, r *http.Request) {
@@ -22,13 +23,31 @@ func (s ServersHandlerImpl) ADDServer(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := s.Ctrl.AddServer(server); err != nil {
-		respondError(w, http.StatusInternalServerError, fmt.Errorf("error adding the server: %v", err))
+	if err := s.Ctrl.AddServer(&server); err != nil {
+		respondError(w, http.StatusInternalServerError, fmt.Errorf("error adding the server: %w", err))
 	} else {
+		s.publishServerChanges(models.Server{}, server)
 		respondJSON(w, http.StatusOK, server)
 	}
 }
 
+// POSTServer is ADDServer answering 201 with the location of the new server
+func (s ServersHandlerImpl) POSTServer(w http.ResponseWriter, r *http.Request) {
+	server := models.Server{}
+
+	if err := json.NewDecoder(r.Body).Decode(&server); err != nil {
+		respondError(w, http.StatusBadRequest, fmt.Errorf("error converting post form to entiy: %v ", err))
+		return
+	}
+
+	if err := s.Ctrl.AddServer(&server); err != nil {
+		respondError(w, http.StatusInternalServerError, fmt.Errorf("error adding the server: %w", err))
+	} else {
+		s.publishServerChanges(models.Server{}, server)
+		respondCreated(w, fmt.Sprintf("/v2/servers/%v", server.Id), server)
+	}
+}
+
 func (s ServersHandlerImpl) UPDATEServer(w http.ResponseWriter, r *http.Request) {
 	server := models.Server{}
 
@@ -39,13 +58,60 @@ func (s ServersHandlerImpl) UPDATEServer(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	s.updateServerResponse(server, w)
+}
+
+func (s ServersHandlerImpl) updateServerResponse(server models.Server, w http.ResponseWriter) {
+	previous, _ := s.Ctrl.GetServer(server.Id)
 	if err := s.Ctrl.UpdateServer(server); err != nil {
-		respondError(w, http.StatusInternalServerError, fmt.Errorf("error updating the server: %v", err))
+		respondError(w, http.StatusInternalServerError, fmt.Errorf("error updating the server: %w", err))
 	} else {
+		s.publishServerChanges(previous, server)
 		respondJSON(w, http.StatusOK, server)
 	}
 }
 
+// publishServerChanges sends the live events for the differences between the previous state of a server and the new one
+func (s ServersHandlerImpl) publishServerChanges(previous models.Server, server models.Server) {
+	if server.Online && !previous.Online {
+		s.LiveCtrl.Publish(models.ServerOnlineEvent, server)
+	} else if !server.Online && previous.Online {
+		s.LiveCtrl.Publish(models.ServerOfflineEvent, server)
+	}
+	if previous.Id != 0 && serverLineupChanged(previous, server) {
+		s.LiveCtrl.Publish(models.ServerLineupChangedEvent, server)
+	}
+}
+
+func serverLineupChanged(previous models.Server, server models.Server) bool {
+	if previous.OutsideTrack != server.OutsideTrack || previous.OutsideTrackName != server.OutsideTrackName ||
+		(!server.OutsideTrack && previous.Track != server.Track) {
+		return true
+	}
+	if len(previous.Cars) != len(server.Cars) || len(previous.OutsideCars) != len(server.OutsideCars) {
+		return true
+	}
+	cars := map[uint]bool{}
+	for _, car := range previous.Cars {
+		cars[car] = true
+	}
+	for _, car := range server.Cars {
+		if !cars[car] {
+			return true
+		}
+	}
+	outsideCars := map[string]bool{}
+	for _, car := range previous.OutsideCars {
+		outsideCars[car.Name] = true
+	}
+	for _, car := range server.OutsideCars {
+		if !outsideCars[car.Name] {
+			return true
+		}
+	}
+	return false
+}
+
 func (s ServersHandlerImpl) DELETEServer(w http.ResponseWriter, r *http.Request) {
 	server := models.Server{}
 
@@ -57,7 +123,7 @@ func (s ServersHandlerImpl) DELETEServer(w http.ResponseWriter, r *http.Request)
 	}
 
 	if err := s.Ctrl.DeleteServer(server); err != nil {
-		respondError(w, http.StatusInternalServerError, fmt.Errorf("error updating the server: %v", err))
+		respondError(w, http.StatusInternalServerError, fmt.Errorf("error updating the server: %w", err))
 	} else {
 		respondJSON(w, http.StatusOK, "server deleted successfully")
 	}
@@ -70,3 +136,71 @@ func (s ServersHandlerImpl) GETAllServers(writer http.ResponseWriter, _ *http.Re
 		respondJSON(writer, http.StatusOK, cars)
 	}
 }
+
+func (s ServersHandlerImpl) GETServer(w http.ResponseWriter, r *http.Request) {
+	serverId, err := idParam(r, "id")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if server, err := s.Ctrl.GetServer(serverId); err != nil {
+		respondError(w, http.StatusInternalServerError, err)
+	} else {
+		respondJSON(w, http.StatusOK, server)
+	}
+}
+
+// PUTServer replaces the whole server, the id in the path wins over the one in the body
+func (s ServersHandlerImpl) PUTServer(w http.ResponseWriter, r *http.Request) {
+	serverId, err := idParam(r, "id")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	server := models.Server{}
+	if err := json.NewDecoder(r.Body).Decode(&server); err != nil {
+		respondError(w, http.StatusBadRequest, fmt.Errorf("error converting post form to entiy: %v ", err))
+		return
+	}
+	server.Id = serverId
+
+	s.updateServerResponse(server, w)
+}
+
+// PATCHServer updates only the fields present in the body
+func (s ServersHandlerImpl) PATCHServer(w http.ResponseWriter, r *http.Request) {
+	serverId, err := idParam(r, "id")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	server, err := s.Ctrl.GetServer(serverId)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if err := json.NewDecoder(r.Body).Decode(&server); err != nil {
+		respondError(w, http.StatusBadRequest, fmt.Errorf("error converting post form to entiy: %v ", err))
+		return
+	}
+	server.Id = serverId
+
+	s.updateServerResponse(server, w)
+}
+
+func (s ServersHandlerImpl) DELETEServerById(w http.ResponseWriter, r *http.Request) {
+	serverId, err := idParam(r, "id")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := s.Ctrl.DeleteServer(models.Server{Id: serverId}); err != nil {
+		respondError(w, http.StatusInternalServerError, fmt.Errorf("error deleting the server: %w", err))
+	} else {
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -10,7 +10,8 @@ import (
 )
 
 type SkinsHandlerImpl struct {
-	Ctrl controllers.SkinController
+	Ctrl     controllers.SkinController
+	LiveCtrl controllers.LiveController
 }
 
 func (s SkinsHandlerImpl) GETCarSkins(w http.ResponseWriter, r *http.Request) {
@@ -23,7 +24,7 @@ func (s SkinsHandlerImpl) GETCarSkins(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if skins, err := s.Ctrl.SelectCarSkins(uint(carId)); err != nil {
-		respondError(w, http.StatusInternalServerError, fmt.Errorf("Error proccessing request: %v ", err))
+		respondError(w, http.StatusInternalServerError, fmt.Errorf("Error proccessing request: %w ", err))
 		return
 	} else {
 		respondJSON(w, http.StatusOK, skins)
@@ -32,7 +33,7 @@ func (s SkinsHandlerImpl) GETCarSkins(w http.ResponseWriter, r *http.Request) {
 
 func (s SkinsHandlerImpl) GETAllSkins(w http.ResponseWriter, _ *http.Request) {
 	if skins, err := s.Ctrl.GetAllSkins(); err != nil {
-		respondError(w, http.StatusInternalServerError, fmt.Errorf("Error proccessing request: %v ", err))
+		respondError(w, http.StatusInternalServerError, fmt.Errorf("Error proccessing request: %w ", err))
 		return
 	} else {
 		respondJSON(w, http.StatusOK, skins)
@@ -49,12 +50,13 @@ func (s SkinsHandlerImpl) ADDSkin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := s.Ctrl.AddSkin(skin); err != nil {
-		respondError(w, http.StatusInternalServerError, fmt.Errorf("error adding the new entity: %v ", err))
+	if err := s.Ctrl.AddSkin(&skin); err != nil {
+		respondError(w, http.StatusInternalServerError, fmt.Errorf("error adding the new entity: %w ", err))
 		return
 	}
+	s.LiveCtrl.Publish(models.SkinAddedEvent, skin)
 
-	respondJSON(w, http.StatusCreated, skin)
+	respondCreated(w, fmt.Sprintf("/v2/skins/%v", skin.Id), skin)
 }
 
 func (s SkinsHandlerImpl) UPDATESkin(w http.ResponseWriter, r *http.Request) {
@@ -67,10 +69,91 @@ func (s SkinsHandlerImpl) UPDATESkin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	s.updateSkinResponse(skin, w, http.StatusCreated)
+}
+
+func (s SkinsHandlerImpl) updateSkinResponse(skin models.Skin, w http.ResponseWriter, status int) {
 	if err := s.Ctrl.UpdateSkin(skin); err != nil {
-		respondError(w, http.StatusInternalServerError, fmt.Errorf("error updating the entity: %v ", err))
+		respondError(w, http.StatusInternalServerError, fmt.Errorf("error updating the entity: %w ", err))
+		return
+	}
+
+	respondJSON(w, status, skin)
+}
+
+// GETSkins lists every skin or, with the carId query param, the skins of a car
+func (s SkinsHandlerImpl) GETSkins(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("carId") != "" {
+		s.GETCarSkins(w, r)
+	} else {
+		s.GETAllSkins(w, r)
+	}
+}
+
+func (s SkinsHandlerImpl) GETSkin(w http.ResponseWriter, r *http.Request) {
+	skinId, err := idParam(r, "id")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if skin, err := s.Ctrl.GetSkin(skinId); err != nil {
+		respondError(w, http.StatusInternalServerError, err)
+	} else {
+		respondJSON(w, http.StatusOK, skin)
+	}
+}
+
+// PUTSkin replaces the whole skin, the id in the path wins over the one in the body
+func (s SkinsHandlerImpl) PUTSkin(w http.ResponseWriter, r *http.Request) {
+	skinId, err := idParam(r, "id")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	skin := models.Skin{}
+	if err := json.NewDecoder(r.Body).Decode(&skin); err != nil {
+		respondError(w, http.StatusBadRequest, fmt.Errorf("error converting post form to entiy: %v ", err))
+		return
+	}
+	skin.Id = skinId
+
+	s.updateSkinResponse(skin, w, http.StatusOK)
+}
+
+// PATCHSkin updates only the fields present in the body
+func (s SkinsHandlerImpl) PATCHSkin(w http.ResponseWriter, r *http.Request) {
+	skinId, err := idParam(r, "id")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
 		return
 	}
 
-	respondJSON(w, http.StatusCreated, skin)
+	skin, err := s.Ctrl.GetSkin(skinId)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if err := json.NewDecoder(r.Body).Decode(&skin); err != nil {
+		respondError(w, http.StatusBadRequest, fmt.Errorf("error converting post form to entiy: %v ", err))
+		return
+	}
+	skin.Id = skinId
+
+	s.updateSkinResponse(skin, w, http.StatusOK)
+}
+
+func (s SkinsHandlerImpl) DELETESkin(w http.ResponseWriter, r *http.Request) {
+	skinId, err := idParam(r, "id")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := s.Ctrl.DeleteSkin(skinId); err != nil {
+		respondError(w, http.StatusInternalServerError, fmt.Errorf("error deleting the skin: %w", err))
+	} else {
+		w.WriteHeader(http.StatusNoContent)
+	}
 }
@@ -10,9 +10,8 @@ import (
 )
 
 type TrackHandlerImpl struct {
-	TrackCtrl      controllers.TrackController
-	FirebaseCtrl   controllers.FirebaseController
-	DiscordBotCtrl controllers.DiscordBotController
+	TrackCtrl controllers.TrackController
+	LiveCtrl  controllers.LiveController
 }
 
 type getTracksByParam func(string) ([]models.Track, error)
@@ -36,15 +35,12 @@ func (t TrackHandlerImpl) POSTNewTrack(writer http.ResponseWriter, request *http
 	}
 
 	if err := t.TrackCtrl.AddTrack(&track); err != nil {
-		respondError(writer, http.StatusInternalServerError, fmt.Errorf("cannot insert new entity: %v ", err))
+		respondError(writer, http.StatusInternalServerError, fmt.Errorf("cannot insert new entity: %w ", err))
 		return
 	}
-	//t.FirebaseCtrl.NotifyTrackAdded(track)
-	if !track.Official {
-		go t.DiscordBotCtrl.NotifyTrackAdded(track)
-	}
+	t.LiveCtrl.Publish(models.TrackAddedEvent, track)
 
-	respondJSON(writer, http.StatusCreated, track)
+	respondCreated(writer, fmt.Sprintf("/v2/tracks/%v", track.Id), track)
 }
 
 func (t TrackHandlerImpl) UPDATETrack(writer http.ResponseWriter, request *http.Request) {
@@ -56,13 +52,15 @@ func (t TrackHandlerImpl) UPDATETrack(writer http.ResponseWriter, request *http.
 		return
 	}
 
-	if versionChange, err := t.TrackCtrl.UpdateTrack(track); err != nil {
-		respondError(writer, http.StatusInternalServerError, fmt.Errorf("cannot insert new entity: %v ", err))
+	t.updateTrackResponse(track, writer)
+}
+
+func (t TrackHandlerImpl) updateTrackResponse(track models.Track, writer http.ResponseWriter) {
+	if _, err := t.TrackCtrl.UpdateTrack(track); err != nil {
+		respondError(writer, http.StatusInternalServerError, fmt.Errorf("cannot insert new entity: %w ", err))
 		return
-	} else if versionChange && !track.Official {
-		//t.FirebaseCtrl.NotifyTrackUpdated(track)
-		go t.DiscordBotCtrl.NotifyTrackUpdated(track)
 	}
+	t.LiveCtrl.Publish(models.TrackUpdatedEvent, track)
 
 	respondJSON(writer, http.StatusOK, track)
 }
@@ -77,13 +75,97 @@ func (t TrackHandlerImpl) getTrackByParamResponse(paramString string, getTracks
 	}
 
 	if tracks, err := getTracks(param); err != nil {
-		if err.Error() == "not found" {
-			respondError(writer, http.StatusNotFound, err)
-		} else {
-			respondError(writer, http.StatusInternalServerError, err)
-		}
+		respondError(writer, http.StatusInternalServerError, err)
 	} else {
 		respondJSON(writer, http.StatusOK, tracks)
 	}
 
 }
+
+func (t TrackHandlerImpl) GETRelatedTracks(writer http.ResponseWriter, request *http.Request) {
+	trackId, err := idParam(request, "id")
+	if err != nil {
+		respondError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	limit, err := limitParam(request)
+	if err != nil {
+		respondError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	if related, err := t.TrackCtrl.GetRelatedTracks(trackId, models.Role(request.Header.Get("Role")), limit); err != nil {
+		respondError(writer, http.StatusInternalServerError, err)
+	} else {
+		respondJSON(writer, http.StatusOK, related)
+	}
+}
+
+func (t TrackHandlerImpl) GETTrack(writer http.ResponseWriter, request *http.Request) {
+	trackId, err := idParam(request, "id")
+	if err != nil {
+		respondError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	if track, err := t.TrackCtrl.GetTrack(trackId, models.Role(request.Header.Get("Role"))); err != nil {
+		respondError(writer, http.StatusInternalServerError, err)
+	} else {
+		respondJSON(writer, http.StatusOK, track)
+	}
+}
+
+// PUTTrack replaces the whole track, the id in the path wins over the one in the body
+func (t TrackHandlerImpl) PUTTrack(writer http.ResponseWriter, request *http.Request) {
+	trackId, err := idParam(request, "id")
+	if err != nil {
+		respondError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	track := models.Track{}
+	if err := json.NewDecoder(request.Body).Decode(&track); err != nil {
+		respondError(writer, http.StatusBadRequest, fmt.Errorf("error converting post form to entiy: %v ", err))
+		return
+	}
+	track.Id = trackId
+
+	t.updateTrackResponse(track, writer)
+}
+
+// PATCHTrack updates only the fields present in the body
+func (t TrackHandlerImpl) PATCHTrack(writer http.ResponseWriter, request *http.Request) {
+	trackId, err := idParam(request, "id")
+	if err != nil {
+		respondError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	track, err := t.TrackCtrl.GetTrack(trackId, models.Admin)
+	if err != nil {
+		respondError(writer, http.StatusInternalServerError, err)
+		return
+	}
+	if err := json.NewDecoder(request.Body).Decode(&track); err != nil {
+		respondError(writer, http.StatusBadRequest, fmt.Errorf("error converting post form to entiy: %v ", err))
+		return
+	}
+	track.Id = trackId
+
+	t.updateTrackResponse(track, writer)
+}
+
+func (t TrackHandlerImpl) DELETETrack(writer http.ResponseWriter, request *http.Request) {
+	trackId, err := idParam(request, "id")
+	if err != nil {
+		respondError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := t.TrackCtrl.DeleteTrack(trackId); err != nil {
+		respondError(writer, http.StatusInternalServerError, fmt.Errorf("error deleting the track: %w", err))
+	} else {
+		writer.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -49,7 +49,7 @@ func (u UserHandlerImpl) LogIn(w http.ResponseWriter, r *http.Request) {
 
 	validToken, err := GenerateJWT(authuser.Username, string(authuser.Role), u.Secret)
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, fmt.Errorf("error generating token: %v ", err))
+		respondError(w, http.StatusInternalServerError, fmt.Errorf("error generating token: %w ", err))
 		return
 	}
 
@@ -67,7 +67,7 @@ func (u UserHandlerImpl) SignIn(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	newUser, err := u.UserCtrl.SignIn(user.Username, user.Password, user.Role)
+	newUser, err := u.UserCtrl.SignIn(user.Username, user.Password, user.Role, user.Author)
 	if err != nil {
 		respondError(w, http.StatusUnauthorized, err)
 		return
@@ -75,7 +75,7 @@ func (u UserHandlerImpl) SignIn(w http.ResponseWriter, r *http.Request) {
 
 	validToken, err := GenerateJWT(newUser.Username, string(newUser.Role), u.Secret)
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, fmt.Errorf("error generating token: %v ", err))
+		respondError(w, http.StatusInternalServerError, fmt.Errorf("error generating token: %w ", err))
 		return
 	}
 
@@ -92,7 +92,7 @@ func (u UserHandlerImpl) UpdatePassword(w http.ResponseWriter, r *http.Request)
 	}
 
 	if err := u.UserCtrl.UpdatePassword(authDetails.Username, authDetails.Password); err != nil {
-		respondError(w, http.StatusInternalServerError, fmt.Errorf("an error occured while processing your request: %v ", err))
+		respondError(w, http.StatusInternalServerError, fmt.Errorf("an error occured while processing your request: %w ", err))
 	}
 
 	respondJSON(w, http.StatusOK, nil)
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/davide/ModRepository/controllers"
+	"github.com/davide/ModRepository/models"
+	"net/http"
+	"strconv"
+)
+
+const (
+	defaultDeliveriesLimit = 50
+	maxDeliveriesLimit     = 500
+)
+
+type WebhooksHandlerImpl struct {
+	Ctrl controllers.WebhookController
+}
+
+func (h WebhooksHandlerImpl) GETWebhooks(writer http.ResponseWriter, _ *http.Request) {
+	if endpoints, err := h.Ctrl.GetWebhooks(); err != nil {
+		respondError(writer, http.StatusInternalServerError, fmt.Errorf("error loading the webhooks: %w", err))
+	} else {
+		respondJSON(writer, http.StatusOK, endpoints)
+	}
+}
+
+func (h WebhooksHandlerImpl) GETWebhook(writer http.ResponseWriter, request *http.Request) {
+	id, err := idParam(request, "id")
+	if err != nil {
+		respondError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	if endpoint, err := h.Ctrl.GetWebhook(id); err != nil {
+		respondError(writer, http.StatusInternalServerError, fmt.Errorf("error loading the webhook: %w", err))
+	} else {
+		respondJSON(writer, http.StatusOK, endpoint)
+	}
+}
+
+// POSTWebhook registers an endpoint, the response is the only one containing the secret
+func (h WebhooksHandlerImpl) POSTWebhook(writer http.ResponseWriter, request *http.Request) {
+	endpoint := models.WebhookEndpoint{Active: true}
+	if err := json.NewDecoder(request.Body).Decode(&endpoint); err != nil {
+		respondError(writer, http.StatusBadRequest, fmt.Errorf("error converting post form to entiy: %v ", err))
+		return
+	}
+
+	endpoint, err := h.Ctrl.AddWebhook(endpoint)
+	if err != nil {
+		respondError(writer, http.StatusInternalServerError, fmt.Errorf("error adding the webhook: %w", err))
+		return
+	}
+	respondCreated(writer, fmt.Sprintf("/webhooks/%v", endpoint.Id), endpoint)
+}
+
+func (h WebhooksHandlerImpl) PUTWebhook(writer http.ResponseWriter, request *http.Request) {
+	id, err := idParam(request, "id")
+	if err != nil {
+		respondError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	endpoint := models.WebhookEndpoint{}
+	if err := json.NewDecoder(request.Body).Decode(&endpoint); err != nil {
+		respondError(writer, http.StatusBadRequest, fmt.Errorf("error converting post form to entiy: %v ", err))
+		return
+	}
+	endpoint.Id = id
+
+	if endpoint, err := h.Ctrl.UpdateWebhook(endpoint); err != nil {
+		respondError(writer, http.StatusInternalServerError, fmt.Errorf("error updating the webhook: %w", err))
+	} else {
+		respondJSON(writer, http.StatusOK, endpoint)
+	}
+}
+
+func (h WebhooksHandlerImpl) DELETEWebhook(writer http.ResponseWriter, request *http.Request) {
+	id, err := idParam(request, "id")
+	if err != nil {
+		respondError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.Ctrl.DeleteWebhook(id); err != nil {
+		respondError(writer, http.StatusInternalServerError, fmt.Errorf("error deleting the webhook: %w", err))
+	} else {
+		writer.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// GETWebhookDeliveries is the delivery log of an endpoint, newest first
+func (h WebhooksHandlerImpl) GETWebhookDeliveries(writer http.ResponseWriter, request *http.Request) {
+	id, err := idParam(request, "id")
+	if err != nil {
+		respondError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	limit := defaultDeliveriesLimit
+	if param := request.URL.Query().Get("limit"); param != "" {
+		value, err := strconv.Atoi(param)
+		if err != nil || value < 1 {
+			respondError(writer, http.StatusBadRequest, models.NewValidationError("limit", "must be a positive number"))
+			return
+		}
+		if value < maxDeliveriesLimit {
+			limit = value
+		} else {
+			limit = maxDeliveriesLimit
+		}
+	}
+
+	if deliveries, err := h.Ctrl.GetDeliveries(id, limit); err != nil {
+		respondError(writer, http.StatusInternalServerError, fmt.Errorf("error loading the deliveries: %w", err))
+	} else {
+		respondJSON(writer, http.StatusOK, deliveries)
+	}
+}
@@ -0,0 +1,990 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"github.com/davide/ModRepository/controllers"
+	"github.com/davide/ModRepository/models"
+	"github.com/graph-gophers/graphql-go"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// GraphqlResolver is the root of the graphql schema
+type GraphqlResolver struct {
+	CarCtrl    controllers.CarController
+	TrackCtrl  controllers.TrackController
+	SkinCtrl   controllers.SkinController
+	ServerCtrl controllers.ServersController
+	AuthorCtrl controllers.AuthorController
+	BrandCtrl  controllers.BrandController
+	NationCtrl controllers.NationController
+}
+
+type graphqlLoaderKey struct{}
+
+type graphqlBatch struct {
+	once  sync.Once
+	value interface{}
+	err   error
+}
+
+func (b *graphqlBatch) load(fetch func() (interface{}, error)) (interface{}, error) {
+	b.once.Do(func() {
+		b.value, b.err = fetch()
+	})
+	return b.value, b.err
+}
+
+// graphqlLoader fetches every list at most once per request and the single mods by id. The nested fields read
+// from maps built once from those lists, so the number of queries doesn't grow with the size or the depth of the result.
+// The skin and server lists announce the mods their items point to, the first item asking for one fetches them all
+type graphqlLoader struct {
+	root GraphqlResolver
+	role models.Role
+
+	mu                     sync.Mutex
+	carsById               map[uint]models.Car
+	tracksById             map[uint]models.Track
+	fetchedCars            map[uint]bool
+	fetchedTracks          map[uint]bool
+	pendingCars            map[uint]bool
+	pendingTracks          map[uint]bool
+	carsFetch, tracksFetch sync.Mutex
+
+	cars, tracks, skins, servers, authors, brands, nations graphqlBatch
+
+	carsByBrand, carsByAuthor, tracksByAuthor, tracksByNation graphqlBatch
+	skinsByCar, serversByCar, serversByTrack, brandsByNation  graphqlBatch
+}
+
+func withGraphqlLoader(ctx context.Context, root GraphqlResolver, role models.Role) context.Context {
+	return context.WithValue(ctx, graphqlLoaderKey{}, &graphqlLoader{
+		root:          root,
+		role:          role,
+		carsById:      map[uint]models.Car{},
+		tracksById:    map[uint]models.Track{},
+		fetchedCars:   map[uint]bool{},
+		fetchedTracks: map[uint]bool{},
+		pendingCars:   map[uint]bool{},
+		pendingTracks: map[uint]bool{},
+	})
+}
+
+func loaderFrom(ctx context.Context) *graphqlLoader {
+	return ctx.Value(graphqlLoaderKey{}).(*graphqlLoader)
+}
+
+func (l *graphqlLoader) Cars() ([]models.Car, error) {
+	cars, err := l.cars.load(func() (interface{}, error) {
+		cars, err := l.root.CarCtrl.GetAllCars(l.role)
+		if err != nil {
+			return nil, err
+		}
+		l.rememberCars(cars)
+		return cars, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cars.([]models.Car), nil
+}
+
+func (l *graphqlLoader) rememberCars(cars []models.Car) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, car := range cars {
+		l.carsById[car.Id] = car
+		l.fetchedCars[car.Id] = true
+	}
+}
+
+// expectCars announces the cars the items of a list may ask for, they are fetched together with the first one asked
+func (l *graphqlLoader) expectCars(ids []uint) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, id := range ids {
+		if !l.fetchedCars[id] {
+			l.pendingCars[id] = true
+		}
+	}
+}
+
+// missingCars are the ids and the expected cars not fetched yet, the expected ones are taken
+func (l *graphqlLoader) missingCars(ids []uint) []uint {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, id := range ids {
+		l.pendingCars[id] = true
+	}
+	missing := []uint{}
+	for id := range l.pendingCars {
+		if !l.fetchedCars[id] {
+			missing = append(missing, id)
+		}
+	}
+	l.pendingCars = map[uint]bool{}
+	sort.Slice(missing, func(i, j int) bool { return missing[i] < missing[j] })
+	return missing
+}
+
+// CarsByIds returns the cars in the order of ids, the ones not seen yet in the request are fetched with one query.
+// The fetches are serialized so the items of a list resolved concurrently wait for the first query instead of
+// running their own
+func (l *graphqlLoader) CarsByIds(ids []uint) ([]models.Car, error) {
+	l.carsFetch.Lock()
+	if missing := l.missingCars(ids); len(missing) > 0 {
+		cars, err := l.root.CarCtrl.GetCarsByIds(missing, l.role)
+		if err != nil {
+			l.carsFetch.Unlock()
+			return nil, err
+		}
+		l.rememberCars(cars)
+		l.mu.Lock()
+		for _, id := range missing {
+			l.fetchedCars[id] = true
+		}
+		l.mu.Unlock()
+	}
+	l.carsFetch.Unlock()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	cars := []models.Car{}
+	for _, id := range ids {
+		if car, ok := l.carsById[id]; ok {
+			cars = append(cars, car)
+		}
+	}
+	return cars, nil
+}
+
+func (l *graphqlLoader) Tracks() ([]models.Track, error) {
+	tracks, err := l.tracks.load(func() (interface{}, error) {
+		tracks, err := l.root.TrackCtrl.GetAllTracks(l.role)
+		if err != nil {
+			return nil, err
+		}
+		l.rememberTracks(tracks)
+		return tracks, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tracks.([]models.Track), nil
+}
+
+func (l *graphqlLoader) rememberTracks(tracks []models.Track) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, track := range tracks {
+		l.tracksById[track.Id] = track
+		l.fetchedTracks[track.Id] = true
+	}
+}
+
+// expectTracks announces the tracks the items of a list may ask for, they are fetched together with the first one asked
+func (l *graphqlLoader) expectTracks(ids []uint) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, id := range ids {
+		if !l.fetchedTracks[id] {
+			l.pendingTracks[id] = true
+		}
+	}
+}
+
+// missingTracks are the ids and the expected tracks not fetched yet, the expected ones are taken
+func (l *graphqlLoader) missingTracks(ids []uint) []uint {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, id := range ids {
+		l.pendingTracks[id] = true
+	}
+	missing := []uint{}
+	for id := range l.pendingTracks {
+		if !l.fetchedTracks[id] {
+			missing = append(missing, id)
+		}
+	}
+	l.pendingTracks = map[uint]bool{}
+	sort.Slice(missing, func(i, j int) bool { return missing[i] < missing[j] })
+	return missing
+}
+
+// TracksByIds returns the tracks in the order of ids, the ones not seen yet in the request are fetched with one
+// query, serialized like CarsByIds
+func (l *graphqlLoader) TracksByIds(ids []uint) ([]models.Track, error) {
+	l.tracksFetch.Lock()
+	if missing := l.missingTracks(ids); len(missing) > 0 {
+		tracks, err := l.root.TrackCtrl.GetTracksByIds(missing, l.role)
+		if err != nil {
+			l.tracksFetch.Unlock()
+			return nil, err
+		}
+		l.rememberTracks(tracks)
+		l.mu.Lock()
+		for _, id := range missing {
+			l.fetchedTracks[id] = true
+		}
+		l.mu.Unlock()
+	}
+	l.tracksFetch.Unlock()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	tracks := []models.Track{}
+	for _, id := range ids {
+		if track, ok := l.tracksById[id]; ok {
+			tracks = append(tracks, track)
+		}
+	}
+	return tracks, nil
+}
+
+func (l *graphqlLoader) Skins() ([]models.Skin, error) {
+	skins, err := l.skins.load(func() (interface{}, error) {
+		return l.root.SkinCtrl.GetAllSkins()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return skins.([]models.Skin), nil
+}
+
+func (l *graphqlLoader) Servers() ([]models.Server, error) {
+	servers, err := l.servers.load(func() (interface{}, error) {
+		return l.root.ServerCtrl.GetAllServers()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return servers.([]models.Server), nil
+}
+
+func (l *graphqlLoader) Authors() ([]models.Author, error) {
+	authors, err := l.authors.load(func() (interface{}, error) {
+		return l.root.AuthorCtrl.GetAllAuthors()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return authors.([]models.Author), nil
+}
+
+func (l *graphqlLoader) Brands() ([]models.CarBrand, error) {
+	brands, err := l.brands.load(func() (interface{}, error) {
+		return l.root.BrandCtrl.GetAllBrands()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return brands.([]models.CarBrand), nil
+}
+
+func (l *graphqlLoader) Nations() ([]models.Nation, error) {
+	nations, err := l.nations.load(func() (interface{}, error) {
+		return l.root.NationCtrl.GetAllNations("")
+	})
+	if err != nil {
+		return nil, err
+	}
+	return nations.([]models.Nation), nil
+}
+
+// groupCars builds once the map from key to cars, every parent of a nested list then reads its own entry
+func (l *graphqlLoader) groupCars(batch *graphqlBatch, key func(models.Car) string) (map[string][]models.Car, error) {
+	groups, err := batch.load(func() (interface{}, error) {
+		cars, err := l.Cars()
+		if err != nil {
+			return nil, err
+		}
+		groups := map[string][]models.Car{}
+		for _, car := range cars {
+			groups[key(car)] = append(groups[key(car)], car)
+		}
+		return groups, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return groups.(map[string][]models.Car), nil
+}
+
+func (l *graphqlLoader) groupTracks(batch *graphqlBatch, key func(models.Track) string) (map[string][]models.Track, error) {
+	groups, err := batch.load(func() (interface{}, error) {
+		tracks, err := l.Tracks()
+		if err != nil {
+			return nil, err
+		}
+		groups := map[string][]models.Track{}
+		for _, track := range tracks {
+			groups[key(track)] = append(groups[key(track)], track)
+		}
+		return groups, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return groups.(map[string][]models.Track), nil
+}
+
+func (l *graphqlLoader) CarsOfBrand(brand string) ([]models.Car, error) {
+	groups, err := l.groupCars(&l.carsByBrand, func(car models.Car) string { return car.Brand.Name })
+	return groups[brand], err
+}
+
+func (l *graphqlLoader) CarsOfAuthor(author string) ([]models.Car, error) {
+	groups, err := l.groupCars(&l.carsByAuthor, func(car models.Car) string { return car.Author.Name })
+	return groups[author], err
+}
+
+func (l *graphqlLoader) TracksOfAuthor(author string) ([]models.Track, error) {
+	groups, err := l.groupTracks(&l.tracksByAuthor, func(track models.Track) string { return track.Author.Name })
+	return groups[author], err
+}
+
+func (l *graphqlLoader) TracksOfNation(nation string) ([]models.Track, error) {
+	groups, err := l.groupTracks(&l.tracksByNation, func(track models.Track) string { return track.Nation.Name })
+	return groups[nation], err
+}
+
+func (l *graphqlLoader) SkinsOfCar(carId uint) ([]models.Skin, error) {
+	groups, err := l.skinsByCar.load(func() (interface{}, error) {
+		skins, err := l.Skins()
+		if err != nil {
+			return nil, err
+		}
+		groups := map[uint][]models.Skin{}
+		for _, skin := range skins {
+			groups[skin.CarId] = append(groups[skin.CarId], skin)
+		}
+		return groups, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return groups.(map[uint][]models.Skin)[carId], nil
+}
+
+func (l *graphqlLoader) ServersOfCar(carId uint) ([]models.Server, error) {
+	groups, err := l.serversByCar.load(func() (interface{}, error) {
+		servers, err := l.Servers()
+		if err != nil {
+			return nil, err
+		}
+		groups := map[uint][]models.Server{}
+		for _, server := range servers {
+			for _, id := range server.Cars {
+				groups[id] = append(groups[id], server)
+			}
+		}
+		return groups, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return groups.(map[uint][]models.Server)[carId], nil
+}
+
+func (l *graphqlLoader) ServersOfTrack(trackId uint) ([]models.Server, error) {
+	groups, err := l.serversByTrack.load(func() (interface{}, error) {
+		servers, err := l.Servers()
+		if err != nil {
+			return nil, err
+		}
+		groups := map[uint][]models.Server{}
+		for _, server := range servers {
+			if !server.OutsideTrack {
+				groups[server.Track] = append(groups[server.Track], server)
+			}
+		}
+		return groups, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return groups.(map[uint][]models.Server)[trackId], nil
+}
+
+func (l *graphqlLoader) BrandsOfNation(nation string) ([]models.CarBrand, error) {
+	groups, err := l.brandsByNation.load(func() (interface{}, error) {
+		brands, err := l.Brands()
+		if err != nil {
+			return nil, err
+		}
+		groups := map[string][]models.CarBrand{}
+		for _, brand := range brands {
+			groups[brand.Nation.Name] = append(groups[brand.Nation.Name], brand)
+		}
+		return groups, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return groups.(map[string][]models.CarBrand)[nation], nil
+}
+
+func parseGraphqlId(id graphql.ID) (uint, error) {
+	value, err := strconv.ParseUint(string(id), 10, 64)
+	if err != nil {
+		return 0, models.NewValidationError("id", "must be a number")
+	}
+	return uint(value), nil
+}
+
+func graphqlId(id uint) graphql.ID {
+	return graphql.ID(strconv.FormatUint(uint64(id), 10))
+}
+
+// matchesFilter tells if an optional filter is unset or equal to the value, ignoring the case
+func matchesFilter(filter *string, value string) bool {
+	return filter == nil || strings.EqualFold(*filter, value)
+}
+
+func matchesFlag(filter *bool, value bool) bool {
+	return filter == nil || *filter == value
+}
+
+func containsFilter(filter *string, values ...string) bool {
+	if filter == nil {
+		return true
+	}
+	for _, value := range values {
+		if strings.Contains(strings.ToLower(value), strings.ToLower(*filter)) {
+			return true
+		}
+	}
+	return false
+}
+
+type carsArgs struct {
+	Brand    *string
+	Category *string
+	Author   *string
+	Nation   *string
+	Premium  *bool
+	Official *bool
+	Search   *string
+}
+
+func (r GraphqlResolver) Cars(ctx context.Context, args carsArgs) ([]carResolver, error) {
+	cars, err := loaderFrom(ctx).Cars()
+	if err != nil {
+		return nil, err
+	}
+	resolvers := []carResolver{}
+	for _, car := range cars {
+		if !matchesFilter(args.Brand, car.Brand.Name) || !matchesFilter(args.Author, car.Author.Name) ||
+			!matchesFilter(args.Nation, car.Brand.Nation.Name) || !matchesFlag(args.Premium, car.Premium) ||
+			!matchesFlag(args.Official, car.Official) || !containsFilter(args.Search, car.ModelName, car.Brand.Name) {
+			continue
+		}
+		if args.Category != nil {
+			found := false
+			for _, category := range car.Categories {
+				found = found || strings.EqualFold(string(category.Name), *args.Category)
+			}
+			if !found {
+				continue
+			}
+		}
+		resolvers = append(resolvers, carResolver{modResolver{car.Mod}, car})
+	}
+	return resolvers, nil
+}
+
+func (r GraphqlResolver) Car(ctx context.Context, args struct{ Id graphql.ID }) (*carResolver, error) {
+	id, err := parseGraphqlId(args.Id)
+	if err != nil {
+		return nil, err
+	}
+	return carById(ctx, id)
+}
+
+type tracksArgs struct {
+	Nation   *string
+	Tag      *string
+	Author   *string
+	Premium  *bool
+	Official *bool
+	Search   *string
+}
+
+func (r GraphqlResolver) Tracks(ctx context.Context, args tracksArgs) ([]trackResolver, error) {
+	tracks, err := loaderFrom(ctx).Tracks()
+	if err != nil {
+		return nil, err
+	}
+	resolvers := []trackResolver{}
+	for _, track := range tracks {
+		if !matchesFilter(args.Nation, track.Nation.Name) || !matchesFilter(args.Author, track.Author.Name) ||
+			!matchesFlag(args.Premium, track.Premium) || !matchesFlag(args.Official, track.Official) ||
+			!containsFilter(args.Search, track.Name, track.Location) {
+			continue
+		}
+		if args.Tag != nil {
+			found := false
+			for _, tag := range track.Tags {
+				found = found || strings.EqualFold(string(tag), *args.Tag)
+			}
+			if !found {
+				continue
+			}
+		}
+		resolvers = append(resolvers, trackResolver{modResolver{track.Mod}, track})
+	}
+	return resolvers, nil
+}
+
+func (r GraphqlResolver) Track(ctx context.Context, args struct{ Id graphql.ID }) (*trackResolver, error) {
+	id, err := parseGraphqlId(args.Id)
+	if err != nil {
+		return nil, err
+	}
+	return trackById(ctx, id)
+}
+
+func (r GraphqlResolver) Skins(ctx context.Context, args struct{ CarId *graphql.ID }) ([]skinResolver, error) {
+	if args.CarId == nil {
+		skins, err := loaderFrom(ctx).Skins()
+		return skinResolvers(ctx, skins), err
+	}
+	carId, err := parseGraphqlId(*args.CarId)
+	if err != nil {
+		return nil, err
+	}
+	skins, err := loaderFrom(ctx).SkinsOfCar(carId)
+	return skinResolvers(ctx, skins), err
+}
+
+func (r GraphqlResolver) Skin(args struct{ Id graphql.ID }) (*skinResolver, error) {
+	id, err := parseGraphqlId(args.Id)
+	if err != nil {
+		return nil, err
+	}
+	skin, err := r.SkinCtrl.GetSkin(id)
+	if errors.Is(err, models.ErrNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &skinResolver{skin}, nil
+}
+
+func (r GraphqlResolver) Servers(ctx context.Context, args struct{ Online *bool }) ([]serverResolver, error) {
+	servers, err := loaderFrom(ctx).Servers()
+	if err != nil {
+		return nil, err
+	}
+	var matching []models.Server
+	for _, server := range servers {
+		if matchesFlag(args.Online, server.Online) {
+			matching = append(matching, server)
+		}
+	}
+	return serverResolvers(ctx, matching), nil
+}
+
+func (r GraphqlResolver) Server(args struct{ Id graphql.ID }) (*serverResolver, error) {
+	id, err := parseGraphqlId(args.Id)
+	if err != nil {
+		return nil, err
+	}
+	server, err := r.ServerCtrl.GetServer(id)
+	if errors.Is(err, models.ErrNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &serverResolver{server}, nil
+}
+
+func (r GraphqlResolver) Authors(ctx context.Context, args struct{ Search *string }) ([]authorResolver, error) {
+	authors, err := loaderFrom(ctx).Authors()
+	if err != nil {
+		return nil, err
+	}
+	resolvers := []authorResolver{}
+	for _, author := range authors {
+		if containsFilter(args.Search, author.Name) {
+			resolvers = append(resolvers, authorResolver{author})
+		}
+	}
+	return resolvers, nil
+}
+
+func (r GraphqlResolver) Author(ctx context.Context, args struct{ Name string }) (*authorResolver, error) {
+	authors, err := loaderFrom(ctx).Authors()
+	if err != nil {
+		return nil, err
+	}
+	for _, author := range authors {
+		if strings.EqualFold(author.Name, args.Name) {
+			return &authorResolver{author}, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r GraphqlResolver) Brands(ctx context.Context, args struct {
+	Nation *string
+	Search *string
+}) ([]brandResolver, error) {
+	brands, err := loaderFrom(ctx).Brands()
+	if err != nil {
+		return nil, err
+	}
+	resolvers := []brandResolver{}
+	for _, brand := range brands {
+		if matchesFilter(args.Nation, brand.Nation.Name) && containsFilter(args.Search, brand.Name) {
+			resolvers = append(resolvers, brandResolver{brand})
+		}
+	}
+	return resolvers, nil
+}
+
+func (r GraphqlResolver) Brand(ctx context.Context, args struct{ Name string }) (*brandResolver, error) {
+	brands, err := loaderFrom(ctx).Brands()
+	if err != nil {
+		return nil, err
+	}
+	for _, brand := range brands {
+		if strings.EqualFold(brand.Name, args.Name) {
+			return &brandResolver{brand}, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r GraphqlResolver) Nations(ctx context.Context, args struct{ Continent *string }) ([]nationResolver, error) {
+	nations, err := loaderFrom(ctx).Nations()
+	if err != nil {
+		return nil, err
+	}
+	resolvers := []nationResolver{}
+	for _, nation := range nations {
+		if matchesFilter(args.Continent, string(nation.Continent)) {
+			resolvers = append(resolvers, nationResolver{nation})
+		}
+	}
+	return resolvers, nil
+}
+
+func carById(ctx context.Context, id uint) (*carResolver, error) {
+	cars, err := loaderFrom(ctx).CarsByIds([]uint{id})
+	if err != nil || len(cars) == 0 {
+		return nil, err
+	}
+	return &carResolvers(cars)[0], nil
+}
+
+func carResolvers(cars []models.Car) []carResolver {
+	resolvers := []carResolver{}
+	for _, car := range cars {
+		resolvers = append(resolvers, carResolver{modResolver{car.Mod}, car})
+	}
+	return resolvers
+}
+
+func trackById(ctx context.Context, id uint) (*trackResolver, error) {
+	tracks, err := loaderFrom(ctx).TracksByIds([]uint{id})
+	if err != nil || len(tracks) == 0 {
+		return nil, err
+	}
+	return &trackResolvers(tracks)[0], nil
+}
+
+func trackResolvers(tracks []models.Track) []trackResolver {
+	resolvers := []trackResolver{}
+	for _, track := range tracks {
+		resolvers = append(resolvers, trackResolver{modResolver{track.Mod}, track})
+	}
+	return resolvers
+}
+
+// skinResolvers announces the cars of the skins, so the car field of the whole list is one query
+func skinResolvers(ctx context.Context, skins []models.Skin) []skinResolver {
+	var carIds []uint
+	resolvers := []skinResolver{}
+	for _, skin := range skins {
+		carIds = append(carIds, skin.CarId)
+		resolvers = append(resolvers, skinResolver{skin})
+	}
+	loaderFrom(ctx).expectCars(carIds)
+	return resolvers
+}
+
+// serverResolvers announces the cars and the tracks of the servers, so the fields of the whole list are one query each
+func serverResolvers(ctx context.Context, servers []models.Server) []serverResolver {
+	var carIds, trackIds []uint
+	resolvers := []serverResolver{}
+	for _, server := range servers {
+		carIds = append(carIds, server.Cars...)
+		if !server.OutsideTrack {
+			trackIds = append(trackIds, server.Track)
+		}
+		resolvers = append(resolvers, serverResolver{server})
+	}
+	loader := loaderFrom(ctx)
+	loader.expectCars(carIds)
+	loader.expectTracks(trackIds)
+	return resolvers
+}
+
+func brandResolvers(brands []models.CarBrand) []brandResolver {
+	resolvers := []brandResolver{}
+	for _, brand := range brands {
+		resolvers = append(resolvers, brandResolver{brand})
+	}
+	return resolvers
+}
+
+// modResolver resolves the fields shared by cars and tracks, download links are already masked by the role
+type modResolver struct {
+	mod models.Mod
+}
+
+func (m modResolver) Id() graphql.ID          { return graphqlId(m.mod.Id) }
+func (m modResolver) DownloadLink() string    { return m.mod.DownloadLink }
+func (m modResolver) Source() string          { return m.mod.Source }
+func (m modResolver) Premium() bool           { return m.mod.Premium }
+func (m modResolver) Personal() bool          { return m.mod.Personal }
+func (m modResolver) Official() bool          { return m.mod.Official }
+func (m modResolver) Rating() int32           { return int32(m.mod.Rating) }
+func (m modResolver) Version() string         { return m.mod.Version }
+func (m modResolver) Changelog() string       { return m.mod.Changelog }
+func (m modResolver) CreatedAt() graphql.Time { return graphql.Time{Time: m.mod.CreatedAt} }
+func (m modResolver) UpdatedAt() graphql.Time { return graphql.Time{Time: m.mod.UpdatedAt} }
+func (m modResolver) Author() authorResolver  { return authorResolver{m.mod.Author} }
+
+func (m modResolver) Contributors() []contributorResolver {
+	resolvers := []contributorResolver{}
+	for _, contributor := range m.mod.Contributors {
+		resolvers = append(resolvers, contributorResolver{contributor})
+	}
+	return resolvers
+}
+
+func (m modResolver) Images() []imageResolver {
+	resolvers := []imageResolver{}
+	for _, image := range m.mod.Images {
+		resolvers = append(resolvers, imageResolver{image})
+	}
+	return resolvers
+}
+
+type carResolver struct {
+	modResolver
+	car models.Car
+}
+
+func (c carResolver) ModelName() string    { return c.car.ModelName }
+func (c carResolver) Brand() brandResolver { return brandResolver{c.car.Brand} }
+func (c carResolver) Year() int32          { return int32(c.car.Year) }
+func (c carResolver) Drivetrain() string   { return string(c.car.Drivetrain) }
+func (c carResolver) Transmission() string { return string(c.car.Transmission) }
+func (c carResolver) Bhp() int32           { return int32(c.car.BHP) }
+func (c carResolver) TopSpeed() int32      { return int32(c.car.TopSpeed) }
+func (c carResolver) Weight() int32        { return int32(c.car.Weight) }
+func (c carResolver) Torque() int32        { return int32(c.car.Torque) }
+
+func (c carResolver) Categories() []string {
+	categories := []string{}
+	for _, category := range c.car.Categories {
+		categories = append(categories, string(category.Name))
+	}
+	return categories
+}
+
+func (c carResolver) Skins(ctx context.Context) ([]skinResolver, error) {
+	skins, err := loaderFrom(ctx).SkinsOfCar(c.car.Id)
+	return skinResolvers(ctx, skins), err
+}
+
+func (c carResolver) Servers(ctx context.Context) ([]serverResolver, error) {
+	servers, err := loaderFrom(ctx).ServersOfCar(c.car.Id)
+	return serverResolvers(ctx, servers), err
+}
+
+type trackResolver struct {
+	modResolver
+	track models.Track
+}
+
+func (t trackResolver) Name() string           { return t.track.Name }
+func (t trackResolver) Location() string       { return t.track.Location }
+func (t trackResolver) Nation() nationResolver { return nationResolver{t.track.Nation} }
+func (t trackResolver) Year() int32            { return int32(t.track.Year) }
+
+func (t trackResolver) Tags() []string {
+	tags := []string{}
+	for _, tag := range t.track.Tags {
+		tags = append(tags, string(tag))
+	}
+	return tags
+}
+
+func (t trackResolver) Layouts() []layoutResolver {
+	resolvers := []layoutResolver{}
+	for _, layout := range t.track.Layouts {
+		resolvers = append(resolvers, layoutResolver{layout})
+	}
+	return resolvers
+}
+
+func (t trackResolver) Servers(ctx context.Context) ([]serverResolver, error) {
+	servers, err := loaderFrom(ctx).ServersOfTrack(t.track.Id)
+	return serverResolvers(ctx, servers), err
+}
+
+type layoutResolver struct {
+	layout models.Layout
+}
+
+func (l layoutResolver) Name() string     { return l.layout.Name }
+func (l layoutResolver) LengthM() float64 { return float64(l.layout.LengthM) }
+func (l layoutResolver) Category() string { return string(l.layout.Category) }
+
+type skinResolver struct {
+	skin models.Skin
+}
+
+func (s skinResolver) Id() graphql.ID       { return graphqlId(s.skin.Id) }
+func (s skinResolver) Name() string         { return s.skin.Name }
+func (s skinResolver) DownloadLink() string { return s.skin.DownloadLink }
+func (s skinResolver) ImageUrl() string     { return s.skin.ImageUrl }
+
+func (s skinResolver) Car(ctx context.Context) (*carResolver, error) {
+	return carById(ctx, s.skin.CarId)
+}
+
+type serverResolver struct {
+	server models.Server
+}
+
+func (s serverResolver) Id() graphql.ID           { return graphqlId(s.server.Id) }
+func (s serverResolver) Name() string             { return s.server.Name }
+func (s serverResolver) Description() string      { return s.server.Description }
+func (s serverResolver) JoinLink() string         { return s.server.JoinLink }
+func (s serverResolver) Password() string         { return s.server.Password }
+func (s serverResolver) Online() bool             { return s.server.Online }
+func (s serverResolver) OutsideTrack() bool       { return s.server.OutsideTrack }
+func (s serverResolver) OutsideTrackName() string { return s.server.OutsideTrackName }
+func (s serverResolver) OutsideTrackLink() string { return s.server.OutsideTrackLink }
+
+func (s serverResolver) Track(ctx context.Context) (*trackResolver, error) {
+	if s.server.OutsideTrack {
+		return nil, nil
+	}
+	return trackById(ctx, s.server.Track)
+}
+
+func (s serverResolver) Cars(ctx context.Context) ([]carResolver, error) {
+	cars, err := loaderFrom(ctx).CarsByIds(s.server.Cars)
+	return carResolvers(cars), err
+}
+
+func (s serverResolver) OutsideCars() []outsideModResolver {
+	resolvers := []outsideModResolver{}
+	for _, car := range s.server.OutsideCars {
+		resolvers = append(resolvers, outsideModResolver{car})
+	}
+	return resolvers
+}
+
+type outsideModResolver struct {
+	mod models.OutsideMod
+}
+
+func (o outsideModResolver) Id() string           { return o.mod.Id }
+func (o outsideModResolver) Name() string         { return o.mod.Name }
+func (o outsideModResolver) DownloadLink() string { return o.mod.DownloadLink }
+
+type authorResolver struct {
+	author models.Author
+}
+
+func (a authorResolver) Name() string { return a.author.Name }
+func (a authorResolver) Link() string { return a.author.Link }
+
+func (a authorResolver) Cars(ctx context.Context) ([]carResolver, error) {
+	cars, err := loaderFrom(ctx).CarsOfAuthor(a.author.Name)
+	return carResolvers(cars), err
+}
+
+func (a authorResolver) Tracks(ctx context.Context) ([]trackResolver, error) {
+	tracks, err := loaderFrom(ctx).TracksOfAuthor(a.author.Name)
+	return trackResolvers(tracks), err
+}
+
+type contributorResolver struct {
+	contributor models.Contributor
+}
+
+func (c contributorResolver) Name() string { return c.contributor.Name }
+func (c contributorResolver) Link() string { return c.contributor.Link }
+func (c contributorResolver) Role() string { return string(c.contributor.Role) }
+
+type brandResolver struct {
+	brand models.CarBrand
+}
+
+func (b brandResolver) Name() string           { return b.brand.Name }
+func (b brandResolver) Logo() string           { return b.brand.Logo }
+func (b brandResolver) Nation() nationResolver { return nationResolver{b.brand.Nation} }
+
+func (b brandResolver) Cars(ctx context.Context) ([]carResolver, error) {
+	cars, err := loaderFrom(ctx).CarsOfBrand(b.brand.Name)
+	return carResolvers(cars), err
+}
+
+type nationResolver struct {
+	nation models.Nation
+}
+
+func (n nationResolver) Name() string      { return n.nation.Name }
+func (n nationResolver) Code() string      { return n.nation.Code }
+func (n nationResolver) Flag() string      { return n.nation.Flag }
+func (n nationResolver) Continent() string { return string(n.nation.Continent) }
+func (n nationResolver) Region() string    { return n.nation.Region }
+
+func (n nationResolver) Brands(ctx context.Context) ([]brandResolver, error) {
+	brands, err := loaderFrom(ctx).BrandsOfNation(n.nation.Name)
+	return brandResolvers(brands), err
+}
+
+func (n nationResolver) Tracks(ctx context.Context) ([]trackResolver, error) {
+	tracks, err := loaderFrom(ctx).TracksOfNation(n.nation.Name)
+	return trackResolvers(tracks), err
+}
+
+type imageResolver struct {
+	image models.Image
+}
+
+func (i imageResolver) Id() graphql.ID  { return graphqlId(i.image.Id) }
+func (i imageResolver) Url() string     { return i.image.Url }
+func (i imageResolver) Favorite() bool  { return i.image.Favorite }
+func (i imageResolver) Position() int32 { return int32(i.image.Position) }
+func (i imageResolver) Caption() string { return i.image.Caption }
+func (i imageResolver) AltText() string { return i.image.AltText }
+
+func (i imageResolver) Variants() []imageVariantResolver {
+	resolvers := []imageVariantResolver{}
+	for name, url := range i.image.Variants {
+		resolvers = append(resolvers, imageVariantResolver{name, url})
+	}
+	sort.Slice(resolvers, func(a, b int) bool { return resolvers[a].name < resolvers[b].name })
+	return resolvers
+}
+
+type imageVariantResolver struct {
+	name string
+	url  string
+}
+
+func (i imageVariantResolver) Name() string { return i.name }
+func (i imageVariantResolver) Url() string  { return i.url }
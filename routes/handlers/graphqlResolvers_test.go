@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/davide/ModRepository/controllers"
+	"github.com/davide/ModRepository/models"
+)
+
+// queryCounter counts the calls of the stub controllers, the resolvers run concurrently
+type queryCounter struct {
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+func (q *queryCounter) count(name string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.calls[name]++
+}
+
+func (q *queryCounter) get(name string) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.calls[name]
+}
+
+type countingCarController struct {
+	controllers.CarController
+	counter *queryCounter
+}
+
+func (c countingCarController) GetCarsByIds(ids []uint, _ models.Role) ([]models.Car, error) {
+	c.counter.count("GetCarsByIds")
+	var cars []models.Car
+	for _, id := range ids {
+		cars = append(cars, models.Car{Mod: models.Mod{Id: id}, ModelName: "Car " + string(graphqlId(id))})
+	}
+	return cars, nil
+}
+
+type countingTrackController struct {
+	controllers.TrackController
+	counter *queryCounter
+}
+
+func (t countingTrackController) GetTracksByIds(ids []uint, _ models.Role) ([]models.Track, error) {
+	t.counter.count("GetTracksByIds")
+	var tracks []models.Track
+	for _, id := range ids {
+		tracks = append(tracks, models.Track{Mod: models.Mod{Id: id}, Name: "Track " + string(graphqlId(id))})
+	}
+	return tracks, nil
+}
+
+type countingSkinController struct {
+	controllers.SkinController
+	counter *queryCounter
+	skins   []models.Skin
+}
+
+func (s countingSkinController) GetAllSkins() ([]models.Skin, error) {
+	s.counter.count("GetAllSkins")
+	return s.skins, nil
+}
+
+type countingServersController struct {
+	controllers.ServersController
+	counter *queryCounter
+	servers []models.Server
+}
+
+func (s countingServersController) GetAllServers() ([]models.Server, error) {
+	s.counter.count("GetAllServers")
+	return s.servers, nil
+}
+
+func runGraphqlQuery(t *testing.T, resolver GraphqlResolver, query string) map[string]interface{} {
+	t.Helper()
+	body, _ := json.Marshal(GraphqlRequest{Query: query})
+	recorder := httptest.NewRecorder()
+	NewGraphqlHandler(resolver).POSTQuery(recorder, httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(string(body))))
+
+	var response struct {
+		Data   map[string]interface{} `json:"data"`
+		Errors []interface{}          `json:"errors"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatal(err)
+	}
+	if len(response.Errors) > 0 {
+		t.Fatalf("query errors %v", response.Errors)
+	}
+	return response.Data
+}
+
+func TestGraphqlListsFetchTheirModsOnce(t *testing.T) {
+	var skins []models.Skin
+	var servers []models.Server
+	for i := uint(1); i <= 20; i++ {
+		skins = append(skins, models.Skin{Id: i, Name: "Skin", CarId: i % 7})
+		servers = append(servers, models.Server{Id: i, Name: "Server", Track: i % 5, Cars: []uint{i, i + 1}})
+	}
+	servers = append(servers, models.Server{Id: 21, Name: "Outside", OutsideTrack: true})
+
+	tests := []struct {
+		name  string
+		query string
+		calls map[string]int
+		check func(t *testing.T, data map[string]interface{})
+	}{
+		{
+			name:  "skins with their car",
+			query: `{ skins { id car { modelName } } }`,
+			calls: map[string]int{"GetAllSkins": 1, "GetCarsByIds": 1},
+			check: func(t *testing.T, data map[string]interface{}) {
+				list := data["skins"].([]interface{})
+				if len(list) != 20 {
+					t.Fatalf("expected 20 skins, got %v", len(list))
+				}
+				if car := list[0].(map[string]interface{})["car"].(map[string]interface{}); car["modelName"] != "Car 1" {
+					t.Errorf("unexpected car %v", car)
+				}
+			},
+		},
+		{
+			name:  "servers with their track and cars",
+			query: `{ servers { id track { name } cars { modelName } } }`,
+			calls: map[string]int{"GetAllServers": 1, "GetCarsByIds": 1, "GetTracksByIds": 1},
+			check: func(t *testing.T, data map[string]interface{}) {
+				list := data["servers"].([]interface{})
+				first := list[0].(map[string]interface{})
+				if cars := first["cars"].([]interface{}); len(cars) != 2 {
+					t.Errorf("expected the 2 cars of the first server, got %v", cars)
+				}
+				if track := first["track"].(map[string]interface{}); track["name"] != "Track 1" {
+					t.Errorf("unexpected track %v", track)
+				}
+				if outside := list[20].(map[string]interface{}); outside["track"] != nil {
+					t.Errorf("a server on an outside track has no track, got %v", outside["track"])
+				}
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			counter := &queryCounter{calls: map[string]int{}}
+			resolver := GraphqlResolver{
+				CarCtrl:    countingCarController{counter: counter},
+				TrackCtrl:  countingTrackController{counter: counter},
+				SkinCtrl:   countingSkinController{counter: counter, skins: skins},
+				ServerCtrl: countingServersController{counter: counter, servers: servers},
+			}
+
+			test.check(t, runGraphqlQuery(t, resolver, test.query))
+			for name, expected := range test.calls {
+				if got := counter.get(name); got != expected {
+					t.Errorf("%v called %v times, expected %v", name, got, expected)
+				}
+			}
+		})
+	}
+}
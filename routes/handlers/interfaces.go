@@ -9,12 +9,23 @@ type CarsHandler interface {
 	GETAllCarCategories(http.ResponseWriter, *http.Request)
 	POSTNewCar(http.ResponseWriter, *http.Request)
 	UPDATECar(http.ResponseWriter, *http.Request)
+	GETRelatedCars(http.ResponseWriter, *http.Request)
+	GETSimilarCars(http.ResponseWriter, *http.Request)
+	GETCar(http.ResponseWriter, *http.Request)
+	PUTCar(http.ResponseWriter, *http.Request)
+	PATCHCar(http.ResponseWriter, *http.Request)
+	DELETECar(http.ResponseWriter, *http.Request)
 }
 
 type TracksHandler interface {
 	GETAllTracks(http.ResponseWriter, *http.Request)
 	POSTNewTrack(http.ResponseWriter, *http.Request)
 	UPDATETrack(http.ResponseWriter, *http.Request)
+	GETRelatedTracks(http.ResponseWriter, *http.Request)
+	GETTrack(http.ResponseWriter, *http.Request)
+	PUTTrack(http.ResponseWriter, *http.Request)
+	PATCHTrack(http.ResponseWriter, *http.Request)
+	DELETETrack(http.ResponseWriter, *http.Request)
 }
 
 type LogsHandler interface {
@@ -25,10 +36,17 @@ type LogsHandler interface {
 type NationsHandler interface {
 	GETAllTracksNations(http.ResponseWriter, *http.Request)
 	GETAllBrandsNations(http.ResponseWriter, *http.Request)
+	GETAllNations(http.ResponseWriter, *http.Request)
+	GETAllContinents(http.ResponseWriter, *http.Request)
+	SEEDNations(http.ResponseWriter, *http.Request)
+	MERGENations(http.ResponseWriter, *http.Request)
 }
 
 type BrandsHandler interface {
 	GETAllBrands(http.ResponseWriter, *http.Request)
+	GETBrand(http.ResponseWriter, *http.Request)
+	UPDATEBrand(http.ResponseWriter, *http.Request)
+	MERGEBrands(http.ResponseWriter, *http.Request)
 }
 
 type UsersHandler interface {
@@ -41,6 +59,9 @@ type AuthorsHandler interface {
 	GETAllAuthors(http.ResponseWriter, *http.Request)
 	GETTrackAuthors(http.ResponseWriter, *http.Request)
 	GETCarAuthors(http.ResponseWriter, *http.Request)
+	GETAuthor(http.ResponseWriter, *http.Request)
+	UPDATEAuthor(http.ResponseWriter, *http.Request)
+	MERGEAuthors(http.ResponseWriter, *http.Request)
 }
 
 type ServersHandler interface {
@@ -48,6 +69,11 @@ type ServersHandler interface {
 	ADDServer(w http.ResponseWriter, r *http.Request)
 	UPDATEServer(w http.ResponseWriter, r *http.Request)
 	DELETEServer(w http.ResponseWriter, r *http.Request)
+	POSTServer(w http.ResponseWriter, r *http.Request)
+	GETServer(w http.ResponseWriter, r *http.Request)
+	PUTServer(w http.ResponseWriter, r *http.Request)
+	PATCHServer(w http.ResponseWriter, r *http.Request)
+	DELETEServerById(w http.ResponseWriter, r *http.Request)
 }
 
 type SkinHandler interface {
@@ -55,13 +81,72 @@ type SkinHandler interface {
 	GETAllSkins(w http.ResponseWriter, r *http.Request)
 	ADDSkin(w http.ResponseWriter, r *http.Request)
 	UPDATESkin(w http.ResponseWriter, r *http.Request)
+	GETSkins(w http.ResponseWriter, r *http.Request)
+	GETSkin(w http.ResponseWriter, r *http.Request)
+	PUTSkin(w http.ResponseWriter, r *http.Request)
+	PATCHSkin(w http.ResponseWriter, r *http.Request)
+	DELETESkin(w http.ResponseWriter, r *http.Request)
 }
 
 type Middleware interface {
 	IsAuthorized(next http.HandlerFunc) http.HandlerFunc
 	IsAllowed(next http.HandlerFunc, allowedRoles []string) http.HandlerFunc
+	RequestId(next http.Handler) http.Handler
+	IsCached(next http.HandlerFunc) http.HandlerFunc
+	TokenFromQuery(next http.HandlerFunc) http.HandlerFunc
 }
 
 type FirebaseHandler interface {
 	SubscribeToTopic(http.ResponseWriter, *http.Request)
+	UnsubscribeFromTopic(http.ResponseWriter, *http.Request)
+	GETTopics(http.ResponseWriter, *http.Request)
+	GETSubscriptions(http.ResponseWriter, *http.Request)
+}
+
+type ModUpdatesHandler interface {
+	POSTModUpdate(http.ResponseWriter, *http.Request)
+	GETPendingModUpdates(http.ResponseWriter, *http.Request)
+	APPROVEModUpdate(http.ResponseWriter, *http.Request)
+	REJECTModUpdate(http.ResponseWriter, *http.Request)
+}
+
+type DiscordHandler interface {
+	POSTEmbedPreview(http.ResponseWriter, *http.Request)
+}
+
+type NotificationsHandler interface {
+	GETNotifications(http.ResponseWriter, *http.Request)
+	REPLAYNotification(http.ResponseWriter, *http.Request)
+}
+
+type WebhooksHandler interface {
+	GETWebhooks(http.ResponseWriter, *http.Request)
+	GETWebhook(http.ResponseWriter, *http.Request)
+	POSTWebhook(http.ResponseWriter, *http.Request)
+	PUTWebhook(http.ResponseWriter, *http.Request)
+	DELETEWebhook(http.ResponseWriter, *http.Request)
+	GETWebhookDeliveries(http.ResponseWriter, *http.Request)
+}
+
+type ImagesHandler interface {
+	UPLOADImage(http.ResponseWriter, *http.Request)
+	UPLOADSkinImage(http.ResponseWriter, *http.Request)
+	UPLOADBrandLogo(http.ResponseWriter, *http.Request)
+	GETImages(http.ResponseWriter, *http.Request)
+	ADDImage(http.ResponseWriter, *http.Request)
+	UPDATEImage(http.ResponseWriter, *http.Request)
+	DELETEImage(http.ResponseWriter, *http.Request)
+	REORDERImages(http.ResponseWriter, *http.Request)
+}
+
+type FeedsHandler interface {
+	GETFeed(http.ResponseWriter, *http.Request)
+}
+
+type LiveHandler interface {
+	GETLive(http.ResponseWriter, *http.Request)
+}
+
+type GraphqlHandler interface {
+	POSTQuery(http.ResponseWriter, *http.Request)
 }
@@ -1,18 +1,53 @@
 package handlers
 
 import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
+	"github.com/davide/ModRepository/controllers"
 	"github.com/davide/ModRepository/models"
 	"github.com/golang-jwt/jwt"
 	"net/http"
+	"net/url"
+	"strings"
+	"time"
 )
 
+const requestIdHeader = "X-Request-Id"
+
 type MiddlewareImpl struct {
 	Secret string
+	Cache  *controllers.CatalogCache
+}
+
+// RequestId tags every request with an id, reusing the one sent by the client, so errors can be traced in the logs
+func (m MiddlewareImpl) RequestId(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestIdHeader)
+		if id == "" || len(id) > 64 {
+			random := make([]byte, 8)
+			_, _ = rand.Read(random)
+			id = hex.EncodeToString(random)
+		}
+		w.Header().Set(requestIdHeader, id)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// TokenFromQuery accepts the token as a query param, WebSocket and EventSource clients can't set headers
+func (m MiddlewareImpl) TokenFromQuery(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if token := r.URL.Query().Get("token"); token != "" && r.Header.Get("Token") == "" {
+			r.Header.Set("Token", token)
+		}
+		next.ServeHTTP(w, r)
+	}
 }
 
 func (m MiddlewareImpl) IsAuthorized(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		r.Header.Del("Username")
 		if r.Header["Token"] == nil {
 			r.Header.Set("Role", string(models.Base))
 			next.ServeHTTP(w, r)
@@ -33,6 +68,9 @@ func (m MiddlewareImpl) IsAuthorized(next http.HandlerFunc) http.HandlerFunc {
 		}
 
 		if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
+			if username, ok := claims["email"].(string); ok {
+				r.Header.Set("Username", username)
+			}
 			switch claims["role"] {
 			case "admin":
 				{
@@ -53,6 +91,12 @@ func (m MiddlewareImpl) IsAuthorized(next http.HandlerFunc) http.HandlerFunc {
 					next.ServeHTTP(w, r)
 					return
 				}
+			case "author":
+				{
+					r.Header.Set("Role", string(models.ModAuthor))
+					next.ServeHTTP(w, r)
+					return
+				}
 			}
 		}
 		respondError(w, http.StatusUnauthorized, fmt.Errorf("you have no authorization"))
@@ -69,6 +113,100 @@ func (m MiddlewareImpl) IsAllowed(next http.HandlerFunc, allowedRoles []string)
 	}
 }
 
+// bufferedResponse holds a response so it can be stored before being sent
+type bufferedResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (b *bufferedResponse) Header() http.Header {
+	return b.header
+}
+
+func (b *bufferedResponse) Write(data []byte) (int, error) {
+	return b.body.Write(data)
+}
+
+func (b *bufferedResponse) WriteHeader(status int) {
+	b.status = status
+}
+
+// cachedParams are the query params read by the cached endpoints, the others don't change the response so they are
+// left out of the key
+var cachedParams = []string{"carId", "continent", "category", "tag", "brand", "author", "limit"}
+
+func cacheKey(r *http.Request) string {
+	query, kept := r.URL.Query(), url.Values{}
+	for _, name := range cachedParams {
+		if value := query.Get(name); value != "" {
+			kept.Set(name, value)
+		}
+	}
+	return r.Header.Get("Role") + " " + r.URL.Path + "?" + kept.Encode()
+}
+
+// IsCached serves the list endpoints from the catalog cache with ETag and Last-Modified, it must run after
+// IsAuthorized because the role is part of the key
+func (m MiddlewareImpl) IsCached(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := cacheKey(r)
+		response, ok := m.Cache.Get(key)
+		if !ok {
+			generation := m.Cache.Generation()
+			// the buffer starts empty so only the headers of the handler are stored, the ones already on w
+			// (cors, request id) belong to this request
+			buffer := &bufferedResponse{header: http.Header{}, status: http.StatusOK}
+			next.ServeHTTP(buffer, r)
+			if buffer.status != http.StatusOK {
+				copyMissingHeaders(w.Header(), buffer.header)
+				w.WriteHeader(buffer.status)
+				w.Write(buffer.body.Bytes())
+				return
+			}
+			response = m.Cache.Set(key, generation, buffer.header, buffer.body.Bytes())
+		}
+
+		copyMissingHeaders(w.Header(), response.Header)
+		w.Header().Set("ETag", response.ETag)
+		w.Header().Set("Last-Modified", response.LastModified.Format(http.TimeFormat))
+		w.Header().Set("Cache-Control", "private, no-cache")
+		w.Header().Add("Vary", "Token")
+		if notModified(r, response) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write(response.Body)
+	}
+}
+
+// copyMissingHeaders adds the headers of from to header, never overwriting the ones already set
+func copyMissingHeaders(header http.Header, from http.Header) {
+	for name, values := range from {
+		if _, ok := header[name]; !ok {
+			header[name] = append([]string(nil), values...)
+		}
+	}
+}
+
+// notModified checks the conditional headers, If-None-Match wins over If-Modified-Since as in RFC 7232
+func notModified(r *http.Request, response controllers.CachedResponse) bool {
+	if match := r.Header.Get("If-None-Match"); match != "" {
+		for _, etag := range strings.Split(match, ",") {
+			etag = strings.TrimPrefix(strings.TrimSpace(etag), "W/")
+			if etag == "*" || etag == response.ETag {
+				return true
+			}
+		}
+		return false
+	}
+	if since, err := time.Parse(http.TimeFormat, r.Header.Get("If-Modified-Since")); err == nil {
+		return !response.LastModified.After(since)
+	}
+	return false
+}
+
 func contains(arr []string, str string) bool {
 	for _, a := range arr {
 		if a == str {
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/davide/ModRepository/controllers"
+	"github.com/rs/cors"
+)
+
+func TestCachedResponseKeepsTheCorsHeadersOfTheRequest(t *testing.T) {
+	middleware := MiddlewareImpl{Cache: &controllers.CatalogCache{}}
+	calls := 0
+	list := middleware.IsCached(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[]`))
+	})
+	handler := middleware.RequestId(cors.New(cors.Options{
+		AllowedOrigins:   []string{"https://a.com", "https://b.com"},
+		AllowCredentials: true,
+	}).Handler(list))
+
+	for _, origin := range []string{"https://a.com", "https://b.com"} {
+		request := httptest.NewRequest(http.MethodGet, "/car", nil)
+		request.Header.Set("Origin", origin)
+		request.Header.Set(requestIdHeader, "request-"+origin)
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+
+		header := recorder.Header()
+		if allowed := header.Values("Access-Control-Allow-Origin"); len(allowed) != 1 || allowed[0] != origin {
+			t.Errorf("origin %v: Access-Control-Allow-Origin %v", origin, allowed)
+		}
+		if id := header.Values(requestIdHeader); len(id) != 1 || id[0] != "request-"+origin {
+			t.Errorf("origin %v: request id %v", origin, id)
+		}
+		if contentType := header.Get("Content-Type"); contentType != "application/json" {
+			t.Errorf("origin %v: content type %q", origin, contentType)
+		}
+		if recorder.Body.String() != `[]` {
+			t.Errorf("origin %v: body %q", origin, recorder.Body.String())
+		}
+	}
+	if calls != 1 {
+		t.Errorf("the second request should be served from the cache, the handler ran %v times", calls)
+	}
+}
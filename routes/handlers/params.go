@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"fmt"
+	"github.com/gorilla/mux"
+	"net/http"
+	"strconv"
+)
+
+const defaultRelatedLimit = 5
+const maxRelatedLimit = 20
+
+func idParam(request *http.Request, paramName string) (uint, error) {
+	id, err := strconv.ParseUint(mux.Vars(request)[paramName], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid param '%v': %v", paramName, err)
+	}
+	return uint(id), nil
+}
+
+func limitParam(request *http.Request) (int, error) {
+	value := request.URL.Query().Get("limit")
+	if value == "" {
+		return defaultRelatedLimit, nil
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 1 {
+		return 0, fmt.Errorf("invalid param 'limit': must be a positive number")
+	}
+	if limit > maxRelatedLimit {
+		limit = maxRelatedLimit
+	}
+	return limit, nil
+}
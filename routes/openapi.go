@@ -0,0 +1,460 @@
+package routes
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"github.com/davide/ModRepository/models"
+	"github.com/davide/ModRepository/routes/handlers"
+	"github.com/gorilla/mux"
+	"log"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// docsAssets is the page rendering the spec, it is served with its script and style so nothing is loaded from
+// other hosts
+//
+//go:embed docs
+var docsAssets embed.FS
+
+type apiParam struct {
+	Name        string
+	In          string
+	Description string
+}
+
+type apiOperation struct {
+	Method  string
+	Path    string
+	Tag     string
+	Summary string
+	// Roles allowed to call the operation, empty when it is public
+	Roles    []string
+	Params   []apiParam
+	Request  interface{}
+	Response interface{}
+	Status   int
+	// Multipart operations receive an "image" file instead of a json body
+	Multipart bool
+	// Cached operations are served from the catalog cache and answer 304 to conditional requests
+	Cached bool
+	// ContentType of the response when it isn't json
+	ContentType string
+}
+
+var limitQuery = apiParam{Name: "limit", In: "query", Description: "max results per group, 5 by default and at most 20"}
+
+// apiOperations documents every route registered in Listen, routes missing from here are reported at startup
+var apiOperations = []apiOperation{
+	{Method: "POST", Path: "/car/new", Tag: "cars", Summary: "Add a car", Roles: []string{"admin"}, Request: models.Car{}, Response: models.Car{}, Status: http.StatusCreated},
+	{Method: "POST", Path: "/car/update", Tag: "cars", Summary: "Update a car", Roles: []string{"admin"}, Request: models.Car{}, Response: models.Car{}},
+	{Method: "GET", Path: "/car/all", Tag: "cars", Summary: "List the cars, download links depend on the role", Response: []models.Car{}, Cached: true},
+	{Method: "GET", Path: "/car/type/all", Tag: "cars", Summary: "List the car categories", Response: []models.CarCategory{}, Cached: true},
+	{Method: "GET", Path: "/car/{id}/related", Tag: "cars", Summary: "Cars by the same author, brand and category", Params: []apiParam{limitQuery}, Response: models.RelatedCars{}},
+	{Method: "GET", Path: "/car/{id}/similar", Tag: "cars", Summary: "Cars with similar specs", Params: []apiParam{limitQuery}, Response: []models.SimilarCar{}},
+
+	{Method: "POST", Path: "/track/new", Tag: "tracks", Summary: "Add a track", Roles: []string{"admin"}, Request: models.Track{}, Response: models.Track{}, Status: http.StatusCreated},
+	{Method: "POST", Path: "/track/update", Tag: "tracks", Summary: "Update a track", Roles: []string{"admin"}, Request: models.Track{}, Response: models.Track{}},
+	{Method: "GET", Path: "/track/all", Tag: "tracks", Summary: "List the tracks, download links depend on the role", Response: []models.Track{}, Cached: true},
+	{Method: "GET", Path: "/track/{id}/related", Tag: "tracks", Summary: "Tracks by the same author, nation and tags", Params: []apiParam{limitQuery}, Response: models.RelatedTracks{}},
+
+	{Method: "GET", Path: "/log/car/all", Tag: "logs", Summary: "Car releases and updates", Response: []models.CarLog{}, Cached: true},
+	{Method: "GET", Path: "/log/track/all", Tag: "logs", Summary: "Track releases and updates", Response: []models.TrackLog{}, Cached: true},
+
+	{Method: "GET", Path: "/nation/brand/all", Tag: "nations", Summary: "Nations with at least a brand", Params: []apiParam{{Name: "continent", In: "query"}}, Response: []models.Nation{}, Cached: true},
+	{Method: "GET", Path: "/nation/track/all", Tag: "nations", Summary: "Nations with at least a track", Params: []apiParam{{Name: "continent", In: "query"}}, Response: []models.Nation{}, Cached: true},
+	{Method: "GET", Path: "/nation/all", Tag: "nations", Summary: "List the nations", Params: []apiParam{{Name: "continent", In: "query"}}, Response: []models.Nation{}, Cached: true},
+	{Method: "GET", Path: "/nation/continent/all", Tag: "nations", Summary: "List the continents", Response: []models.Continent{}},
+	{Method: "POST", Path: "/nation/seed", Tag: "nations", Summary: "Seed the ISO 3166 nations", Roles: []string{"admin"}, Response: ""},
+	{Method: "POST", Path: "/nation/merge", Tag: "nations", Summary: "Merge a duplicate nation into another", Roles: []string{"admin"}, Request: handlers.MergeRequest{}, Response: ""},
+
+	{Method: "GET", Path: "/brand/all", Tag: "brands", Summary: "List the brands", Response: []models.CarBrand{}, Cached: true},
+	{Method: "POST", Path: "/brand/update", Tag: "brands", Summary: "Update logo, nation or name of a brand", Roles: []string{"admin"}, Request: handlers.BrandUpdateRequest{}, Response: models.CarBrand{}},
+	{Method: "POST", Path: "/brand/merge", Tag: "brands", Summary: "Merge a duplicate brand into another", Roles: []string{"admin"}, Request: handlers.MergeRequest{}, Response: ""},
+	{Method: "GET", Path: "/brand/{name}", Tag: "brands", Summary: "A brand with its cars", Response: models.BrandDetails{}},
+
+	{Method: "GET", Path: "/author/all", Tag: "authors", Summary: "List the authors", Response: []models.Author{}, Cached: true},
+	{Method: "GET", Path: "/car/author/all", Tag: "authors", Summary: "Authors with at least a car", Response: []models.Author{}, Cached: true},
+	{Method: "GET", Path: "/track/author/all", Tag: "authors", Summary: "Authors with at least a track", Response: []models.Author{}, Cached: true},
+	{Method: "POST", Path: "/author/update", Tag: "authors", Summary: "Update an author", Roles: []string{"admin"}, Request: handlers.AuthorUpdateRequest{}, Response: models.Author{}},
+	{Method: "POST", Path: "/author/merge", Tag: "authors", Summary: "Merge a duplicate author into another", Roles: []string{"admin"}, Request: handlers.MergeRequest{}, Response: ""},
+	{Method: "GET", Path: "/author/{name}", Tag: "authors", Summary: "An author with mods and stats", Response: models.AuthorDetails{}},
+
+	{Method: "GET", Path: "/skin", Tag: "skins", Summary: "Skins of a car", Params: []apiParam{{Name: "carId", In: "query"}}, Response: []models.Skin{}, Cached: true},
+	{Method: "GET", Path: "/skin/all", Tag: "skins", Summary: "List the skins", Response: []models.Skin{}, Cached: true},
+	{Method: "POST", Path: "/skin/add", Tag: "skins", Summary: "Add a skin", Roles: []string{"admin"}, Request: models.Skin{}, Response: models.Skin{}, Status: http.StatusCreated},
+	{Method: "POST", Path: "/skin/update", Tag: "skins", Summary: "Update a skin", Roles: []string{"admin"}, Request: models.Skin{}, Response: models.Skin{}, Status: http.StatusCreated},
+
+	{Method: "GET", Path: "/fsr/server1/all", Tag: "servers", Summary: "List the servers", Response: []models.Server{}, Cached: true},
+	{Method: "POST", Path: "/fsr/server1/update", Tag: "servers", Summary: "Update a server", Roles: []string{"admin", "fsrteam"}, Request: models.Server{}, Response: models.Server{}},
+	{Method: "POST", Path: "/fsr/server1/add", Tag: "servers", Summary: "Add a server", Roles: []string{"admin", "fsrteam"}, Request: models.Server{}, Response: models.Server{}},
+	{Method: "POST", Path: "/fsr/server1/delete", Tag: "servers", Summary: "Delete a server", Roles: []string{"admin", "fsrteam"}, Request: models.Server{}, Response: ""},
+
+	{Method: "POST", Path: "/mod/update", Tag: "mod updates", Summary: "Release an update, authors' updates may wait for approval (202)", Roles: []string{"admin", "author"}, Request: models.ModUpdate{}, Response: models.ModUpdate{}},
+	{Method: "GET", Path: "/mod/update/pending", Tag: "mod updates", Summary: "Updates waiting for approval", Roles: []string{"admin"}, Response: []models.ModUpdate{}},
+	{Method: "POST", Path: "/mod/update/{id}/approve", Tag: "mod updates", Summary: "Approve and apply an update", Roles: []string{"admin"}, Response: models.ModUpdate{}},
+	{Method: "POST", Path: "/mod/update/{id}/reject", Tag: "mod updates", Summary: "Reject an update", Roles: []string{"admin"}, Response: ""},
+
+	{Method: "POST", Path: "/{modType}/{id}/image", Tag: "images", Summary: "Upload an image to the gallery", Roles: []string{"admin"}, Multipart: true, Response: models.Image{}, Status: http.StatusCreated},
+	{Method: "GET", Path: "/{modType}/{id}/image/all", Tag: "images", Summary: "The gallery of a mod", Response: []models.Image{}},
+	{Method: "POST", Path: "/{modType}/{id}/image/add", Tag: "images", Summary: "Add an image by url", Roles: []string{"admin"}, Request: models.Image{}, Response: models.Image{}, Status: http.StatusCreated},
+	{Method: "POST", Path: "/{modType}/{id}/image/update", Tag: "images", Summary: "Update caption, alt text or favorite", Roles: []string{"admin"}, Request: models.Image{}, Response: models.Image{}},
+	{Method: "POST", Path: "/{modType}/{id}/image/reorder", Tag: "images", Summary: "Reorder the gallery", Roles: []string{"admin"}, Request: handlers.ReorderRequest{}, Response: []uint{}},
+	{Method: "POST", Path: "/{modType}/{id}/image/{imageId}/delete", Tag: "images", Summary: "Remove an image", Roles: []string{"admin"}, Response: ""},
+	{Method: "POST", Path: "/skin/{id}/image", Tag: "images", Summary: "Upload the image of a skin", Roles: []string{"admin"}, Multipart: true, Response: models.Image{}, Status: http.StatusCreated},
+	{Method: "POST", Path: "/brand/{name}/logo", Tag: "images", Summary: "Upload the logo of a brand", Roles: []string{"admin"}, Multipart: true, Response: models.Image{}, Status: http.StatusCreated},
+
+	{Method: "POST", Path: "/login", Tag: "users", Summary: "Log in and receive a token", Request: models.Authentication{}, Response: models.Token{}, Status: http.StatusAccepted},
+	{Method: "POST", Path: "/signin", Tag: "users", Summary: "Create a user", Roles: []string{"admin"}, Request: models.User{}, Response: models.Token{}, Status: http.StatusAccepted},
+	{Method: "POST", Path: "/user/updatepassword", Tag: "users", Summary: "Change the password of a user", Roles: []string{"admin"}, Request: models.Authentication{}},
+
+	{Method: "POST", Path: "/notification/register", Tag: "notifications", Summary: "Subscribe a device to a topic of the scheme", Request: handlers.SubscribeRequest{}, Response: ""},
+	{Method: "POST", Path: "/notification/unregister", Tag: "notifications", Summary: "Unsubscribe a device of the user from a topic", Request: handlers.SubscribeRequest{}, Response: ""},
+	{Method: "GET", Path: "/notification/subscriptions", Tag: "notifications", Summary: "The devices of the logged user with their topics", Response: []models.DeviceToken{}},
+	{Method: "GET", Path: "/notification/topics", Tag: "notifications", Summary: "The topics, brand_{slug}, author_{slug} and nation_{code} stand for every brand, author and ISO 3166 code", Response: []string{}},
+	{Method: "GET", Path: "/notification/outbox", Tag: "notifications", Summary: "The Discord and Firebase notifications in the outbox, newest first", Roles: []string{"admin"}, Params: []apiParam{
+		{Name: "status", In: "query", Description: "pending, delivered or dead, dead by default"},
+		{Name: "limit", In: "query", Description: "max notifications, 50 by default and at most 500"},
+	}, Response: []models.Notification{}},
+	{Method: "POST", Path: "/notification/outbox/{id}/replay", Tag: "notifications", Summary: "Send a dead notification again", Roles: []string{"admin"}, Response: models.Notification{}},
+	{Method: "POST", Path: "/notification/discord/preview", Tag: "notifications", Summary: "Render the Discord embed of a mod, with the template sent or the one in use", Roles: []string{"admin"}, Request: handlers.EmbedPreviewRequest{}, Response: map[string]interface{}{}},
+
+	{Method: "GET", Path: "/feed/{kind}.{format}", Tag: "feeds", Summary: "Atom or RSS feed of the releases and updates, kind is cars, tracks or all", Params: []apiParam{
+		{Name: "category", In: "query", Description: "car category, tracks are excluded"},
+		{Name: "tag", In: "query", Description: "track tag, cars are excluded"},
+		{Name: "brand", In: "query", Description: "car brand, tracks are excluded"},
+		{Name: "author", In: "query", Description: "author or contributor"},
+		{Name: "limit", In: "query", Description: "max entries, 50 by default and at most 200"},
+	}, ContentType: "application/atom+xml", Cached: true},
+	{Method: "GET", Path: "/live", Tag: "live", Summary: "Catalog changes over a WebSocket, or as Server-Sent Events without the upgrade", Params: []apiParam{
+		{Name: "since", In: "query", Description: "id of the last event received, Last-Event-ID is used too"},
+		{Name: "token", In: "query", Description: "the token for clients that can't set headers"},
+	}, Response: models.LiveEvent{}},
+	{Method: "POST", Path: "/graphql", Tag: "graphql", Summary: "Query the catalog with graphql, download links depend on the role", Request: handlers.GraphqlRequest{}, Response: map[string]interface{}{}},
+
+	{Method: "GET", Path: "/webhooks", Tag: "webhooks", Summary: "The webhook endpoints, without their secrets", Roles: []string{"admin"}, Response: []models.WebhookEndpoint{}},
+	{Method: "POST", Path: "/webhooks", Tag: "webhooks", Summary: "Register an endpoint, the secret is generated when missing and only returned here", Roles: []string{"admin"}, Request: models.WebhookEndpoint{}, Response: models.WebhookEndpoint{}, Status: http.StatusCreated},
+	{Method: "GET", Path: "/webhooks/{id}", Tag: "webhooks", Summary: "A webhook endpoint", Roles: []string{"admin"}, Response: models.WebhookEndpoint{}},
+	{Method: "PUT", Path: "/webhooks/{id}", Tag: "webhooks", Summary: "Update url, description, events and active flag", Roles: []string{"admin"}, Request: models.WebhookEndpoint{}, Response: models.WebhookEndpoint{}},
+	{Method: "DELETE", Path: "/webhooks/{id}", Tag: "webhooks", Summary: "Delete an endpoint with its delivery log", Roles: []string{"admin"}, Status: http.StatusNoContent},
+	{Method: "GET", Path: "/webhooks/{id}/deliveries", Tag: "webhooks", Summary: "The delivery log of an endpoint, newest first", Roles: []string{"admin"}, Params: []apiParam{
+		{Name: "limit", In: "query", Description: "max deliveries, 50 by default and at most 500"},
+	}, Response: []models.WebhookDelivery{}},
+
+	{Method: "GET", Path: "/v2/cars", Tag: "v2 cars", Summary: "List the cars, download links depend on the role", Response: []models.Car{}, Cached: true},
+	{Method: "POST", Path: "/v2/cars", Tag: "v2 cars", Summary: "Add a car", Roles: []string{"admin"}, Request: models.Car{}, Response: models.Car{}, Status: http.StatusCreated},
+	{Method: "GET", Path: "/v2/cars/{id}", Tag: "v2 cars", Summary: "A car", Response: models.Car{}},
+	{Method: "PUT", Path: "/v2/cars/{id}", Tag: "v2 cars", Summary: "Replace a car", Roles: []string{"admin"}, Request: models.Car{}, Response: models.Car{}},
+	{Method: "PATCH", Path: "/v2/cars/{id}", Tag: "v2 cars", Summary: "Update the fields sent of a car", Roles: []string{"admin"}, Request: models.Car{}, Response: models.Car{}},
+	{Method: "DELETE", Path: "/v2/cars/{id}", Tag: "v2 cars", Summary: "Delete a car with its skins", Roles: []string{"admin"}, Status: http.StatusNoContent},
+
+	{Method: "GET", Path: "/v2/tracks", Tag: "v2 tracks", Summary: "List the tracks, download links depend on the role", Response: []models.Track{}, Cached: true},
+	{Method: "POST", Path: "/v2/tracks", Tag: "v2 tracks", Summary: "Add a track", Roles: []string{"admin"}, Request: models.Track{}, Response: models.Track{}, Status: http.StatusCreated},
+	{Method: "GET", Path: "/v2/tracks/{id}", Tag: "v2 tracks", Summary: "A track", Response: models.Track{}},
+	{Method: "PUT", Path: "/v2/tracks/{id}", Tag: "v2 tracks", Summary: "Replace a track", Roles: []string{"admin"}, Request: models.Track{}, Response: models.Track{}},
+	{Method: "PATCH", Path: "/v2/tracks/{id}", Tag: "v2 tracks", Summary: "Update the fields sent of a track", Roles: []string{"admin"}, Request: models.Track{}, Response: models.Track{}},
+	{Method: "DELETE", Path: "/v2/tracks/{id}", Tag: "v2 tracks", Summary: "Delete a track, tracks used by a server are a conflict", Roles: []string{"admin"}, Status: http.StatusNoContent},
+
+	{Method: "GET", Path: "/v2/skins", Tag: "v2 skins", Summary: "List the skins", Params: []apiParam{{Name: "carId", In: "query", Description: "only the skins of this car"}}, Response: []models.Skin{}, Cached: true},
+	{Method: "POST", Path: "/v2/skins", Tag: "v2 skins", Summary: "Add a skin", Roles: []string{"admin"}, Request: models.Skin{}, Response: models.Skin{}, Status: http.StatusCreated},
+	{Method: "GET", Path: "/v2/skins/{id}", Tag: "v2 skins", Summary: "A skin", Response: models.Skin{}},
+	{Method: "PUT", Path: "/v2/skins/{id}", Tag: "v2 skins", Summary: "Replace a skin", Roles: []string{"admin"}, Request: models.Skin{}, Response: models.Skin{}},
+	{Method: "PATCH", Path: "/v2/skins/{id}", Tag: "v2 skins", Summary: "Update the fields sent of a skin", Roles: []string{"admin"}, Request: models.Skin{}, Response: models.Skin{}},
+	{Method: "DELETE", Path: "/v2/skins/{id}", Tag: "v2 skins", Summary: "Delete a skin", Roles: []string{"admin"}, Status: http.StatusNoContent},
+
+	{Method: "GET", Path: "/v2/servers", Tag: "v2 servers", Summary: "List the servers", Response: []models.Server{}, Cached: true},
+	{Method: "POST", Path: "/v2/servers", Tag: "v2 servers", Summary: "Add a server", Roles: []string{"admin", "fsrteam"}, Request: models.Server{}, Response: models.Server{}, Status: http.StatusCreated},
+	{Method: "GET", Path: "/v2/servers/{id}", Tag: "v2 servers", Summary: "A server", Response: models.Server{}},
+	{Method: "PUT", Path: "/v2/servers/{id}", Tag: "v2 servers", Summary: "Replace a server", Roles: []string{"admin", "fsrteam"}, Request: models.Server{}, Response: models.Server{}},
+	{Method: "PATCH", Path: "/v2/servers/{id}", Tag: "v2 servers", Summary: "Update the fields sent of a server", Roles: []string{"admin", "fsrteam"}, Request: models.Server{}, Response: models.Server{}},
+	{Method: "DELETE", Path: "/v2/servers/{id}", Tag: "v2 servers", Summary: "Delete a server", Roles: []string{"admin", "fsrteam"}, Status: http.StatusNoContent},
+}
+
+// enumValues lists the accepted values of the enum types, the validation uses the same lists
+var enumValues = map[reflect.Type][]string{}
+
+func registerEnum(values interface{}) {
+	list := reflect.ValueOf(values)
+	var names []string
+	for i := 0; i < list.Len(); i++ {
+		names = append(names, list.Index(i).String())
+	}
+	enumValues[list.Type().Elem()] = names
+}
+
+func init() {
+	registerEnum(models.CarTypes)
+	registerEnum(models.Drivetrains)
+	registerEnum(models.Transmissions)
+	registerEnum(models.TrackTags)
+	registerEnum(models.LayoutTypes)
+	registerEnum(models.ContributionRoles)
+	registerEnum(models.Continents)
+	registerEnum(models.LiveEventTypes)
+	registerEnum([]models.ModType{models.CarModType, models.TrackModType})
+	registerEnum([]models.Role{models.Admin, models.Premium, models.Base, models.FSRTeam, models.ModAuthor})
+	registerEnum([]models.UpdateStatus{models.PendingUpdate, models.ApprovedUpdate, models.RejectedUpdate})
+}
+
+type schemaBuilder struct {
+	components map[string]interface{}
+}
+
+// schema describes a go type by its json encoding, named structs become components
+func (s schemaBuilder) schema(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == reflect.TypeOf(time.Time{}) {
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+	if values, ok := enumValues[t]; ok {
+		return map[string]interface{}{"type": "string", "enum": values}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": s.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": s.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.structSchema(t)
+		}
+		if _, ok := s.components[t.Name()]; !ok {
+			// placeholder against recursive types
+			s.components[t.Name()] = nil
+			s.components[t.Name()] = s.structSchema(t)
+		}
+		return map[string]interface{}{"$ref": "#/components/schemas/" + t.Name()}
+	}
+	return map[string]interface{}{}
+}
+
+func (s schemaBuilder) structSchema(t reflect.Type) map[string]interface{} {
+	properties := map[string]interface{}{}
+	s.addFields(t, properties)
+	return map[string]interface{}{"type": "object", "properties": properties}
+}
+
+func (s schemaBuilder) addFields(t reflect.Type, properties map[string]interface{}) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			s.addFields(field.Type, properties)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = s.schema(field.Type)
+	}
+}
+
+var pathVarPattern = regexp.MustCompile(`\{([^}:]+)(:[^}]*)?\}`)
+
+// specPath turns a mux path template into an OpenAPI path, dropping the variable patterns
+func specPath(template string) string {
+	return pathVarPattern.ReplaceAllString(template, "{$1}")
+}
+
+func (o apiOperation) spec(s schemaBuilder) map[string]interface{} {
+	var parameters []interface{}
+	for _, match := range pathVarPattern.FindAllStringSubmatch(o.Path, -1) {
+		schema := map[string]interface{}{"type": "string"}
+		if match[1] == "modType" {
+			schema["enum"] = []string{string(models.CarModType), string(models.TrackModType)}
+		} else if strings.HasSuffix(strings.ToLower(match[1]), "id") {
+			schema = map[string]interface{}{"type": "integer", "minimum": 1}
+		}
+		parameters = append(parameters, map[string]interface{}{"name": match[1], "in": "path", "required": true, "schema": schema})
+	}
+	for _, param := range o.Params {
+		parameters = append(parameters, map[string]interface{}{"name": param.Name, "in": param.In, "description": param.Description, "schema": map[string]interface{}{"type": "string"}})
+	}
+
+	if o.Cached {
+		parameters = append(parameters,
+			map[string]interface{}{"name": "If-None-Match", "in": "header", "schema": map[string]interface{}{"type": "string"}},
+			map[string]interface{}{"name": "If-Modified-Since", "in": "header", "schema": map[string]interface{}{"type": "string"}})
+	}
+
+	description := "Public"
+	if len(o.Roles) > 0 {
+		description = "Roles: " + strings.Join(o.Roles, ", ")
+	}
+
+	status := o.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	success := map[string]interface{}{"description": http.StatusText(status)}
+	if o.ContentType != "" {
+		success["content"] = map[string]interface{}{o.ContentType: map[string]interface{}{"schema": map[string]interface{}{"type": "string"}}}
+	} else if o.Response != nil {
+		success["content"] = map[string]interface{}{"application/json": map[string]interface{}{"schema": s.schema(reflect.TypeOf(o.Response))}}
+	}
+	if o.Cached {
+		success["headers"] = map[string]interface{}{
+			"ETag":          map[string]interface{}{"schema": map[string]interface{}{"type": "string"}},
+			"Last-Modified": map[string]interface{}{"schema": map[string]interface{}{"type": "string"}},
+		}
+	}
+	errorContent := map[string]interface{}{"application/json": map[string]interface{}{"schema": s.schema(reflect.TypeOf(handlers.ErrorResponse{}))}}
+
+	operation := map[string]interface{}{
+		"tags":        []string{o.Tag},
+		"summary":     o.Summary,
+		"description": description,
+		"x-roles":     o.Roles,
+		"responses": map[string]interface{}{
+			fmt.Sprint(status): success,
+			"default":          map[string]interface{}{"description": "Error envelope", "content": errorContent},
+		},
+	}
+	if o.Cached {
+		operation["responses"].(map[string]interface{})["304"] = map[string]interface{}{"description": http.StatusText(http.StatusNotModified)}
+	}
+	if len(parameters) > 0 {
+		operation["parameters"] = parameters
+	}
+	if len(o.Roles) > 0 {
+		operation["security"] = []interface{}{map[string]interface{}{"token": []string{}}}
+	}
+	if o.Multipart {
+		operation["requestBody"] = map[string]interface{}{
+			"required": true,
+			"content": map[string]interface{}{"multipart/form-data": map[string]interface{}{"schema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"image":    map[string]interface{}{"type": "string", "format": "binary"},
+					"favorite": map[string]interface{}{"type": "boolean"},
+					"caption":  map[string]interface{}{"type": "string"},
+					"altText":  map[string]interface{}{"type": "string"},
+				},
+				"required": []string{"image"},
+			}}},
+		}
+	} else if o.Request != nil {
+		operation["requestBody"] = map[string]interface{}{
+			"required": true,
+			"content":  map[string]interface{}{"application/json": map[string]interface{}{"schema": s.schema(reflect.TypeOf(o.Request))}},
+		}
+	}
+	return operation
+}
+
+func openApiSpec() map[string]interface{} {
+	s := schemaBuilder{components: map[string]interface{}{}}
+	paths := map[string]map[string]interface{}{}
+	for _, operation := range apiOperations {
+		path := specPath(operation.Path)
+		if paths[path] == nil {
+			paths[path] = map[string]interface{}{}
+		}
+		paths[path][strings.ToLower(operation.Method)] = operation.spec(s)
+	}
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":       "AC Mod Repository API",
+			"version":     "1.0.0",
+			"description": "The role is read from the jwt sent in the Token header, requests without it have the base role",
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas":         s.components,
+			"securitySchemes": map[string]interface{}{"token": map[string]interface{}{"type": "apiKey", "in": "header", "name": "Token"}},
+		},
+	}
+}
+
+// docsContentPolicy lets the docs page load only its own assets and the spec
+const docsContentPolicy = "default-src 'none'; script-src 'self'; style-src 'self'; connect-src 'self'; img-src 'self'"
+
+// unspecifiedPrefixes are the routes that aren't part of the api, like the spec itself
+var unspecifiedPrefixes = []string{"/openapi.json", "/docs", "/images/"}
+
+// undocumentedRoutes lists the registered routes that have no operation in the spec
+func undocumentedRoutes(router *mux.Router, ignored ...string) []string {
+	documented := map[string]bool{}
+	for _, operation := range apiOperations {
+		documented[operation.Method+" "+specPath(operation.Path)] = true
+	}
+	var missing []string
+	_ = router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		template, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+		for _, prefix := range ignored {
+			if strings.HasPrefix(template, prefix) {
+				return nil
+			}
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			return nil
+		}
+		for _, method := range methods {
+			if key := method + " " + specPath(template); !documented[key] {
+				missing = append(missing, key)
+			}
+		}
+		return nil
+	})
+	sort.Strings(missing)
+	return missing
+}
+
+func serveApiSpec(router *mux.Router) {
+	spec, err := json.Marshal(openApiSpec())
+	if err != nil {
+		log.Fatal(fmt.Errorf("error building the openapi spec: %v", err))
+	}
+	router.HandleFunc("/openapi.json", func(writer http.ResponseWriter, _ *http.Request) {
+		writer.Header().Set("Content-Type", "application/json")
+		writer.Header().Set("Access-Control-Allow-Origin", "*")
+		writer.Write(spec)
+	}).Methods("GET")
+	docsPage, err := docsAssets.ReadFile("docs/index.html")
+	if err != nil {
+		log.Fatal(fmt.Errorf("error reading the docs page: %v", err))
+	}
+	router.HandleFunc("/docs", func(writer http.ResponseWriter, _ *http.Request) {
+		writer.Header().Set("Content-Type", "text/html; charset=utf-8")
+		writer.Header().Set("Content-Security-Policy", docsContentPolicy)
+		writer.Write(docsPage)
+	}).Methods("GET")
+	assets := http.FileServer(http.FS(docsAssets))
+	router.PathPrefix("/docs/").Handler(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Content-Security-Policy", docsContentPolicy)
+		assets.ServeHTTP(writer, request)
+	})).Methods("GET")
+
+	for _, route := range undocumentedRoutes(router, unspecifiedPrefixes...) {
+		log.Printf("route missing from the openapi spec: %v", route)
+	}
+}
@@ -0,0 +1,85 @@
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/davide/ModRepository/routes/handlers"
+	"github.com/gorilla/mux"
+)
+
+// testWeb has every handler set, the routes are only registered and never served
+func testWeb() Web {
+	return Web{
+		CarHandler:      handlers.CarsHandlerImpl{},
+		TracksHandler:   handlers.TrackHandlerImpl{},
+		NationHandler:   handlers.NationsHandlerImpl{},
+		BrandsHandler:   handlers.BrandsHandlerImpl{},
+		UsersHandler:    handlers.UserHandlerImpl{},
+		AuthorsHandler:  handlers.AuthorHandlerImpl{},
+		LogsHandler:     handlers.LogsHandlerImpl{},
+		ServersHandler:  handlers.ServersHandlerImpl{},
+		Middleware:      handlers.MiddlewareImpl{},
+		FirebaseHandler: handlers.FirebaseHandlerImpl{},
+		SkinsHandler:    handlers.SkinsHandlerImpl{},
+		ModUpdates:      handlers.ModUpdatesHandlerImpl{},
+		ImagesHandler:   handlers.ImagesHandlerImpl{},
+		GraphqlHandler:  handlers.NewGraphqlHandler(handlers.GraphqlResolver{}),
+		LiveHandler:     handlers.LiveHandlerImpl{},
+		FeedsHandler:    handlers.FeedsHandlerImpl{},
+		WebhooksHandler: handlers.WebhooksHandlerImpl{},
+		Notifications:   handlers.NotificationsHandlerImpl{},
+		DiscordHandler:  handlers.DiscordHandlerImpl{},
+	}
+}
+
+func TestEveryRouteIsDocumented(t *testing.T) {
+	router := testWeb().Router()
+
+	for _, route := range undocumentedRoutes(router, unspecifiedPrefixes...) {
+		t.Errorf("route missing from the openapi spec: %v", route)
+	}
+}
+
+func TestEveryOperationIsRouted(t *testing.T) {
+	router := testWeb().Router()
+
+	routed := map[string]bool{}
+	_ = router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		template, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+		methods, _ := route.GetMethods()
+		for _, method := range methods {
+			routed[method+" "+specPath(template)] = true
+		}
+		return nil
+	})
+	for _, operation := range apiOperations {
+		if key := operation.Method + " " + specPath(operation.Path); !routed[key] {
+			t.Errorf("operation without a route: %v", key)
+		}
+	}
+}
+
+func TestDocsAreServedWithoutOtherHosts(t *testing.T) {
+	router := testWeb().Router()
+
+	for _, path := range []string{"/docs", "/docs/docs.js", "/docs/docs.css"} {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		if recorder.Code != http.StatusOK {
+			t.Errorf("%v: status %v", path, recorder.Code)
+			continue
+		}
+		if policy := recorder.Header().Get("Content-Security-Policy"); policy != docsContentPolicy {
+			t.Errorf("%v: content security policy %q", path, policy)
+		}
+		if body := recorder.Body.String(); strings.Contains(body, "https://") {
+			t.Errorf("%v loads assets from another host", path)
+		}
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"golang.org/x/crypto/acme/autocert"
 	"log"
 	"net/http"
+	"os"
 	"sync"
 )
 
@@ -27,51 +28,172 @@ type Web struct {
 	Middleware      handlers.Middleware
 	FirebaseHandler handlers.FirebaseHandler
 	SkinsHandler    handlers.SkinHandler
+	ModUpdates      handlers.ModUpdatesHandler
+	ImagesHandler   handlers.ImagesHandler
+	GraphqlHandler  handlers.GraphqlHandler
+	LiveHandler     handlers.LiveHandler
+	FeedsHandler    handlers.FeedsHandler
+	WebhooksHandler handlers.WebhooksHandler
+	Notifications   handlers.NotificationsHandler
+	DiscordHandler  handlers.DiscordHandler
+	ImagesDir       string
 }
 
-func (w Web) Listen() {
+// imagesFileSystem serves the stored images without listing the directories
+type imagesFileSystem struct {
+	fs http.FileSystem
+}
+
+func (i imagesFileSystem) Open(name string) (http.File, error) {
+	file, err := i.fs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	if stat, err := file.Stat(); err != nil || stat.IsDir() {
+		file.Close()
+		return nil, os.ErrNotExist
+	}
+	return file, nil
+}
+
+// listenV2 registers the resource oriented routes, they share the handlers and controllers of the legacy ones
+func (w Web) listenV2(router *mux.Router) {
+	admin := []string{"admin"}
+	serverAdmin := []string{"admin", "fsrteam"}
+
+	router.HandleFunc("/cars", w.Middleware.IsAuthorized(w.Middleware.IsCached(w.CarHandler.GETAllCars))).Methods("GET")
+	router.HandleFunc("/cars", w.Middleware.IsAuthorized(w.Middleware.IsAllowed(w.CarHandler.POSTNewCar, admin))).Methods("POST")
+	router.HandleFunc("/cars/{id:[0-9]+}", w.Middleware.IsAuthorized(w.CarHandler.GETCar)).Methods("GET")
+	router.HandleFunc("/cars/{id:[0-9]+}", w.Middleware.IsAuthorized(w.Middleware.IsAllowed(w.CarHandler.PUTCar, admin))).Methods("PUT")
+	router.HandleFunc("/cars/{id:[0-9]+}", w.Middleware.IsAuthorized(w.Middleware.IsAllowed(w.CarHandler.PATCHCar, admin))).Methods("PATCH")
+	router.HandleFunc("/cars/{id:[0-9]+}", w.Middleware.IsAuthorized(w.Middleware.IsAllowed(w.CarHandler.DELETECar, admin))).Methods("DELETE")
+
+	router.HandleFunc("/tracks", w.Middleware.IsAuthorized(w.Middleware.IsCached(w.TracksHandler.GETAllTracks))).Methods("GET")
+	router.HandleFunc("/tracks", w.Middleware.IsAuthorized(w.Middleware.IsAllowed(w.TracksHandler.POSTNewTrack, admin))).Methods("POST")
+	router.HandleFunc("/tracks/{id:[0-9]+}", w.Middleware.IsAuthorized(w.TracksHandler.GETTrack)).Methods("GET")
+	router.HandleFunc("/tracks/{id:[0-9]+}", w.Middleware.IsAuthorized(w.Middleware.IsAllowed(w.TracksHandler.PUTTrack, admin))).Methods("PUT")
+	router.HandleFunc("/tracks/{id:[0-9]+}", w.Middleware.IsAuthorized(w.Middleware.IsAllowed(w.TracksHandler.PATCHTrack, admin))).Methods("PATCH")
+	router.HandleFunc("/tracks/{id:[0-9]+}", w.Middleware.IsAuthorized(w.Middleware.IsAllowed(w.TracksHandler.DELETETrack, admin))).Methods("DELETE")
+
+	router.HandleFunc("/skins", w.Middleware.IsAuthorized(w.Middleware.IsCached(w.SkinsHandler.GETSkins))).Methods("GET")
+	router.HandleFunc("/skins", w.Middleware.IsAuthorized(w.Middleware.IsAllowed(w.SkinsHandler.ADDSkin, admin))).Methods("POST")
+	router.HandleFunc("/skins/{id:[0-9]+}", w.Middleware.IsAuthorized(w.SkinsHandler.GETSkin)).Methods("GET")
+	router.HandleFunc("/skins/{id:[0-9]+}", w.Middleware.IsAuthorized(w.Middleware.IsAllowed(w.SkinsHandler.PUTSkin, admin))).Methods("PUT")
+	router.HandleFunc("/skins/{id:[0-9]+}", w.Middleware.IsAuthorized(w.Middleware.IsAllowed(w.SkinsHandler.PATCHSkin, admin))).Methods("PATCH")
+	router.HandleFunc("/skins/{id:[0-9]+}", w.Middleware.IsAuthorized(w.Middleware.IsAllowed(w.SkinsHandler.DELETESkin, admin))).Methods("DELETE")
+
+	router.HandleFunc("/servers", w.Middleware.IsAuthorized(w.Middleware.IsCached(w.ServersHandler.GETAllServers))).Methods("GET")
+	router.HandleFunc("/servers", w.Middleware.IsAuthorized(w.Middleware.IsAllowed(w.ServersHandler.POSTServer, serverAdmin))).Methods("POST")
+	router.HandleFunc("/servers/{id:[0-9]+}", w.Middleware.IsAuthorized(w.ServersHandler.GETServer)).Methods("GET")
+	router.HandleFunc("/servers/{id:[0-9]+}", w.Middleware.IsAuthorized(w.Middleware.IsAllowed(w.ServersHandler.PUTServer, serverAdmin))).Methods("PUT")
+	router.HandleFunc("/servers/{id:[0-9]+}", w.Middleware.IsAuthorized(w.Middleware.IsAllowed(w.ServersHandler.PATCHServer, serverAdmin))).Methods("PATCH")
+	router.HandleFunc("/servers/{id:[0-9]+}", w.Middleware.IsAuthorized(w.Middleware.IsAllowed(w.ServersHandler.DELETEServerById, serverAdmin))).Methods("DELETE")
+}
+
+// Router registers every route, Listen serves it
+func (w Web) Router() *mux.Router {
 	router := mux.NewRouter().StrictSlash(true)
+	router.Use(w.Middleware.RequestId)
+	// the middlewares only run on the matched routes
+	router.NotFoundHandler = w.Middleware.RequestId(http.HandlerFunc(handlers.NotFound))
+	router.MethodNotAllowedHandler = w.Middleware.RequestId(http.HandlerFunc(handlers.MethodNotAllowed))
 	router.HandleFunc("/car/new", w.Middleware.IsAuthorized(w.Middleware.IsAllowed(w.CarHandler.POSTNewCar, []string{"admin"}))).Methods("POST")
 	router.HandleFunc("/car/update", w.Middleware.IsAuthorized(w.Middleware.IsAllowed(w.CarHandler.UPDATECar, []string{"admin"}))).Methods("POST")
-	router.HandleFunc("/car/all", w.Middleware.IsAuthorized(w.CarHandler.GETAllCars)).Methods("GET")
-	router.HandleFunc("/car/type/all", w.Middleware.IsAuthorized(w.CarHandler.GETAllCarCategories)).Methods("GET")
+	router.HandleFunc("/car/all", w.Middleware.IsAuthorized(w.Middleware.IsCached(w.CarHandler.GETAllCars))).Methods("GET")
+	router.HandleFunc("/car/type/all", w.Middleware.IsAuthorized(w.Middleware.IsCached(w.CarHandler.GETAllCarCategories))).Methods("GET")
+	router.HandleFunc("/car/{id:[0-9]+}/related", w.Middleware.IsAuthorized(w.CarHandler.GETRelatedCars)).Methods("GET")
+	router.HandleFunc("/car/{id:[0-9]+}/similar", w.Middleware.IsAuthorized(w.CarHandler.GETSimilarCars)).Methods("GET")
 
 	router.HandleFunc("/track/new", w.Middleware.IsAuthorized(w.Middleware.IsAllowed(w.TracksHandler.POSTNewTrack, []string{"admin"}))).Methods("POST")
 	router.HandleFunc("/track/update", w.Middleware.IsAuthorized(w.Middleware.IsAllowed(w.TracksHandler.UPDATETrack, []string{"admin"}))).Methods("POST")
-	router.HandleFunc("/track/all", w.Middleware.IsAuthorized(w.TracksHandler.GETAllTracks)).Methods("GET")
-
-	router.HandleFunc("/log/car/all", w.Middleware.IsAuthorized(w.LogsHandler.GETAllCarLogs)).Methods("GET")
-	router.HandleFunc("/log/track/all", w.Middleware.IsAuthorized(w.LogsHandler.GETAllTrackLogs)).Methods("GET")
-
-	router.HandleFunc("/nation/brand/all", w.Middleware.IsAuthorized(w.NationHandler.GETAllBrandsNations)).Methods("GET")
-	router.HandleFunc("/nation/track/all", w.Middleware.IsAuthorized(w.NationHandler.GETAllTracksNations)).Methods("GET")
-
-	router.HandleFunc("/brand/all", w.Middleware.IsAuthorized(w.BrandsHandler.GETAllBrands)).Methods("GET")
-
-	router.HandleFunc("/author/all", w.Middleware.IsAuthorized(w.AuthorsHandler.GETAllAuthors)).Methods("GET")
-	router.HandleFunc("/car/author/all", w.Middleware.IsAuthorized(w.AuthorsHandler.GETCarAuthors)).Methods("GET")
-	router.HandleFunc("/track/author/all", w.Middleware.IsAuthorized(w.AuthorsHandler.GETTrackAuthors)).Methods("GET")
-
-	router.HandleFunc("/skin", w.Middleware.IsAuthorized(w.SkinsHandler.GETCarSkins)).Methods("GET")
-	router.HandleFunc("/skin/all", w.Middleware.IsAuthorized(w.SkinsHandler.GETAllSkins)).Methods("GET")
+	router.HandleFunc("/track/all", w.Middleware.IsAuthorized(w.Middleware.IsCached(w.TracksHandler.GETAllTracks))).Methods("GET")
+	router.HandleFunc("/track/{id:[0-9]+}/related", w.Middleware.IsAuthorized(w.TracksHandler.GETRelatedTracks)).Methods("GET")
+
+	router.HandleFunc("/log/car/all", w.Middleware.IsAuthorized(w.Middleware.IsCached(w.LogsHandler.GETAllCarLogs))).Methods("GET")
+	router.HandleFunc("/log/track/all", w.Middleware.IsAuthorized(w.Middleware.IsCached(w.LogsHandler.GETAllTrackLogs))).Methods("GET")
+
+	router.HandleFunc("/nation/brand/all", w.Middleware.IsAuthorized(w.Middleware.IsCached(w.NationHandler.GETAllBrandsNations))).Methods("GET")
+	router.HandleFunc("/nation/track/all", w.Middleware.IsAuthorized(w.Middleware.IsCached(w.NationHandler.GETAllTracksNations))).Methods("GET")
+	router.HandleFunc("/nation/all", w.Middleware.IsAuthorized(w.Middleware.IsCached(w.NationHandler.GETAllNations))).Methods("GET")
+	router.HandleFunc("/nation/continent/all", w.Middleware.IsAuthorized(w.NationHandler.GETAllContinents)).Methods("GET")
+	router.HandleFunc("/nation/seed", w.Middleware.IsAuthorized(w.Middleware.IsAllowed(w.NationHandler.SEEDNations, []string{"admin"}))).Methods("POST")
+	router.HandleFunc("/nation/merge", w.Middleware.IsAuthorized(w.Middleware.IsAllowed(w.NationHandler.MERGENations, []string{"admin"}))).Methods("POST")
+
+	router.HandleFunc("/brand/all", w.Middleware.IsAuthorized(w.Middleware.IsCached(w.BrandsHandler.GETAllBrands))).Methods("GET")
+	router.HandleFunc("/brand/update", w.Middleware.IsAuthorized(w.Middleware.IsAllowed(w.BrandsHandler.UPDATEBrand, []string{"admin"}))).Methods("POST")
+	router.HandleFunc("/brand/merge", w.Middleware.IsAuthorized(w.Middleware.IsAllowed(w.BrandsHandler.MERGEBrands, []string{"admin"}))).Methods("POST")
+	router.HandleFunc("/brand/{name}", w.Middleware.IsAuthorized(w.BrandsHandler.GETBrand)).Methods("GET")
+
+	router.HandleFunc("/author/all", w.Middleware.IsAuthorized(w.Middleware.IsCached(w.AuthorsHandler.GETAllAuthors))).Methods("GET")
+	router.HandleFunc("/car/author/all", w.Middleware.IsAuthorized(w.Middleware.IsCached(w.AuthorsHandler.GETCarAuthors))).Methods("GET")
+	router.HandleFunc("/track/author/all", w.Middleware.IsAuthorized(w.Middleware.IsCached(w.AuthorsHandler.GETTrackAuthors))).Methods("GET")
+	router.HandleFunc("/author/update", w.Middleware.IsAuthorized(w.Middleware.IsAllowed(w.AuthorsHandler.UPDATEAuthor, []string{"admin"}))).Methods("POST")
+	router.HandleFunc("/author/merge", w.Middleware.IsAuthorized(w.Middleware.IsAllowed(w.AuthorsHandler.MERGEAuthors, []string{"admin"}))).Methods("POST")
+	router.HandleFunc("/author/{name}", w.Middleware.IsAuthorized(w.AuthorsHandler.GETAuthor)).Methods("GET")
+
+	router.HandleFunc("/skin", w.Middleware.IsAuthorized(w.Middleware.IsCached(w.SkinsHandler.GETCarSkins))).Methods("GET")
+	router.HandleFunc("/skin/all", w.Middleware.IsAuthorized(w.Middleware.IsCached(w.SkinsHandler.GETAllSkins))).Methods("GET")
 	router.HandleFunc("/skin/add", w.Middleware.IsAuthorized(w.Middleware.IsAllowed(w.SkinsHandler.ADDSkin, []string{"admin"}))).Methods("POST")
 	router.HandleFunc("/skin/update", w.Middleware.IsAuthorized(w.Middleware.IsAllowed(w.SkinsHandler.UPDATESkin, []string{"admin"}))).Methods("POST")
 
-	router.HandleFunc("/fsr/server1/all", w.Middleware.IsAuthorized(w.ServersHandler.GETAllServers)).Methods("GET")
+	router.HandleFunc("/fsr/server1/all", w.Middleware.IsAuthorized(w.Middleware.IsCached(w.ServersHandler.GETAllServers))).Methods("GET")
 	router.HandleFunc("/fsr/server1/update", w.Middleware.IsAuthorized(w.Middleware.IsAllowed(w.ServersHandler.UPDATEServer, []string{"admin", "fsrteam"}))).Methods("POST")
 	router.HandleFunc("/fsr/server1/add", w.Middleware.IsAuthorized(w.Middleware.IsAllowed(w.ServersHandler.ADDServer, []string{"admin", "fsrteam"}))).Methods("POST")
 	router.HandleFunc("/fsr/server1/delete", w.Middleware.IsAuthorized(w.Middleware.IsAllowed(w.ServersHandler.DELETEServer, []string{"admin", "fsrteam"}))).Methods("POST")
 
+	router.HandleFunc("/mod/update", w.Middleware.IsAuthorized(w.Middleware.IsAllowed(w.ModUpdates.POSTModUpdate, []string{"admin", "author"}))).Methods("POST")
+	router.HandleFunc("/mod/update/pending", w.Middleware.IsAuthorized(w.Middleware.IsAllowed(w.ModUpdates.GETPendingModUpdates, []string{"admin"}))).Methods("GET")
+	router.HandleFunc("/mod/update/{id:[0-9]+}/approve", w.Middleware.IsAuthorized(w.Middleware.IsAllowed(w.ModUpdates.APPROVEModUpdate, []string{"admin"}))).Methods("POST")
+	router.HandleFunc("/mod/update/{id:[0-9]+}/reject", w.Middleware.IsAuthorized(w.Middleware.IsAllowed(w.ModUpdates.REJECTModUpdate, []string{"admin"}))).Methods("POST")
+
+	router.HandleFunc("/{modType:car|track}/{id:[0-9]+}/image", w.Middleware.IsAuthorized(w.Middleware.IsAllowed(w.ImagesHandler.UPLOADImage, []string{"admin"}))).Methods("POST")
+	router.HandleFunc("/{modType:car|track}/{id:[0-9]+}/image/all", w.Middleware.IsAuthorized(w.ImagesHandler.GETImages)).Methods("GET")
+	router.HandleFunc("/{modType:car|track}/{id:[0-9]+}/image/add", w.Middleware.IsAuthorized(w.Middleware.IsAllowed(w.ImagesHandler.ADDImage, []string{"admin"}))).Methods("POST")
+	router.HandleFunc("/{modType:car|track}/{id:[0-9]+}/image/update", w.Middleware.IsAuthorized(w.Middleware.IsAllowed(w.ImagesHandler.UPDATEImage, []string{"admin"}))).Methods("POST")
+	router.HandleFunc("/{modType:car|track}/{id:[0-9]+}/image/reorder", w.Middleware.IsAuthorized(w.Middleware.IsAllowed(w.ImagesHandler.REORDERImages, []string{"admin"}))).Methods("POST")
+	router.HandleFunc("/{modType:car|track}/{id:[0-9]+}/image/{imageId:[0-9]+}/delete", w.Middleware.IsAuthorized(w.Middleware.IsAllowed(w.ImagesHandler.DELETEImage, []string{"admin"}))).Methods("POST")
+	router.HandleFunc("/skin/{id:[0-9]+}/image", w.Middleware.IsAuthorized(w.Middleware.IsAllowed(w.ImagesHandler.UPLOADSkinImage, []string{"admin"}))).Methods("POST")
+	router.HandleFunc("/brand/{name}/logo", w.Middleware.IsAuthorized(w.Middleware.IsAllowed(w.ImagesHandler.UPLOADBrandLogo, []string{"admin"}))).Methods("POST")
+	router.PathPrefix("/images/").Handler(http.StripPrefix("/images/", http.FileServer(imagesFileSystem{fs: http.Dir(w.ImagesDir)}))).Methods("GET")
+
 	router.HandleFunc("/login", w.UsersHandler.LogIn).Methods("POST")
 	router.HandleFunc("/signin", w.Middleware.IsAuthorized(w.Middleware.IsAllowed(w.UsersHandler.SignIn, []string{"admin"}))).Methods("POST")
 	router.HandleFunc("/user/updatepassword", w.Middleware.IsAuthorized(w.Middleware.IsAllowed(w.UsersHandler.UpdatePassword, []string{"admin"}))).Methods("POST")
 
 	router.HandleFunc("/notification/register", w.Middleware.IsAuthorized(w.FirebaseHandler.SubscribeToTopic)).Methods("POST")
+	router.HandleFunc("/notification/unregister", w.Middleware.IsAuthorized(w.FirebaseHandler.UnsubscribeFromTopic)).Methods("POST")
+	router.HandleFunc("/notification/topics", w.Middleware.IsAuthorized(w.FirebaseHandler.GETTopics)).Methods("GET")
+	router.HandleFunc("/notification/subscriptions", w.Middleware.IsAuthorized(w.FirebaseHandler.GETSubscriptions)).Methods("GET")
+	router.HandleFunc("/notification/outbox", w.Middleware.IsAuthorized(w.Middleware.IsAllowed(w.Notifications.GETNotifications, []string{"admin"}))).Methods("GET")
+	router.HandleFunc("/notification/discord/preview", w.Middleware.IsAuthorized(w.Middleware.IsAllowed(w.DiscordHandler.POSTEmbedPreview, []string{"admin"}))).Methods("POST")
+	router.HandleFunc("/notification/outbox/{id:[0-9]+}/replay", w.Middleware.IsAuthorized(w.Middleware.IsAllowed(w.Notifications.REPLAYNotification, []string{"admin"}))).Methods("POST")
+
+	w.listenV2(router.PathPrefix("/v2").Subrouter())
+
+	router.HandleFunc("/feed/{kind:cars|tracks|all}.{format:atom|rss}", w.Middleware.IsAuthorized(w.Middleware.IsCached(w.FeedsHandler.GETFeed))).Methods("GET")
+	router.HandleFunc("/live", w.Middleware.TokenFromQuery(w.Middleware.IsAuthorized(w.LiveHandler.GETLive))).Methods("GET")
+	router.HandleFunc("/graphql", w.Middleware.IsAuthorized(w.GraphqlHandler.POSTQuery)).Methods("POST")
+
+	router.HandleFunc("/webhooks", w.Middleware.IsAuthorized(w.Middleware.IsAllowed(w.WebhooksHandler.GETWebhooks, []string{"admin"}))).Methods("GET")
+	router.HandleFunc("/webhooks", w.Middleware.IsAuthorized(w.Middleware.IsAllowed(w.WebhooksHandler.POSTWebhook, []string{"admin"}))).Methods("POST")
+	router.HandleFunc("/webhooks/{id:[0-9]+}", w.Middleware.IsAuthorized(w.Middleware.IsAllowed(w.WebhooksHandler.GETWebhook, []string{"admin"}))).Methods("GET")
+	router.HandleFunc("/webhooks/{id:[0-9]+}", w.Middleware.IsAuthorized(w.Middleware.IsAllowed(w.WebhooksHandler.PUTWebhook, []string{"admin"}))).Methods("PUT")
+	router.HandleFunc("/webhooks/{id:[0-9]+}", w.Middleware.IsAuthorized(w.Middleware.IsAllowed(w.WebhooksHandler.DELETEWebhook, []string{"admin"}))).Methods("DELETE")
+	router.HandleFunc("/webhooks/{id:[0-9]+}/deliveries", w.Middleware.IsAuthorized(w.Middleware.IsAllowed(w.WebhooksHandler.GETWebhookDeliveries, []string{"admin"}))).Methods("GET")
+
+	serveApiSpec(router)
+	return router
+}
+
+func (w Web) Listen() {
+	router := w.Router()
 
 	c := cors.New(cors.Options{
 		AllowedOrigins:   []string{"https://www.acmodrepository.com", "http://localhost:8080", "http://localhost:3000", "https://mods.davidebaldelli.it", "128.116.134.232", "https://fsr-dev--nuxt-acmodrepo.netlify.app"},
 		AllowCredentials: true,
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
 		AllowedHeaders:   []string{"*"},
+		ExposedHeaders:   []string{"X-Request-Id", "Location"},
 	})
 
 	handler := c.Handler(router)
@@ -0,0 +1,47 @@
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/davide/ModRepository/routes/handlers"
+)
+
+func TestUnmatchedRequestsUseTheErrorEnvelope(t *testing.T) {
+	router := testWeb().Router()
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		status int
+		code   string
+	}{
+		{name: "unknown path", method: http.MethodGet, path: "/not/a/route", status: http.StatusNotFound, code: "not_found"},
+		{name: "unknown method", method: http.MethodDelete, path: "/car/all", status: http.StatusMethodNotAllowed, code: "method_not_allowed"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(test.method, test.path, nil))
+
+			if recorder.Code != test.status {
+				t.Errorf("expected status %v, got %v", test.status, recorder.Code)
+			}
+			id := recorder.Header().Get("X-Request-Id")
+			if id == "" {
+				t.Error("the response has no request id")
+			}
+			var response handlers.ErrorResponse
+			if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+				t.Fatalf("the body isn't the error envelope: %q", recorder.Body.String())
+			}
+			if response.Code != test.code || response.RequestId != id {
+				t.Errorf("unexpected envelope %+v", response)
+			}
+		})
+	}
+}